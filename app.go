@@ -1,7 +1,10 @@
 package app
 
 import (
+	"fmt"
+
 	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+	"github.com/sunnya97/sdk-dex-mvp/x/token"
 	abci "github.com/tendermint/tendermint/abci/types"
 	cmn "github.com/tendermint/tendermint/libs/common"
 	dbm "github.com/tendermint/tendermint/libs/db"
@@ -12,6 +15,11 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/auth"
 	"github.com/cosmos/cosmos-sdk/x/bank"
+	distr "github.com/cosmos/cosmos-sdk/x/distribution"
+	"github.com/cosmos/cosmos-sdk/x/gov"
+	"github.com/cosmos/cosmos-sdk/x/params"
+	"github.com/cosmos/cosmos-sdk/x/slashing"
+	"github.com/cosmos/cosmos-sdk/x/stake"
 )
 
 const (
@@ -25,17 +33,39 @@ type DexterApp struct {
 	keyMain          *sdk.KVStoreKey
 	keyAccount       *sdk.KVStoreKey
 	keyOrderbook     *sdk.KVStoreKey
+	keyToken         *sdk.KVStoreKey
 	keyFeeCollection *sdk.KVStoreKey
+	keyStake         *sdk.KVStoreKey
+	tkeyStake        *sdk.TransientStoreKey
+	keySlashing      *sdk.KVStoreKey
+	keyDistr         *sdk.KVStoreKey
+	tkeyDistr        *sdk.TransientStoreKey
+	keyGov           *sdk.KVStoreKey
+	keyParams        *sdk.KVStoreKey
+	tkeyParams       *sdk.TransientStoreKey
 
 	accountKeeper   auth.AccountKeeper
 	feeKeeper       auth.FeeCollectionKeeper
 	bankKeeper      bank.Keeper
+	stakeKeeper     stake.Keeper
+	slashingKeeper  slashing.Keeper
+	distrKeeper     distr.Keeper
+	govKeeper       gov.Keeper
+	paramsKeeper    params.Keeper
 	orderbookKeeper orderbook.Keeper
+	tokenKeeper     token.Keeper
 
 	codespacer *sdk.Codespacer
 }
 
-func NewDexterApp(logger log.Logger, db dbm.DB) *DexterApp {
+// Creates the app.  orderbookUpgradeHeight is the height at which the orderbook store is migrated to the schema
+// version of this release, or 0 while no upgrade is scheduled.
+// A chain whose orderbook store is at an older schema version is upgraded by agreeing on an upgrade height, then
+// stopping every node once the block before it is committed, so that the chain halts there.  The nodes are restarted
+// with this release and --orderbook-upgrade-height set to the upgrade height, and the store is migrated at the
+// beginning of the upgrade block.  A node started with this release before the upgrade height is reached, or without
+// the upgrade height, stops at its first block instead of diverging from the others
+func NewDexterApp(logger log.Logger, db dbm.DB, orderbookUpgradeHeight int64) *DexterApp {
 	cdc := MakeCodec()
 	bApp := bam.NewBaseApp(appName, logger, db, auth.DefaultTxDecoder(cdc))
 
@@ -43,11 +73,23 @@ func NewDexterApp(logger log.Logger, db dbm.DB) *DexterApp {
 		BaseApp: bApp,
 		cdc:     cdc,
 
-		keyMain:      sdk.NewKVStoreKey("main"),
-		keyAccount:   sdk.NewKVStoreKey("acc"),
-		keyOrderbook: sdk.NewKVStoreKey("orderbook"),
+		keyMain:          sdk.NewKVStoreKey("main"),
+		keyAccount:       sdk.NewKVStoreKey("acc"),
+		keyOrderbook:     sdk.NewKVStoreKey("orderbook"),
+		keyToken:         sdk.NewKVStoreKey("token"),
+		keyFeeCollection: sdk.NewKVStoreKey("fee"),
+		keyStake:         sdk.NewKVStoreKey("stake"),
+		tkeyStake:        sdk.NewTransientStoreKey("transient_stake"),
+		keySlashing:      sdk.NewKVStoreKey("slashing"),
+		keyDistr:         sdk.NewKVStoreKey("distr"),
+		tkeyDistr:        sdk.NewTransientStoreKey("transient_distr"),
+		keyGov:           sdk.NewKVStoreKey("gov"),
+		keyParams:        sdk.NewKVStoreKey("params"),
+		tkeyParams:       sdk.NewTransientStoreKey("transient_params"),
 	}
 
+	app.paramsKeeper = params.NewKeeper(app.cdc, app.keyParams, app.tkeyParams)
+
 	app.accountKeeper = auth.NewAccountKeeper(
 		app.cdc,
 		app.keyAccount,
@@ -58,61 +100,217 @@ func NewDexterApp(logger log.Logger, db dbm.DB) *DexterApp {
 
 	app.bankKeeper = bank.NewBaseKeeper(app.accountKeeper)
 
+	// the stake keeper is shared by pointer until its hooks are set, so the keepers built on it see them
+	stakeKeeper := stake.NewKeeper(
+		app.cdc,
+		app.keyStake, app.tkeyStake,
+		app.bankKeeper, app.paramsKeeper.Subspace(stake.DefaultParamspace),
+		app.RegisterCodespace(stake.DefaultCodespace),
+	)
+
+	// validators and their delegators are paid the collected transaction and trading fees
+	app.distrKeeper = distr.NewKeeper(
+		app.cdc,
+		app.keyDistr, app.tkeyDistr,
+		app.paramsKeeper.Subspace(distr.DefaultParamspace), app.bankKeeper, &stakeKeeper, app.feeKeeper,
+		app.RegisterCodespace(distr.DefaultCodespace),
+	)
+
+	app.slashingKeeper = slashing.NewKeeper(
+		app.cdc,
+		app.keySlashing,
+		&stakeKeeper, app.paramsKeeper.Subspace(slashing.DefaultParamspace),
+		app.RegisterCodespace(slashing.DefaultCodespace),
+	)
+
+	// governance tallies votes by the stake delegated to validators
+	app.govKeeper = gov.NewKeeper(
+		app.cdc,
+		app.keyGov,
+		app.paramsKeeper, app.paramsKeeper.Subspace(gov.DefaultParamspace), app.bankKeeper, &stakeKeeper,
+		app.RegisterCodespace(gov.DefaultCodespace),
+	)
+
+	app.stakeKeeper = *stakeKeeper.SetHooks(NewStakingHooks(app.distrKeeper.Hooks(), app.slashingKeeper.Hooks()))
+
 	app.orderbookKeeper = orderbook.NewKeeper(
 		app.bankKeeper,
+		app.feeKeeper,
+		app.govKeeper,
 		app.keyOrderbook,
 		app.cdc,
+		app.paramsKeeper.Subspace(orderbook.DefaultParamspace),
 		app.RegisterCodespace(orderbook.DefaultCodespace),
+	).WithOrderbookCache(orderbook.DefaultCacheDepth).WithUpgradeHeight(orderbookUpgradeHeight)
+
+	// issued tokens are listed on the orderbook
+	app.tokenKeeper = token.NewKeeper(
+		app.bankKeeper,
+		app.orderbookKeeper,
+		app.keyToken,
+		app.cdc,
+		app.RegisterCodespace(token.DefaultCodespace),
 	)
 
 	app.SetAnteHandler(auth.NewAnteHandler(app.accountKeeper, app.feeKeeper))
 
 	app.Router().
 		AddRoute("orderbook", orderbook.NewHandler(app.orderbookKeeper)).
-		AddRoute("bank", bank.NewHandler(app.bankKeeper))
+		AddRoute("token", token.NewHandler(app.tokenKeeper)).
+		AddRoute("bank", bank.NewHandler(app.bankKeeper)).
+		AddRoute("stake", stake.NewHandler(app.stakeKeeper)).
+		AddRoute("slashing", slashing.NewHandler(app.slashingKeeper)).
+		AddRoute("distr", distr.NewHandler(app.distrKeeper)).
+		AddRoute("gov", gov.NewHandler(app.govKeeper))
+
+	app.QueryRouter().
+		AddRoute("orderbook", orderbook.NewQuerier(app.orderbookKeeper)).
+		AddRoute("token", token.NewQuerier(app.tokenKeeper)).
+		AddRoute("stake", stake.NewQuerier(app.stakeKeeper, app.cdc)).
+		AddRoute("gov", gov.NewQuerier(app.govKeeper))
 
 	app.SetInitChainer(app.initChainer)
+	app.SetBeginBlocker(app.BeginBlocker)
+	app.SetEndBlocker(app.EndBlocker)
 
 	app.MountStoresIAVL(
 		app.keyMain,
 		app.keyAccount,
 		app.keyOrderbook,
+		app.keyToken,
+		app.keyFeeCollection,
+		app.keyStake,
+		app.keySlashing,
+		app.keyDistr,
+		app.keyGov,
+		app.keyParams,
 	)
+	app.MountStore(app.tkeyStake, sdk.StoreTypeTransient)
+	app.MountStore(app.tkeyDistr, sdk.StoreTypeTransient)
+	app.MountStore(app.tkeyParams, sdk.StoreTypeTransient)
 
 	err := app.LoadLatestVersion(app.keyMain)
 	if err != nil {
 		cmn.Exit(err.Error())
 	}
 
-	return app
-}
+	// the orderbook cache isn't persisted, so it is rebuilt from the latest state
+	app.orderbookKeeper.RebuildOrderbookCache(app.NewContext(true, abci.Header{}))
 
-type GenesisState struct {
-	Accounts []auth.BaseAccount `json:"accounts"`
+	return app
 }
 
+// Sets the state of a new chain. A genesis state that doesn't validate stops the node with the reason,
+// as InitChain can't return an error
 func (app *DexterApp) initChainer(ctx sdk.Context, req abci.RequestInitChain) abci.ResponseInitChain {
 	stateJSON := req.AppStateBytes
 
 	genesisState := new(GenesisState)
 	err := app.cdc.UnmarshalJSON(stateJSON, genesisState)
 	if err != nil {
-		panic(err)
+		cmn.Exit(fmt.Sprintf("failed to read the genesis state: %s", err))
+	}
+	err = ValidateGenesisState(app.cdc, *genesisState)
+	if err != nil {
+		cmn.Exit(fmt.Sprintf("invalid genesis state: %s", err))
 	}
 
+	var genesisCoins sdk.Coins
 	for _, acc := range genesisState.Accounts {
 		acc.AccountNumber = app.accountKeeper.GetNextAccountNumber(ctx)
 		app.accountKeeper.SetAccount(ctx, &acc)
+		genesisCoins = genesisCoins.Plus(acc.Coins)
+	}
+
+	validators, err := stake.InitGenesis(ctx, app.stakeKeeper, genesisState.StakeData)
+	if err != nil {
+		cmn.Exit(fmt.Sprintf("invalid stake genesis state: %s", err))
+	}
+
+	auth.InitGenesis(ctx, app.feeKeeper, genesisState.AuthData)
+	slashing.InitGenesis(ctx, app.slashingKeeper, genesisState.SlashingData, genesisState.StakeData)
+	distr.InitGenesis(ctx, app.distrKeeper, genesisState.DistrData)
+	gov.InitGenesis(ctx, app.govKeeper, genesisState.GovData)
+	orderbook.InitGenesis(ctx, app.orderbookKeeper, genesisState.OrderbookData)
+	token.InitGenesis(ctx, app.tokenKeeper, genesisState.TokenData, genesisCoins)
+
+	// the gentxs bond the initial validators, which replace any validators set in the stake genesis
+	if len(genesisState.GenTxs) > 0 {
+		txEncoder := auth.DefaultTxEncoder(app.cdc)
+		for i, genTx := range genesisState.GenTxs {
+			var tx auth.StdTx
+			app.cdc.MustUnmarshalJSON(genTx, &tx)
+			bz, err := txEncoder(tx)
+			if err != nil {
+				cmn.Exit(fmt.Sprintf("failed to encode gentx %d: %s", i, err))
+			}
+			res := app.BaseApp.DeliverTx(bz)
+			if !res.IsOK() {
+				cmn.Exit(fmt.Sprintf("gentx %d failed: %s", i, res.Log))
+			}
+		}
+		validators = app.stakeKeeper.ApplyAndReturnValidatorSetUpdates(ctx)
+	}
+	app.slashingKeeper.AddValidators(ctx, validators)
+
+	return abci.ResponseInitChain{
+		Validators: validators,
+	}
+}
+
+// application updates every begin block
+func (app *DexterApp) BeginBlocker(ctx sdk.Context, req abci.RequestBeginBlock) abci.ResponseBeginBlock {
+	// the orderbook store is migrated at the upgrade height before the block touches it
+	tags := orderbook.BeginBlocker(ctx, app.orderbookKeeper)
+
+	// the fees collected in the previous block are distributed before anyone is slashed,
+	// so a slashed validator's share is already in its pool
+	distr.BeginBlocker(ctx, req, app.distrKeeper)
+	tags = tags.AppendTags(slashing.BeginBlocker(ctx, req, app.slashingKeeper))
+
+	return abci.ResponseBeginBlock{
+		Tags: tags,
 	}
+}
+
+// application updates every end block
+func (app *DexterApp) EndBlocker(ctx sdk.Context, req abci.RequestEndBlock) abci.ResponseEndBlock {
+	// passed orderbook proposals are applied by the orderbook EndBlocker, so governance tallies first
+	tags := gov.EndBlocker(ctx, app.govKeeper)
+	tags = tags.AppendTags(orderbook.EndBlocker(ctx, app.orderbookKeeper))
+	validatorUpdates := stake.EndBlocker(ctx, app.stakeKeeper)
+	app.slashingKeeper.AddValidators(ctx, validatorUpdates)
+
+	return abci.ResponseEndBlock{
+		ValidatorUpdates: validatorUpdates,
+		Tags:             tags,
+	}
+}
+
+// Commits the state of the block and resets the CheckTx state to it.
+// The orderwalls cached for CheckTx hold the orders of the mempool, so they are dropped along with the CheckTx state
+func (app *DexterApp) Commit() abci.ResponseCommit {
+	res := app.BaseApp.Commit()
+	app.orderbookKeeper.ResetCheckTxOrderbookCache()
+	return res
+}
 
-	return abci.ResponseInitChain{}
+// Checks the registered orderbook invariants against the latest committed state
+func (app *DexterApp) AssertInvariants() error {
+	ctx := app.NewContext(true, abci.Header{Height: app.LastBlockHeight()})
+	return orderbook.AssertInvariants(ctx, app.orderbookKeeper)
 }
 
 func MakeCodec() *codec.Codec {
 	var cdc = codec.New()
 	auth.RegisterCodec(cdc)
 	bank.RegisterCodec(cdc)
+	stake.RegisterCodec(cdc)
+	slashing.RegisterCodec(cdc)
+	distr.RegisterCodec(cdc)
+	gov.RegisterCodec(cdc)
 	orderbook.RegisterCodec(cdc)
+	token.RegisterCodec(cdc)
 	sdk.RegisterCodec(cdc)
 	codec.RegisterCrypto(cdc)
 	return cdc
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
+	dbm "github.com/tendermint/tendermint/libs/db"
+	"github.com/tendermint/tendermint/libs/log"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/stake"
+)
+
+// returns a funded genesis account and its gentx bonding bond, signed as dexterd gentx signs it
+func makeGenesisValidator(t *testing.T, cdc *codec.Codec, chainID string, moniker string, coins sdk.Coins, bond sdk.Coin) (auth.BaseAccount, json.RawMessage) {
+	key := secp256k1.GenPrivKey()
+	addr := sdk.AccAddress(key.PubKey().Address())
+
+	msg := stake.NewMsgCreateValidator(
+		sdk.ValAddress(addr),
+		ed25519.GenPrivKey().PubKey(),
+		bond,
+		stake.Description{Moniker: moniker},
+		stake.NewCommissionMsg(sdk.NewDecWithPrec(1, 1), sdk.NewDecWithPrec(2, 1), sdk.NewDecWithPrec(1, 2)),
+	)
+	signMsg := auth.StdSignMsg{
+		ChainID: chainID,
+		Fee:     auth.NewStdFee(200000),
+		Msgs:    []sdk.Msg{msg},
+	}
+	sig, err := key.Sign(signMsg.Bytes())
+	require.NoError(t, err)
+	tx := auth.NewStdTx(signMsg.Msgs, signMsg.Fee, []auth.StdSignature{{PubKey: key.PubKey(), Signature: sig}}, "")
+
+	return auth.BaseAccount{Address: addr, Coins: coins}, cdc.MustMarshalJSON(tx)
+}
+
+func TestInitChainWithGenTxs(t *testing.T) {
+	cdc := MakeCodec()
+	chainID := "test-chain"
+	bondDenom := stake.DefaultParams().BondDenom
+
+	genesisState := NewDefaultGenesisState()
+	for i := 0; i < 2; i++ {
+		coins := sdk.Coins{sdk.NewInt64Coin("BTC", 10), sdk.NewInt64Coin(bondDenom, 1000)}
+		acc, genTx := makeGenesisValidator(t, cdc, chainID, fmt.Sprintf("node%d", i), coins, sdk.NewInt64Coin(bondDenom, 100))
+		genesisState.Accounts = append(genesisState.Accounts, acc)
+		genesisState.GenTxs = append(genesisState.GenTxs, genTx)
+	}
+	genesisState = SetGenesisLooseTokens(genesisState)
+	require.NoError(t, ValidateGenesisState(cdc, genesisState))
+
+	// recounting the loose tokens doesn't add the accounts again
+	require.True(t, sdk.NewDec(2000).Equal(SetGenesisLooseTokens(genesisState).StakeData.Pool.LooseTokens))
+
+	appState, err := codec.MarshalJSONIndent(cdc, genesisState)
+	require.NoError(t, err)
+
+	dexterApp := NewDexterApp(log.NewNopLogger(), dbm.NewMemDB(), 0)
+	res := dexterApp.InitChain(abci.RequestInitChain{ChainId: chainID, AppStateBytes: appState})
+	require.Len(t, res.Validators, 2)
+	dexterApp.Commit()
+
+	ctx := dexterApp.NewContext(true, abci.Header{})
+	pool := dexterApp.stakeKeeper.GetPool(ctx)
+	require.True(t, sdk.NewDec(1800).Equal(pool.LooseTokens), pool.LooseTokens.String())
+	require.True(t, sdk.NewDec(200).Equal(pool.BondedTokens), pool.BondedTokens.String())
+	for _, acc := range genesisState.Accounts {
+		require.Equal(t, int64(900), dexterApp.accountKeeper.GetAccount(ctx, acc.Address).GetCoins().AmountOf(bondDenom).Int64())
+	}
+	require.NoError(t, dexterApp.AssertInvariants())
+}
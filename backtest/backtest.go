@@ -0,0 +1,418 @@
+package backtest
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto"
+	dbm "github.com/tendermint/tendermint/libs/db"
+	"github.com/tendermint/tendermint/libs/log"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/store"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+	"github.com/cosmos/cosmos-sdk/x/params"
+
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+)
+
+// Options of a backtest
+type Options struct {
+	Params  orderbook.Params
+	Markets []orderbook.Market
+	// coins every account starts with
+	InitialCoins sdk.Coins
+	// events less than BlockTime apart are replayed in the same block, and the orderbook EndBlocker runs in between blocks
+	BlockTime time.Duration
+	// books are snapshotted every SnapshotInterval of event time, as well as at the end.  Zero only snapshots at the end
+	SnapshotInterval time.Duration
+	// number of price levels of each side of the book snapshots
+	SnapshotLevels int64
+	// denom the PnL of accounts is valued in, at the last trade price of every other denom against it
+	ValuationDenom string
+}
+
+// Returns Options with the default params and one second blocks
+func DefaultOptions(markets []orderbook.Market, initialCoins sdk.Coins) Options {
+	return Options{
+		Params:         orderbook.DefaultParams(),
+		Markets:        markets,
+		InitialCoins:   initialCoins,
+		BlockTime:      time.Second,
+		SnapshotLevels: orderbook.DefaultDepthLevels,
+	}
+}
+
+// Fill is a trade made while replaying an order flow, with the accounts of the maker and the taker
+type Fill struct {
+	Maker string          `json:"maker"`
+	Taker string          `json:"taker"`
+	Trade orderbook.Trade `json:"trade"`
+}
+
+// Rejection is an event of the order flow that failed, and left the orderbook as it was
+type Rejection struct {
+	Index int    `json:"index"`
+	Event Event  `json:"event"`
+	Error string `json:"error"`
+}
+
+// Snapshot is the books of all the markets at a point of the replay
+type Snapshot struct {
+	Time   time.Time         `json:"time"`
+	Height int64             `json:"height"`
+	Books  []orderbook.Depth `json:"books"`
+}
+
+// AccountResult is how an account ended a backtest.  Final includes the coins escrowed in the account's resting orders,
+// and PnL is the change from Initial to Final valued in the ValuationDenom, counting only the denoms that have a price
+type AccountResult struct {
+	Account  string         `json:"account"`
+	Address  sdk.AccAddress `json:"address"`
+	Initial  sdk.Coins      `json:"initial"`
+	Final    sdk.Coins      `json:"final"`
+	Change   sdk.Coins      `json:"change"`
+	PnL      sdk.Dec        `json:"pnl"`
+	Unvalued []string       `json:"unvalued"`
+}
+
+// Result of a backtest
+type Result struct {
+	Events     int             `json:"events"`
+	Blocks     int64           `json:"blocks"`
+	Fills      []Fill          `json:"fills"`
+	Rejections []Rejection     `json:"rejections"`
+	Snapshots  []Snapshot      `json:"snapshots"`
+	Prices     []Price         `json:"prices"`
+	Accounts   []AccountResult `json:"accounts"`
+}
+
+// Price is the last trade price of a denom in the ValuationDenom
+type Price struct {
+	Denom string  `json:"denom"`
+	Price sdk.Dec `json:"price"`
+}
+
+// Backtester replays order flows against an orderbook Keeper backed by in-memory stores and bank.
+// Replays are deterministic: the same events and Options always give the same Result
+type Backtester struct {
+	opts Options
+	cdc  *codec.Codec
+
+	ctx        sdk.Context
+	bankKeeper bank.Keeper
+	keeper     orderbook.Keeper
+
+	accounts  map[string]sdk.AccAddress
+	names     map[string]string
+	result    Result
+	blockTime time.Time
+	snapshot  time.Time
+}
+
+// Creates a Backtester with the markets of the Options listed
+func NewBacktester(cdc *codec.Codec, opts Options) (*Backtester, error) {
+	err := orderbook.ValidateGenesis(orderbook.NewGenesisState(opts.Params, opts.Markets))
+	if err != nil {
+		return nil, err
+	}
+	if opts.BlockTime <= 0 {
+		return nil, fmt.Errorf("block time must be positive")
+	}
+	opts.InitialCoins = opts.InitialCoins.Sort()
+
+	keyAcc := sdk.NewKVStoreKey("acc")
+	keyOrderbook := sdk.NewKVStoreKey("orderbook")
+	keyFeeCollection := sdk.NewKVStoreKey("fee")
+	keyParams := sdk.NewKVStoreKey("params")
+	tkeyParams := sdk.NewTransientStoreKey("transient_params")
+
+	db := dbm.NewMemDB()
+	ms := store.NewCommitMultiStore(db)
+	ms.MountStoreWithDB(keyAcc, sdk.StoreTypeIAVL, db)
+	ms.MountStoreWithDB(keyOrderbook, sdk.StoreTypeIAVL, db)
+	ms.MountStoreWithDB(keyFeeCollection, sdk.StoreTypeIAVL, db)
+	ms.MountStoreWithDB(keyParams, sdk.StoreTypeIAVL, db)
+	ms.MountStoreWithDB(tkeyParams, sdk.StoreTypeTransient, db)
+	err = ms.LoadLatestVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := sdk.NewContext(ms, abci.Header{ChainID: "backtest", Height: 1}, false, log.NewNopLogger())
+
+	accountKeeper := auth.NewAccountKeeper(cdc, keyAcc, auth.ProtoBaseAccount)
+	bankKeeper := bank.NewBaseKeeper(accountKeeper)
+	feeCollectionKeeper := auth.NewFeeCollectionKeeper(cdc, keyFeeCollection)
+	paramsKeeper := params.NewKeeper(cdc, keyParams, tkeyParams)
+	keeper := orderbook.NewKeeper(bankKeeper, feeCollectionKeeper, nil, keyOrderbook, cdc,
+		paramsKeeper.Subspace(orderbook.DefaultParamspace), orderbook.DefaultCodespace)
+	orderbook.InitGenesis(ctx, keeper, orderbook.NewGenesisState(opts.Params, opts.Markets))
+
+	return &Backtester{
+		opts:       opts,
+		cdc:        cdc,
+		ctx:        ctx,
+		bankKeeper: bankKeeper,
+		keeper:     keeper,
+		accounts:   make(map[string]sdk.AccAddress),
+		names:      make(map[string]string),
+	}, nil
+}
+
+// Returns the address of a named account, derived from its name
+func AccountAddress(name string) sdk.AccAddress {
+	return sdk.AccAddress(crypto.AddressHash([]byte(name)))
+}
+
+// Returns the Keeper and the Context of the current block, to inspect the state directly
+func (b *Backtester) Keeper() orderbook.Keeper {
+	return b.keeper
+}
+
+func (b *Backtester) Context() sdk.Context {
+	return b.ctx
+}
+
+// Replays events, in order, and returns the Result of the backtest
+func (b *Backtester) Run(events []Event) (Result, error) {
+	err := checkOrder(events)
+	if err != nil {
+		return Result{}, err
+	}
+
+	for i, event := range events {
+		b.advance(event.Time)
+		err := b.apply(event)
+		if err != nil {
+			b.result.Rejections = append(b.result.Rejections, Rejection{Index: i, Event: event, Error: err.Error()})
+		}
+		b.result.Events++
+	}
+
+	if len(events) > 0 {
+		b.endBlock()
+		b.takeSnapshot(b.blockTime)
+	}
+	b.result.Prices = b.prices()
+	b.result.Accounts = b.accountResults()
+	return b.result, nil
+}
+
+// moves to the block an event at t belongs to, ending the current block first if t is past it
+func (b *Backtester) advance(t time.Time) {
+	if b.blockTime.IsZero() {
+		b.startBlock(t)
+		b.snapshot = t
+		return
+	}
+	if t.Sub(b.blockTime) < b.opts.BlockTime {
+		return
+	}
+
+	b.endBlock()
+	if b.opts.SnapshotInterval > 0 {
+		for !t.Before(b.snapshot.Add(b.opts.SnapshotInterval)) {
+			b.snapshot = b.snapshot.Add(b.opts.SnapshotInterval)
+			b.takeSnapshot(b.snapshot)
+		}
+	}
+	b.startBlock(t)
+}
+
+func (b *Backtester) startBlock(t time.Time) {
+	header := b.ctx.BlockHeader()
+	if !b.blockTime.IsZero() {
+		header.Height++
+	}
+	header.Time = t
+	b.ctx = b.ctx.WithBlockHeader(header)
+	b.blockTime = t
+}
+
+func (b *Backtester) endBlock() {
+	orderbook.EndBlocker(b.ctx, b.keeper)
+	b.result.Blocks++
+}
+
+// applies an event the way its message would be handled, in a cached context that is only written if it succeeds
+func (b *Backtester) apply(event Event) error {
+	owner := b.account(event.Account)
+	ctx, write := b.ctx.CacheContext()
+
+	var err sdk.Error
+	switch event.Type {
+	case EventMake:
+		err = b.makeOrder(ctx, owner, event)
+	case EventCancel:
+		err = b.cancelOrder(ctx, owner, event)
+	default:
+		err = sdk.ErrUnknownRequest(fmt.Sprintf("unknown event type %q", event.Type))
+	}
+	if err != nil {
+		return fmt.Errorf("%s", err.Result().Log)
+	}
+	write()
+	return nil
+}
+
+func (b *Backtester) makeOrder(ctx sdk.Context, owner sdk.AccAddress, event Event) sdk.Error {
+	msg := orderbook.NewMsgMakeOrder(owner, event.ClientOrderID, event.SellCoins,
+		orderbook.NewPrice(event.Price, event.BuyDenom, event.SellCoins.Denom), event.ExpirationTime, event.DisplayAmount,
+		event.SelfTradePrevention)
+	err := msg.ValidateBasic()
+	if err != nil {
+		return err
+	}
+
+	order := orderbook.Order{
+		OrderID:             b.keeper.GetNextOrderID(ctx),
+		ClientOrderID:       msg.ClientOrderID,
+		Owner:               owner,
+		SellCoins:           msg.SellCoins,
+		BuyDenom:            msg.Price.NumeratorDenom,
+		Price:               msg.Price,
+		ExpirationTime:      msg.ExpirationTime,
+		DisplayAmount:       msg.DisplayAmount,
+		HiddenCoins:         sdk.NewCoin(msg.SellCoins.Denom, sdk.ZeroInt()),
+		SelfTradePrevention: msg.SelfTradePrevention,
+	}
+	if order.ExpirationTime.IsZero() && b.keeper.DefaultExpiry(ctx) > 0 {
+		order.ExpirationTime = ctx.BlockHeader().Time.Add(b.keeper.DefaultExpiry(ctx))
+	}
+
+	_, _, err = b.bankKeeper.SubtractCoins(ctx, owner, sdk.Coins{order.SellCoins})
+	if err != nil {
+		return err
+	}
+
+	lastTradeID := b.keeper.GetLastTradeID(ctx)
+	order, _, err = b.keeper.AddNewOrder(ctx, order)
+	if err != nil {
+		return err
+	}
+	for tradeID := lastTradeID + 1; tradeID <= b.keeper.GetLastTradeID(ctx); tradeID++ {
+		if trade, found := b.keeper.GetTrade(ctx, order.Pair(), tradeID); found {
+			b.result.Fills = append(b.result.Fills, Fill{Maker: b.names[trade.Maker.String()], Taker: event.Account, Trade: trade})
+		}
+	}
+	return nil
+}
+
+func (b *Backtester) cancelOrder(ctx sdk.Context, owner sdk.AccAddress, event Event) sdk.Error {
+	orderID := event.OrderID
+	if event.ClientOrderID != "" {
+		var found bool
+		orderID, found = b.keeper.GetOrderIDByClientOrderID(ctx, owner, event.ClientOrderID)
+		if !found {
+			return orderbook.ErrOrderNotFound(orderbook.DefaultCodespace, orderID)
+		}
+	}
+
+	order, found := b.keeper.GetOrder(ctx, orderID)
+	if !found {
+		return orderbook.ErrOrderNotFound(orderbook.DefaultCodespace, orderID)
+	}
+	if !order.Owner.Equals(owner) {
+		return sdk.ErrUnauthorized("only the owner can remove an order")
+	}
+
+	removedOrder := b.keeper.RemoveOrder(ctx, orderID)
+	_, _, err := b.bankKeeper.AddCoins(ctx, owner, sdk.Coins{removedOrder.TotalSellCoins()})
+	return err
+}
+
+// returns the address of a named account, funding it with the InitialCoins the first time it's seen
+func (b *Backtester) account(name string) sdk.AccAddress {
+	address, ok := b.accounts[name]
+	if ok {
+		return address
+	}
+	address = AccountAddress(name)
+	b.accounts[name] = address
+	b.names[address.String()] = name
+	if !b.opts.InitialCoins.IsZero() {
+		_, _, err := b.bankKeeper.AddCoins(b.ctx, address, b.opts.InitialCoins)
+		if err != nil {
+			panic(err)
+		}
+	}
+	return address
+}
+
+// snapshots the books as of the end of the current block, at time t
+func (b *Backtester) takeSnapshot(t time.Time) {
+	snapshot := Snapshot{
+		Time:   t,
+		Height: b.ctx.BlockHeight(),
+	}
+	for _, market := range b.keeper.GetMarkets(b.ctx) {
+		snapshot.Books = append(snapshot.Books, b.keeper.GetDepth(b.ctx, market.Pair(), b.opts.SnapshotLevels))
+	}
+	b.result.Snapshots = append(b.result.Snapshots, snapshot)
+}
+
+// returns the price of every denom traded against the ValuationDenom, in the ValuationDenom, at its last fill
+func (b *Backtester) prices() (prices []Price) {
+	if b.opts.ValuationDenom == "" {
+		return nil
+	}
+	last := make(map[string]sdk.Dec)
+	for _, fill := range b.result.Fills {
+		sold, bought := fill.Trade.MakerSold, fill.Trade.TakerSold
+		if bought.Denom != b.opts.ValuationDenom {
+			sold, bought = bought, sold
+		}
+		if bought.Denom != b.opts.ValuationDenom || !sold.IsPositive() {
+			continue
+		}
+		last[sold.Denom] = sdk.NewDecFromInt(bought.Amount).Quo(sdk.NewDecFromInt(sold.Amount))
+	}
+
+	for denom, price := range last {
+		prices = append(prices, Price{Denom: denom, Price: price})
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i].Denom < prices[j].Denom })
+	return prices
+}
+
+func (b *Backtester) accountResults() (results []AccountResult) {
+	prices := make(map[string]sdk.Dec)
+	for _, price := range b.result.Prices {
+		prices[price.Denom] = price.Price
+	}
+
+	for name, address := range b.accounts {
+		final := b.bankKeeper.GetCoins(b.ctx, address)
+		for _, order := range b.keeper.GetOwnerOrders(b.ctx, address) {
+			final = final.Plus(sdk.Coins{order.TotalSellCoins()})
+		}
+
+		result := AccountResult{
+			Account: name,
+			Address: address,
+			Initial: b.opts.InitialCoins,
+			Final:   final,
+			Change:  final.Minus(b.opts.InitialCoins),
+			PnL:     sdk.ZeroDec(),
+		}
+		for _, coin := range result.Change {
+			switch price, ok := prices[coin.Denom]; {
+			case coin.Denom == b.opts.ValuationDenom:
+				result.PnL = result.PnL.Add(sdk.NewDecFromInt(coin.Amount))
+			case ok:
+				result.PnL = result.PnL.Add(sdk.NewDecFromInt(coin.Amount).Mul(price))
+			default:
+				result.Unvalued = append(result.Unvalued, coin.Denom)
+			}
+		}
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Account < results[j].Account })
+	return results
+}
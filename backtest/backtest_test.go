@@ -0,0 +1,109 @@
+package backtest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	app "github.com/sunnya97/sdk-dex-mvp"
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+)
+
+const testFlow = `time,type,account,client_order_id,sell,buy_denom,price
+2018-11-01T00:00:00Z,make,alice,a1,10BTC,ETH,2
+2018-11-01T00:00:00Z,make,alice,a2,10BTC,ETH,3
+2018-11-01T00:00:02Z,make,bob,b1,30ETH,BTC,0.5
+2018-11-01T00:00:04Z,cancel,bob,b1,,,
+2018-11-01T00:00:05Z,cancel,alice,a2,,,
+2018-11-01T00:00:06Z,make,carol,c1,5000BTC,ETH,1
+`
+
+func testOptions() Options {
+	opts := DefaultOptions([]orderbook.Market{orderbook.NewMarket("BTC", "ETH")},
+		sdk.Coins{sdk.NewInt64Coin("BTC", 1000), sdk.NewInt64Coin("ETH", 1000)})
+	opts.SnapshotInterval = 3 * time.Second
+	opts.ValuationDenom = "ETH"
+	return opts
+}
+
+func runTestFlow(t *testing.T) Result {
+	events, err := ReadCSV(strings.NewReader(testFlow))
+	require.NoError(t, err)
+	require.Len(t, events, 6)
+
+	backtester, err := NewBacktester(app.MakeCodec(), testOptions())
+	require.NoError(t, err)
+	result, err := backtester.Run(events)
+	require.NoError(t, err)
+	return result
+}
+
+func TestBacktest(t *testing.T) {
+	result := runTestFlow(t)
+	require.Equal(t, 6, result.Events)
+	require.Equal(t, int64(5), result.Blocks)
+
+	// bob buys the 10 BTC at 2 ETH, and what's left of his order rests until he cancels it
+	require.Len(t, result.Fills, 1)
+	require.Equal(t, "alice", result.Fills[0].Maker)
+	require.Equal(t, "bob", result.Fills[0].Taker)
+	require.Equal(t, sdk.NewInt64Coin("BTC", 10), result.Fills[0].Trade.MakerSold)
+	require.Equal(t, sdk.NewInt64Coin("ETH", 20), result.Fills[0].Trade.TakerSold)
+
+	// carol can't afford her order
+	require.Len(t, result.Rejections, 1)
+	require.Equal(t, 5, result.Rejections[0].Index)
+
+	// snapshots every 3s, with alice's second ask and what's left of bob's bid resting at 3s, and at the end
+	require.Len(t, result.Snapshots, 3)
+	require.Len(t, result.Snapshots[0].Books, 1)
+	require.Equal(t, []orderbook.PriceLevel{{Price: sdk.NewDec(3), Amount: sdk.NewInt(10)}}, result.Snapshots[0].Books[0].Asks)
+	require.Equal(t, []orderbook.PriceLevel{{Price: sdk.NewDec(2), Amount: sdk.NewInt(5)}}, result.Snapshots[0].Books[0].Bids)
+	for _, snapshot := range result.Snapshots[1:] {
+		require.Empty(t, snapshot.Books[0].Asks)
+		require.Empty(t, snapshot.Books[0].Bids)
+	}
+
+	// BTC is valued at the last trade price of 2 ETH
+	require.Equal(t, []Price{{Denom: "BTC", Price: sdk.NewDec(2)}}, result.Prices)
+	require.Len(t, result.Accounts, 3)
+	alice, bob, carol := result.Accounts[0], result.Accounts[1], result.Accounts[2]
+	require.Equal(t, "alice", alice.Account)
+	require.Equal(t, sdk.Coins{sdk.NewInt64Coin("BTC", -10), sdk.NewInt64Coin("ETH", 20)}, alice.Change)
+	require.True(t, alice.PnL.IsZero())
+	require.Equal(t, sdk.Coins{sdk.NewInt64Coin("BTC", 10), sdk.NewInt64Coin("ETH", -20)}, bob.Change)
+	require.True(t, bob.PnL.IsZero())
+	require.Empty(t, carol.Change)
+}
+
+func TestBacktestDeterministic(t *testing.T) {
+	require.Equal(t, runTestFlow(t), runTestFlow(t))
+}
+
+func TestReadJSON(t *testing.T) {
+	csvEvents, err := ReadCSV(strings.NewReader(testFlow))
+	require.NoError(t, err)
+
+	// an array and a stream of objects give the same events as the CSV
+	objects := []string{
+		`{"time": "2018-11-01T00:00:00Z", "type": "make", "account": "alice", "client_order_id": "a1", "sell": "10BTC", "buy_denom": "ETH", "price": "2"}`,
+		`{"time": "2018-11-01T00:00:00Z", "type": "make", "account": "alice", "client_order_id": "a2", "sell": "10BTC", "buy_denom": "ETH", "price": 3}`,
+		`{"time": "2018-11-01T00:00:02Z", "type": "make", "account": "bob", "client_order_id": "b1", "sell": "30ETH", "buy_denom": "BTC", "price": "0.5"}`,
+		`{"time": "2018-11-01T00:00:04Z", "type": "cancel", "account": "bob", "client_order_id": "b1"}`,
+		`{"time": "2018-11-01T00:00:05Z", "type": "cancel", "account": "alice", "client_order_id": "a2"}`,
+		`{"time": "2018-11-01T00:00:06Z", "type": "make", "account": "carol", "client_order_id": "c1", "sell": "5000BTC", "buy_denom": "ETH", "price": 1}`,
+	}
+	for _, flow := range []string{"[" + strings.Join(objects, ",") + "]", strings.Join(objects, "\n")} {
+		events, err := ReadJSON(strings.NewReader(flow))
+		require.NoError(t, err)
+		require.Equal(t, csvEvents, events)
+	}
+
+	// events out of order are refused
+	_, err = ReadJSON(strings.NewReader(objects[2] + objects[0]))
+	require.Error(t, err)
+}
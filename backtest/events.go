@@ -0,0 +1,219 @@
+package backtest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+)
+
+// Types of Events
+const (
+	EventMake   = "make"
+	EventCancel = "cancel"
+)
+
+// Event is one entry of an order flow.  Accounts are named, and orders are cancelled either by the client order ID
+// they were made with or by the order ID the Keeper assigned them
+type Event struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Account string    `json:"account"`
+
+	ClientOrderID string `json:"client_order_id"`
+	OrderID       int64  `json:"order_id"`
+
+	// only used by EventMake.  Price is in units of BuyDenom/SellCoins.Denom
+	SellCoins           sdk.Coin                      `json:"sell"`
+	BuyDenom            string                        `json:"buy_denom"`
+	Price               sdk.Dec                       `json:"price"`
+	DisplayAmount       sdk.Int                       `json:"display_amount"`
+	SelfTradePrevention orderbook.SelfTradePrevention `json:"stp"`
+	ExpirationTime      time.Time                     `json:"expiration_time"`
+}
+
+// Fields of an event, as the columns of a CSV order flow or the keys of a JSON one
+const (
+	fieldTime           = "time"
+	fieldType           = "type"
+	fieldAccount        = "account"
+	fieldClientOrderID  = "client_order_id"
+	fieldOrderID        = "order_id"
+	fieldSell           = "sell"
+	fieldBuyDenom       = "buy_denom"
+	fieldPrice          = "price"
+	fieldDisplayAmount  = "display_amount"
+	fieldSTP            = "stp"
+	fieldExpirationTime = "expiration_time"
+)
+
+// Reads an order flow from CSV, with a header row naming the columns, e.g.
+//
+//	time,type,account,client_order_id,sell,buy_denom,price
+//	2018-11-01T00:00:00Z,make,alice,a1,10BTC,ETH,2.5
+//	2018-11-01T00:00:05Z,cancel,alice,a1,,,
+//
+// Events must be in order of time
+func ReadCSV(r io.Reader) (events []Event, err error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		fields := make(map[string]string)
+		for i, column := range header {
+			if i < len(row) {
+				fields[strings.TrimSpace(column)] = strings.TrimSpace(row[i])
+			}
+		}
+		event, err := parseEvent(fields)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		events = append(events, event)
+	}
+
+	return events, checkOrder(events)
+}
+
+// Reads an order flow from JSON, either an array of objects or a stream of objects such as JSON lines, with the
+// same keys as the columns of ReadCSV.  Events must be in order of time
+func ReadJSON(r io.Reader) (events []Event, err error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	var objects []map[string]interface{}
+	var first interface{}
+	err = decoder.Decode(&first)
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch first := first.(type) {
+	case []interface{}:
+		for _, value := range first {
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("event %d is not an object", len(objects))
+			}
+			objects = append(objects, object)
+		}
+	case map[string]interface{}:
+		objects = append(objects, first)
+		for decoder.More() {
+			var object map[string]interface{}
+			err = decoder.Decode(&object)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, object)
+		}
+	default:
+		return nil, fmt.Errorf("expected an array or objects of events")
+	}
+
+	for i, object := range objects {
+		fields := make(map[string]string)
+		for key, value := range object {
+			if value != nil {
+				fields[key] = fmt.Sprint(value)
+			}
+		}
+		event, err := parseEvent(fields)
+		if err != nil {
+			return nil, fmt.Errorf("event %d: %v", i, err)
+		}
+		events = append(events, event)
+	}
+
+	return events, checkOrder(events)
+}
+
+func parseEvent(fields map[string]string) (event Event, err error) {
+	event.Time, err = time.Parse(time.RFC3339Nano, fields[fieldTime])
+	if err != nil {
+		return event, err
+	}
+	event.Type = fields[fieldType]
+	event.Account = fields[fieldAccount]
+	if event.Account == "" {
+		return event, fmt.Errorf("missing %s", fieldAccount)
+	}
+	event.ClientOrderID = fields[fieldClientOrderID]
+	event.DisplayAmount = sdk.ZeroInt()
+	if orderID := fields[fieldOrderID]; orderID != "" {
+		event.OrderID, err = strconv.ParseInt(orderID, 10, 64)
+		if err != nil {
+			return event, err
+		}
+	}
+
+	switch event.Type {
+	case EventMake:
+		event.SellCoins, err = sdk.ParseCoin(fields[fieldSell])
+		if err != nil {
+			return event, err
+		}
+		event.BuyDenom = fields[fieldBuyDenom]
+		event.Price, err = sdk.NewDecFromStr(fields[fieldPrice])
+		if err != nil {
+			return event, err
+		}
+		if displayAmount := fields[fieldDisplayAmount]; displayAmount != "" {
+			var ok bool
+			event.DisplayAmount, ok = sdk.NewIntFromString(displayAmount)
+			if !ok {
+				return event, fmt.Errorf("invalid %s %s", fieldDisplayAmount, displayAmount)
+			}
+		}
+		if stp := fields[fieldSTP]; stp != "" {
+			event.SelfTradePrevention, err = orderbook.SelfTradePreventionFromStr(stp)
+			if err != nil {
+				return event, err
+			}
+		}
+		if expirationTime := fields[fieldExpirationTime]; expirationTime != "" {
+			event.ExpirationTime, err = time.Parse(time.RFC3339Nano, expirationTime)
+			if err != nil {
+				return event, err
+			}
+		}
+	case EventCancel:
+		if event.ClientOrderID == "" && event.OrderID == 0 {
+			return event, fmt.Errorf("a cancel needs either a %s or an %s", fieldClientOrderID, fieldOrderID)
+		}
+	default:
+		return event, fmt.Errorf("unknown event type %q", event.Type)
+	}
+
+	return event, nil
+}
+
+func checkOrder(events []Event) error {
+	for i := 1; i < len(events); i++ {
+		if events[i].Time.Before(events[i-1].Time) {
+			return fmt.Errorf("event %d is before the event preceding it", i)
+		}
+	}
+	return nil
+}
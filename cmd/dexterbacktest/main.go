@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/tendermint/tendermint/libs/cli"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	app "github.com/sunnya97/sdk-dex-mvp"
+	"github.com/sunnya97/sdk-dex-mvp/backtest"
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+)
+
+const (
+	flagMarkets          = "markets"
+	flagInitialCoins     = "initial-coins"
+	flagParams           = "params"
+	flagBlockTime        = "block-time"
+	flagSnapshotInterval = "snapshot-interval"
+	flagLevels           = "levels"
+	flagValuationDenom   = "valuation-denom"
+	flagOut              = "out"
+)
+
+// DefaultBacktestHome is the home of dexterbacktest, only used for its config file
+var DefaultBacktestHome = os.ExpandEnv("$HOME/.dexterbacktest")
+
+func main() {
+	cdc := app.MakeCodec()
+
+	rootCmd := &cobra.Command{
+		Use:   "dexterbacktest [order-flow-file]",
+		Short: "Replay an order flow against an in-memory orderbook",
+		Long: `Replay an order flow of make and cancel events against an in-memory orderbook, and write the fills,
+the PnL of every account and snapshots of the books as JSON.
+The order flow is read as CSV if the file name ends with .csv, and as JSON otherwise.
+Every account starts with --initial-coins, and replays are deterministic.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			events, err := readEvents(args[0])
+			if err != nil {
+				return err
+			}
+
+			opts, err := optionsFromFlags(cdc)
+			if err != nil {
+				return err
+			}
+			backtester, err := backtest.NewBacktester(cdc, opts)
+			if err != nil {
+				return err
+			}
+			result, err := backtester.Run(events)
+			if err != nil {
+				return err
+			}
+
+			output, err := codec.MarshalJSONIndent(cdc, result)
+			if err != nil {
+				return err
+			}
+			if out := viper.GetString(flagOut); out != "" {
+				return ioutil.WriteFile(out, output, 0644)
+			}
+			fmt.Println(string(output))
+			return nil
+		},
+	}
+	rootCmd.Flags().String(flagMarkets, "", "Comma separated markets to list, as base:quote")
+	rootCmd.Flags().String(flagInitialCoins, "", "Coins every account starts with")
+	rootCmd.Flags().String(flagParams, "", "JSON file of the orderbook params, the default params if left blank")
+	rootCmd.Flags().Duration(flagBlockTime, time.Second, "Events less than this apart are replayed in the same block")
+	rootCmd.Flags().Duration(flagSnapshotInterval, 0, "Snapshot the books this often, only at the end if 0")
+	rootCmd.Flags().Int64(flagLevels, orderbook.DefaultDepthLevels, "Number of price levels of each side of the snapshots")
+	rootCmd.Flags().String(flagValuationDenom, "", "Denom to value the PnL of accounts in")
+	rootCmd.Flags().String(flagOut, "", "File to write the result to instead of stdout")
+	rootCmd.MarkFlagRequired(flagMarkets)
+
+	executor := cli.PrepareMainCmd(rootCmd, "DEX", DefaultBacktestHome)
+	err := executor.Execute()
+	if err != nil {
+		panic(err)
+	}
+}
+
+func readEvents(path string) ([]backtest.Event, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if strings.ToLower(filepath.Ext(path)) == ".csv" {
+		return backtest.ReadCSV(file)
+	}
+	return backtest.ReadJSON(file)
+}
+
+func optionsFromFlags(cdc *codec.Codec) (opts backtest.Options, err error) {
+	var markets []orderbook.Market
+	for _, market := range strings.Split(viper.GetString(flagMarkets), ",") {
+		denoms := strings.Split(strings.TrimSpace(market), ":")
+		if len(denoms) != 2 {
+			return opts, fmt.Errorf("invalid market %s, expected base:quote", market)
+		}
+		markets = append(markets, orderbook.NewMarket(denoms[0], denoms[1]))
+	}
+
+	initialCoins, err := sdk.ParseCoins(viper.GetString(flagInitialCoins))
+	if err != nil {
+		return opts, err
+	}
+
+	opts = backtest.DefaultOptions(markets, initialCoins)
+	if paramsFile := viper.GetString(flagParams); paramsFile != "" {
+		bz, err := ioutil.ReadFile(paramsFile)
+		if err != nil {
+			return opts, err
+		}
+		err = cdc.UnmarshalJSON(bz, &opts.Params)
+		if err != nil {
+			return opts, err
+		}
+	}
+	opts.BlockTime = viper.GetDuration(flagBlockTime)
+	opts.SnapshotInterval = viper.GetDuration(flagSnapshotInterval)
+	opts.SnapshotLevels = viper.GetInt64(flagLevels)
+	opts.ValuationDenom = viper.GetString(flagValuationDenom)
+	return opts, nil
+}
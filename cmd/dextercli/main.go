@@ -9,13 +9,24 @@ import (
 
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/keys"
+	"github.com/cosmos/cosmos-sdk/client/lcd"
 	"github.com/cosmos/cosmos-sdk/client/rpc"
 	"github.com/cosmos/cosmos-sdk/client/tx"
 
 	app "github.com/sunnya97/sdk-dex-mvp"
 
 	authcmd "github.com/cosmos/cosmos-sdk/x/auth/client/cli"
+	auth "github.com/cosmos/cosmos-sdk/x/auth/client/rest"
+	bank "github.com/cosmos/cosmos-sdk/x/bank/client/rest"
+	distrcmd "github.com/cosmos/cosmos-sdk/x/distribution/client/cli"
+	govcmd "github.com/cosmos/cosmos-sdk/x/gov/client/cli"
+	slashingcmd "github.com/cosmos/cosmos-sdk/x/slashing/client/cli"
+	stakecmd "github.com/cosmos/cosmos-sdk/x/stake/client/cli"
 	orderbookcmd "github.com/sunnya97/sdk-dex-mvp/x/orderbook/cli"
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook/client/marketmaker"
+	orderbook "github.com/sunnya97/sdk-dex-mvp/x/orderbook/client/rest"
+	orderbookstream "github.com/sunnya97/sdk-dex-mvp/x/orderbook/client/stream"
+	tokencmd "github.com/sunnya97/sdk-dex-mvp/x/token/cli"
 )
 
 const storeAcc = "acc"
@@ -52,6 +63,18 @@ func main() {
 		authcmd.GetAccountCmd(storeAcc, cdc, authcmd.GetAccountDecoder(cdc)),
 		orderbookcmd.GetCmdGetOrder("orderbook", cdc),
 		orderbookcmd.GetCmdGetOrderwall("orderbook", cdc),
+		orderbookcmd.GetCmdGetDepth("orderbook", cdc),
+		orderbookcmd.GetCmdGetTWAPOrder("orderbook", cdc),
+		orderbookcmd.GetCmdGetClientOrder("orderbook", cdc),
+		orderbookcmd.GetCmdGetParams("orderbook", cdc),
+		orderbookcmd.GetCmdGetMarkets("orderbook", cdc),
+		tokencmd.GetCmdGetToken("token", cdc),
+		tokencmd.GetCmdGetTokens("token", cdc),
+		tokencmd.GetCmdGetDenomMetadata("token", cdc),
+		govcmd.GetCmdQueryProposal("gov", cdc),
+		stakecmd.GetCmdQueryValidator("stake", cdc),
+		stakecmd.GetCmdQueryValidators("stake", cdc),
+		stakecmd.GetCmdQueryDelegation("stake", cdc),
 	)...)
 
 	txCmd := &cobra.Command{
@@ -62,12 +85,35 @@ func main() {
 	txCmd.AddCommand(client.PostCommands(
 		orderbookcmd.GetCmdMakeOrder(cdc),
 		orderbookcmd.GetCmdRemoveOrder(cdc),
+		orderbookcmd.GetCmdMakeTWAPOrder(cdc),
+		orderbookcmd.GetCmdCancelTWAPOrder(cdc),
+		orderbookcmd.GetCmdUpdateParams(cdc),
+		orderbookcmd.GetCmdProposeListMarket(cdc),
+		orderbookcmd.GetCmdProposeHaltMarket(cdc),
+		orderbookcmd.GetCmdProposeDelistMarket(cdc),
+		orderbookcmd.GetCmdProposeChangeParams(cdc),
+		tokencmd.GetCmdIssueToken(cdc),
+		tokencmd.GetCmdMint(cdc),
+		tokencmd.GetCmdBurn(cdc),
+		tokencmd.GetCmdTransferOwnership(cdc),
+		tokencmd.GetCmdSetDisplayNames(cdc),
+		govcmd.GetCmdDeposit(cdc),
+		govcmd.GetCmdVote(cdc),
+		stakecmd.GetCmdCreateValidator(cdc),
+		stakecmd.GetCmdDelegate(cdc),
+		stakecmd.GetCmdUnbond("stake", cdc),
+		slashingcmd.GetCmdUnjail(cdc),
+		distrcmd.GetCmdWithdrawRewards(cdc),
 	)...)
 
 	rootCmd.AddCommand(
 		queryCmd,
 		txCmd,
 		client.LineBreak,
+		lcd.ServeCommand(cdc, registerRoutes),
+		orderbookstream.GetCmdStream("orderbook", cdc),
+		marketmaker.GetCmdMarketMaker(cdc),
+		client.LineBreak,
 	)
 
 	rootCmd.AddCommand(
@@ -80,3 +126,13 @@ func main() {
 		panic(err)
 	}
 }
+
+// registers the routes served by the rest-server command
+func registerRoutes(rs *lcd.RestServer) {
+	keys.RegisterRoutes(rs.Mux, rs.CliCtx.Indent)
+	rpc.RegisterRoutes(rs.CliCtx, rs.Mux)
+	tx.RegisterRoutes(rs.CliCtx, rs.Mux, rs.Cdc)
+	auth.RegisterRoutes(rs.CliCtx, rs.Mux, rs.Cdc, storeAcc)
+	bank.RegisterRoutes(rs.CliCtx, rs.Mux, rs.Cdc, rs.KeyBase)
+	orderbook.RegisterRoutes(rs.CliCtx, rs.Mux, rs.Cdc, rs.KeyBase, "orderbook")
+}
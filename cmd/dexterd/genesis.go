@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/server"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	app "github.com/sunnya97/sdk-dex-mvp"
+	cfg "github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/libs/cli"
+	"github.com/tendermint/tendermint/p2p"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+const flagOverwrite = "overwrite"
+
+// directory in a node's config folder the gentxs of the initial validators are collected in
+func genTxsDir(config *cfg.Config) string {
+	return filepath.Join(config.RootDir, "config", "gentx")
+}
+
+// Reads the app state of a genesis file
+func loadGenesisState(cdc *codec.Codec, genesisFile string) (genDoc *tmtypes.GenesisDoc, genesisState app.GenesisState, err error) {
+	genDoc, err = tmtypes.GenesisDocFromFile(genesisFile)
+	if err != nil {
+		return nil, genesisState, err
+	}
+	err = cdc.UnmarshalJSON(genDoc.AppState, &genesisState)
+	if err != nil {
+		return nil, genesisState, fmt.Errorf("failed to read the app state of %s: %s", genesisFile, err)
+	}
+	return genDoc, genesisState, nil
+}
+
+// Writes a genesis file with a new app state
+func saveGenesisState(cdc *codec.Codec, genesisFile string, genDoc *tmtypes.GenesisDoc, genesisState app.GenesisState) error {
+	appState, err := codec.MarshalJSONIndent(cdc, genesisState)
+	if err != nil {
+		return err
+	}
+	genDoc.AppState = appState
+	return genDoc.SaveAs(genesisFile)
+}
+
+// get cmd to fund an account in the genesis file
+func AddGenesisAccountCmd(ctx *server.Context, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "add-genesis-account [address] [coins]",
+		Short: "Add a funded account to the genesis file",
+		Long: `Add a funded account to the genesis file, e.g.
+	dexterd add-genesis-account cosmos1... 1000000BTC,1000000ETH,100steak`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			config := ctx.Config
+			config.SetRoot(viper.GetString(cli.HomeFlag))
+
+			addr, err := sdk.AccAddressFromBech32(args[0])
+			if err != nil {
+				return err
+			}
+			coins, err := sdk.ParseCoins(args[1])
+			if err != nil {
+				return err
+			}
+			err = app.ValidateGenesisCoins(coins)
+			if err != nil {
+				return err
+			}
+
+			genDoc, genesisState, err := loadGenesisState(cdc, config.GenesisFile())
+			if err != nil {
+				return err
+			}
+			for _, acc := range genesisState.Accounts {
+				if acc.Address.Equals(addr) {
+					return fmt.Errorf("account %s is already in genesis", addr)
+				}
+			}
+			genesisState.Accounts = append(genesisState.Accounts, auth.BaseAccount{Address: addr, Coins: coins})
+			genesisState = app.SetGenesisLooseTokens(genesisState)
+
+			return saveGenesisState(cdc, config.GenesisFile(), genDoc, genesisState)
+		},
+	}
+}
+
+// get cmd to add the gentxs in config/gentx to the genesis file, and set the nodes that made them as persistent peers
+func CollectGenTxsCmd(ctx *server.Context, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "collect-gentxs",
+		Short: "Add the gentxs in config/gentx to the genesis file",
+		Long: `Add the gentxs in config/gentx to the genesis file, replacing the ones already in it,
+and set the nodes that made them as this node's persistent peers.`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			config := ctx.Config
+			config.SetRoot(viper.GetString(cli.HomeFlag))
+
+			genDoc, genesisState, err := loadGenesisState(cdc, config.GenesisFile())
+			if err != nil {
+				return err
+			}
+			nodeKey, err := p2p.LoadNodeKey(config.NodeKeyFile())
+			if err != nil {
+				return err
+			}
+
+			genTxs, peers, err := readGenTxs(cdc, genTxsDir(config), genesisState.Accounts)
+			if err != nil {
+				return err
+			}
+			genesisState.GenTxs = genTxs
+			genesisState = app.SetGenesisLooseTokens(genesisState)
+
+			err = app.ValidateGenesisState(cdc, genesisState)
+			if err != nil {
+				return err
+			}
+			err = saveGenesisState(cdc, config.GenesisFile(), genDoc, genesisState)
+			if err != nil {
+				return err
+			}
+
+			// a node doesn't peer with itself
+			var otherPeers []string
+			for _, peer := range peers {
+				if !strings.HasPrefix(peer, string(nodeKey.ID())+"@") {
+					otherPeers = append(otherPeers, peer)
+				}
+			}
+			config.P2P.PersistentPeers = strings.Join(otherPeers, ",")
+			cfg.WriteConfigFile(filepath.Join(config.RootDir, "config", "config.toml"), config)
+
+			fmt.Fprintf(os.Stderr, "collected %d gentxs into %s\n", len(genTxs), config.GenesisFile())
+			return nil
+		},
+	}
+}
+
+// Reads and validates the gentxs in a directory. Returns them with the addresses of the nodes that made them,
+// which are the gentxs' memos
+func readGenTxs(cdc *codec.Codec, dir string, accounts []auth.BaseAccount) (genTxs []json.RawMessage, peers []string, err error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		bz, err := ioutil.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var tx auth.StdTx
+		err = cdc.UnmarshalJSON(bz, &tx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read gentx %s: %s", file.Name(), err)
+		}
+		err = app.ValidateGenTx(accounts, tx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid gentx %s: %s", file.Name(), err)
+		}
+		genTxs = append(genTxs, bz)
+
+		if tx.Memo != "" {
+			peers = append(peers, tx.Memo)
+		}
+	}
+	if len(genTxs) == 0 {
+		return nil, nil, fmt.Errorf("no gentxs in %s", dir)
+	}
+
+	sort.Strings(peers)
+	return genTxs, peers, nil
+}
+
+// get cmd to check that a genesis file can start the chain
+func ValidateGenesisCmd(ctx *server.Context, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate-genesis [file]",
+		Short: "Check that a genesis file can start the chain, by default the node's own",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			config := ctx.Config
+			config.SetRoot(viper.GetString(cli.HomeFlag))
+
+			genesisFile := config.GenesisFile()
+			if len(args) == 1 {
+				genesisFile = args[0]
+			}
+
+			_, genesisState, err := loadGenesisState(cdc, genesisFile)
+			if err != nil {
+				return err
+			}
+			err = app.ValidateGenesisState(cdc, genesisState)
+			if err != nil {
+				return fmt.Errorf("invalid genesis file %s: %s", genesisFile, err)
+			}
+
+			fmt.Printf("genesis file %s is valid\n", genesisFile)
+			return nil
+		},
+	}
+}
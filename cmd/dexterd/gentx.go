@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/keys"
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/server"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/stake"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tendermint/tendermint/libs/cli"
+	"github.com/tendermint/tendermint/p2p"
+
+	app "github.com/sunnya97/sdk-dex-mvp"
+
+	gaiaInit "github.com/cosmos/cosmos-sdk/cmd/gaia/init"
+)
+
+const (
+	flagClientHome              = "home-client"
+	flagAmount                  = "amount"
+	flagMoniker                 = "moniker"
+	flagIP                      = "ip"
+	flagCommissionRate          = "commission-rate"
+	flagCommissionMaxRate       = "commission-max-rate"
+	flagCommissionMaxChangeRate = "commission-max-change-rate"
+)
+
+// Builds the gentx creating this node's validator, signed with a key of the client keybase.
+// Gentxs are delivered before any account number is assigned, so they're signed with account number 0
+func buildGenTx(cdc *codec.Codec, kb keys.Keybase, name string, passphrase string, chainID string, msg stake.MsgCreateValidator, memo string) (auth.StdTx, error) {
+	signMsg := auth.StdSignMsg{
+		ChainID: chainID,
+		Fee:     auth.NewStdFee(200000),
+		Msgs:    []sdk.Msg{msg},
+		Memo:    memo,
+	}
+	sig, pubKey, err := kb.Sign(name, passphrase, signMsg.Bytes())
+	if err != nil {
+		return auth.StdTx{}, err
+	}
+	return auth.NewStdTx(signMsg.Msgs, signMsg.Fee, []auth.StdSignature{{PubKey: pubKey, Signature: sig}}, memo), nil
+}
+
+// get cmd to create the gentx of this node's validator, to be added to the genesis file with collect-gentxs
+func GenTxCmd(ctx *server.Context, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gentx",
+		Short: "Create the transaction bonding this node's validator at genesis",
+		Long: `Create the transaction bonding this node's validator at genesis, signed with a key of the client keybase.
+The key's account must be funded in the genesis file with at least the delegated amount.
+The gentx is written to config/gentx, from where it is added to the genesis file with "dexterd collect-gentxs".`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			config := ctx.Config
+			config.SetRoot(viper.GetString(cli.HomeFlag))
+
+			nodeKey, err := p2p.LoadOrGenNodeKey(config.NodeKeyFile())
+			if err != nil {
+				return err
+			}
+			nodeID := string(nodeKey.ID())
+			valPubKey := gaiaInit.ReadOrCreatePrivValidator(config.PrivValidatorFile())
+
+			genDoc, genesisState, err := loadGenesisState(cdc, config.GenesisFile())
+			if err != nil {
+				return err
+			}
+
+			name := viper.GetString(client.FlagName)
+			kb, err := keys.GetKeyBaseFromDir(viper.GetString(flagClientHome))
+			if err != nil {
+				return err
+			}
+			info, err := kb.Get(name)
+			if err != nil {
+				return err
+			}
+
+			amount, err := sdk.ParseCoin(viper.GetString(flagAmount))
+			if err != nil {
+				return err
+			}
+			rate, err := sdk.NewDecFromStr(viper.GetString(flagCommissionRate))
+			if err != nil {
+				return err
+			}
+			maxRate, err := sdk.NewDecFromStr(viper.GetString(flagCommissionMaxRate))
+			if err != nil {
+				return err
+			}
+			maxChangeRate, err := sdk.NewDecFromStr(viper.GetString(flagCommissionMaxChangeRate))
+			if err != nil {
+				return err
+			}
+
+			moniker := viper.GetString(flagMoniker)
+			if moniker == "" {
+				moniker = name
+			}
+
+			msg := stake.NewMsgCreateValidator(
+				sdk.ValAddress(info.GetAddress()),
+				valPubKey,
+				amount,
+				stake.Description{Moniker: moniker},
+				stake.NewCommissionMsg(rate, maxRate, maxChangeRate),
+			)
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			// fail now rather than when the chain starts if the validator can't be created
+			tx := auth.NewStdTx([]sdk.Msg{msg}, auth.NewStdFee(200000), nil, "")
+			err = app.ValidateGenTx(genesisState.Accounts, tx)
+			if err != nil {
+				return err
+			}
+
+			passphrase, err := keys.GetPassphrase(name)
+			if err != nil {
+				return err
+			}
+			memo := fmt.Sprintf("%s@%s:26656", nodeID, viper.GetString(flagIP))
+			tx, err = buildGenTx(cdc, kb, name, passphrase, genDoc.ChainID, msg, memo)
+			if err != nil {
+				return err
+			}
+
+			bz, err := codec.MarshalJSONIndent(cdc, tx)
+			if err != nil {
+				return err
+			}
+			err = os.MkdirAll(genTxsDir(config), 0700)
+			if err != nil {
+				return err
+			}
+			file := filepath.Join(genTxsDir(config), fmt.Sprintf("gentx-%s.json", nodeID))
+			err = ioutil.WriteFile(file, bz, 0644)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(os.Stderr, "gentx written to %s\n", file)
+			return nil
+		},
+	}
+
+	ip, _ := server.ExternalIP()
+	cmd.Flags().String(client.FlagName, "", "name of the client key the validator is created and signed with")
+	cmd.Flags().String(flagClientHome, os.ExpandEnv("$HOME/.dextercli"), "client home directory holding the key")
+	cmd.Flags().String(flagAmount, "100"+stake.DefaultParams().BondDenom, "amount of coins the validator bonds")
+	cmd.Flags().String(flagMoniker, "", "validator name, the key name if left blank")
+	cmd.Flags().String(flagIP, ip, "IP address other nodes reach this node at")
+	cmd.Flags().String(flagCommissionRate, "0.1", "initial commission rate")
+	cmd.Flags().String(flagCommissionMaxRate, "0.2", "maximum commission rate")
+	cmd.Flags().String(flagCommissionMaxChangeRate, "0.01", "maximum daily commission change")
+	cmd.MarkFlagRequired(client.FlagName)
+	return cmd
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/cosmos/cosmos-sdk/server"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tendermint/tendermint/libs/cli"
+	dbm "github.com/tendermint/tendermint/libs/db"
+
+	app "github.com/sunnya97/sdk-dex-mvp"
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+)
+
+// CheckInvariantsCmd returns a command checking the orderbook invariants against the state of a stopped node
+func CheckInvariantsCmd(ctx *server.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check-invariants",
+		Short: "Check the orderbook invariants against the latest state of the node",
+		Long: `Check the orderbook invariants against the latest state in the data directory of the node.
+The node must be stopped, as its application database is opened directly.`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			config := ctx.Config
+			config.SetRoot(viper.GetString(cli.HomeFlag))
+
+			db, err := dbm.NewGoLevelDB("application", filepath.Join(config.RootDir, "data"))
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			dexterApp := app.NewDexterApp(ctx.Logger, db, viper.GetInt64(flagOrderbookUpgradeHeight))
+			err = dexterApp.AssertInvariants()
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("All %d orderbook invariants hold at height %d\n", len(orderbook.Invariants()), dexterApp.LastBlockHeight())
+			return nil
+		},
+	}
+	return cmd
+}
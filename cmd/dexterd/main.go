@@ -26,6 +26,8 @@ import (
 // DefaultNodeHome sets the folder where the applcation data and configuration will be stored
 var DefaultNodeHome = os.ExpandEnv("$HOME/.dexterd")
 
+const flagOrderbookUpgradeHeight = "orderbook-upgrade-height"
+
 func main() {
 	cobra.EnableCommandSorting = false
 
@@ -33,7 +35,7 @@ func main() {
 	ctx := server.NewDefaultContext()
 
 	appInit := server.AppInit{
-		AppGenState: server.SimpleAppGenState,
+		AppGenState: appGenState,
 	}
 
 	rootCmd := &cobra.Command{
@@ -43,8 +45,16 @@ func main() {
 	}
 
 	rootCmd.AddCommand(InitCmd(ctx, cdc, appInit))
+	rootCmd.AddCommand(GenTxCmd(ctx, cdc))
+	rootCmd.AddCommand(AddGenesisAccountCmd(ctx, cdc))
+	rootCmd.AddCommand(CollectGenTxsCmd(ctx, cdc))
+	rootCmd.AddCommand(ValidateGenesisCmd(ctx, cdc))
+	rootCmd.AddCommand(TestnetFilesCmd(ctx, cdc))
+	rootCmd.AddCommand(CheckInvariantsCmd(ctx))
 
 	server.AddCommands(ctx, cdc, rootCmd, appInit, newApp, exportAppStateAndTMValidators)
+	rootCmd.PersistentFlags().Int64(flagOrderbookUpgradeHeight, 0,
+		"Height at which the orderbook store is migrated to the schema version of this release, once every node was stopped before it")
 
 	// prepare and add flags
 	executor := cli.PrepareBaseCmd(rootCmd, "DEX", DefaultNodeHome)
@@ -56,65 +66,72 @@ func main() {
 }
 
 func newApp(logger log.Logger, db dbm.DB, traceStore io.Writer) abci.Application {
-	return app.NewDexterApp(logger, db)
+	return app.NewDexterApp(logger, db, viper.GetInt64(flagOrderbookUpgradeHeight))
+}
+
+// appGenState returns the default state of every module, with the gentxs that create the initial validators
+func appGenState(cdc *codec.Codec, genDoc tmtypes.GenesisDoc, appGenTxs []json.RawMessage) (appState json.RawMessage, err error) {
+	genesisState := app.NewDefaultGenesisState()
+	genesisState.GenTxs = appGenTxs
+	return codec.MarshalJSONIndent(cdc, genesisState)
 }
 
 func exportAppStateAndTMValidators(logger log.Logger, db dbm.DB, traceStore io.Writer) (json.RawMessage, []tmtypes.GenesisValidator, error) {
 	return nil, nil, nil
 }
 
-// get cmd to initialize all files for tendermint and application
+// get cmd to initialize all files for tendermint and application.
+// The genesis starts without accounts or validators: they are added with add-genesis-account and collect-gentxs
 // nolint: errcheck
 func InitCmd(ctx *server.Context, cdc *codec.Codec, appInit server.AppInit) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "init",
 		Short: "Initialize genesis config, priv-validator file, and p2p-node file",
-		Args:  cobra.NoArgs,
+		Long: `Initialize genesis config, priv-validator file, and p2p-node file.
+Then fund accounts with "dexterd add-genesis-account", create the gentxs of the validators with
+"dexterd gentx", copy them to config/gentx and add them to the genesis file with "dexterd collect-gentxs".`,
+		Args: cobra.NoArgs,
 		RunE: func(_ *cobra.Command, _ []string) error {
 
 			config := ctx.Config
 			config.SetRoot(viper.GetString(cli.HomeFlag))
-			chainID := viper.GetString(client.FlagChainID)
-			if chainID == "" {
-				chainID = fmt.Sprintf("test-chain-%v", common.RandStr(6))
-			}
 
 			nodeKey, err := p2p.LoadOrGenNodeKey(config.NodeKeyFile())
 			if err != nil {
 				return err
 			}
 			nodeID := string(nodeKey.ID())
+			gaiaInit.ReadOrCreatePrivValidator(config.PrivValidatorFile())
 
-			pk := gaiaInit.ReadOrCreatePrivValidator(config.PrivValidatorFile())
-			genTx, appMessage, validator, err := server.SimpleAppGenTx(cdc, pk)
-			if err != nil {
-				return err
+			if !viper.GetBool(flagOverwrite) && common.FileExists(config.GenesisFile()) {
+				return fmt.Errorf("genesis file %s already exists, use --%s to replace it", config.GenesisFile(), flagOverwrite)
 			}
-
-			appState, err := appInit.AppGenState(cdc, tmtypes.GenesisDoc{}, []json.RawMessage{genTx})
-			if err != nil {
-				return err
+			chainID := viper.GetString(client.FlagChainID)
+			if chainID == "" {
+				chainID = fmt.Sprintf("test-chain-%v", common.RandStr(6))
 			}
-			appStateJSON, err := cdc.MarshalJSON(appState)
+			appStateJSON, err := appInit.AppGenState(cdc, tmtypes.GenesisDoc{}, nil)
 			if err != nil {
 				return err
 			}
 
 			toPrint := struct {
-				ChainID    string          `json:"chain_id"`
-				NodeID     string          `json:"node_id"`
-				AppMessage json.RawMessage `json:"app_message"`
+				ChainID string `json:"chain_id"`
+				NodeID  string `json:"node_id"`
 			}{
 				chainID,
 				nodeID,
-				appMessage,
 			}
 			out, err := codec.MarshalJSONIndent(cdc, toPrint)
 			if err != nil {
 				return err
 			}
 			fmt.Fprintf(os.Stderr, "%s\n", string(out))
-			return gaiaInit.WriteGenesisFile(config.GenesisFile(), chainID, []tmtypes.GenesisValidator{validator}, appStateJSON)
+			return gaiaInit.WriteGenesisFile(config.GenesisFile(), chainID, nil, appStateJSON)
 		},
 	}
+
+	cmd.Flags().String(client.FlagChainID, "", "genesis file chain-id, if left blank will be randomly created")
+	cmd.Flags().Bool(flagOverwrite, false, "overwrite the genesis file")
+	return cmd
 }
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/keys"
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/server"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/stake"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tendermint/tendermint/libs/common"
+	"github.com/tendermint/tendermint/p2p"
+	tmtypes "github.com/tendermint/tendermint/types"
+	tmtime "github.com/tendermint/tendermint/types/time"
+
+	gaiaInit "github.com/cosmos/cosmos-sdk/cmd/gaia/init"
+	app "github.com/sunnya97/sdk-dex-mvp"
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+	cfg "github.com/tendermint/tendermint/config"
+)
+
+const (
+	flagNumValidators  = "v"
+	flagOutputDir      = "o"
+	flagNodeDirPrefix  = "node-dir-prefix"
+	flagNodeDaemonHome = "node-daemon-home"
+	flagNodeCLIHome    = "node-cli-home"
+	flagStartingPort   = "starting-port"
+	flagCoins          = "coins"
+	flagMarkets        = "markets"
+
+	// passphrase of the validator keys created for a testnet
+	testnetKeyPass = "12345678"
+)
+
+// get cmd to write the home directories of a local testnet's nodes
+func TestnetFilesCmd(ctx *server.Context, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "testnet",
+		Short: "Initialize files for a local multi-validator testnet",
+		Long: `Write the home directories of the nodes of a testnet running on one machine.
+Every node gets a validator key, a genesis account funded with --coins and the bond amount,
+and the same genesis file, which lists --markets and bonds every validator with a gentx.
+The nodes listen on consecutive ports from --starting-port and are each other's persistent peers.
+
+Example:
+	dexterd testnet --v 4 --o ./mytestnet --coins 1000000BTC,1000000ETH --markets BTC/ETH`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return initTestnet(cdc)
+		},
+	}
+
+	cmd.Flags().Int(flagNumValidators, 4, "number of validators to initialize the testnet with")
+	cmd.Flags().String(flagOutputDir, "./mytestnet", "directory to store the node directories in")
+	cmd.Flags().String(flagNodeDirPrefix, "node", "prefix of the node directories, followed by the node's index")
+	cmd.Flags().String(flagNodeDaemonHome, "dexterd", "home directory of the node's daemon configuration")
+	cmd.Flags().String(flagNodeCLIHome, "dextercli", "home directory of the node's cli configuration")
+	cmd.Flags().String(flagIP, "127.0.0.1", "IP address all the nodes listen on")
+	cmd.Flags().Int(flagStartingPort, 26656, "p2p port of the first node, each node uses the 10 ports from its own")
+	cmd.Flags().String(flagCoins, "1000000000BTC,1000000000ETH", "coins each validator's account is funded with, besides its bond")
+	cmd.Flags().String(flagAmount, "100"+stake.DefaultParams().BondDenom, "amount of coins each validator bonds")
+	cmd.Flags().String(flagMarkets, "BTC/ETH", "comma separated markets listed at genesis, as base/quote")
+	cmd.Flags().String(client.FlagChainID, "", "genesis file chain-id, if left blank will be randomly created")
+	return cmd
+}
+
+// a node of the testnet, with the account and gentx of its validator
+type testnetNode struct {
+	name     string
+	config   *cfg.Config
+	account  auth.BaseAccount
+	genTx    json.RawMessage
+	peerAddr string
+}
+
+func initTestnet(cdc *codec.Codec) error {
+	numValidators := viper.GetInt(flagNumValidators)
+	outputDir := viper.GetString(flagOutputDir)
+	ip := viper.GetString(flagIP)
+	startingPort := viper.GetInt(flagStartingPort)
+
+	chainID := viper.GetString(client.FlagChainID)
+	if chainID == "" {
+		chainID = fmt.Sprintf("test-chain-%v", common.RandStr(6))
+	}
+
+	coins, err := sdk.ParseCoins(viper.GetString(flagCoins))
+	if err != nil {
+		return err
+	}
+	bond, err := sdk.ParseCoin(viper.GetString(flagAmount))
+	if err != nil {
+		return err
+	}
+	markets, err := parseMarkets(viper.GetString(flagMarkets))
+	if err != nil {
+		return err
+	}
+
+	nodes := make([]testnetNode, numValidators)
+	for i := range nodes {
+		nodes[i], err = initTestnetNode(cdc, chainID, outputDir, i, ip, startingPort+10*i, coins.Plus(sdk.Coins{bond}), bond)
+		if err != nil {
+			return err
+		}
+	}
+
+	genesisState := app.NewDefaultGenesisState()
+	genesisState.OrderbookData.Markets = markets
+	for _, node := range nodes {
+		genesisState.Accounts = append(genesisState.Accounts, node.account)
+		genesisState.GenTxs = append(genesisState.GenTxs, node.genTx)
+	}
+	genesisState = app.SetGenesisLooseTokens(genesisState)
+	err = app.ValidateGenesisState(cdc, genesisState)
+	if err != nil {
+		return err
+	}
+	appState, err := codec.MarshalJSONIndent(cdc, genesisState)
+	if err != nil {
+		return err
+	}
+
+	// every node gets the same genesis file, down to the genesis time
+	genDoc := tmtypes.GenesisDoc{
+		GenesisTime: tmtime.Now(),
+		ChainID:     chainID,
+		AppState:    appState,
+	}
+	for i, node := range nodes {
+		err = genDoc.SaveAs(node.config.GenesisFile())
+		if err != nil {
+			return err
+		}
+
+		var peers []string
+		for j, peer := range nodes {
+			if j != i {
+				peers = append(peers, peer.peerAddr)
+			}
+		}
+		node.config.P2P.PersistentPeers = strings.Join(peers, ",")
+		cfg.WriteConfigFile(filepath.Join(node.config.RootDir, "config", "config.toml"), node.config)
+	}
+
+	fmt.Printf("Successfully initialized %d node directories in %s for chain %s\n", numValidators, outputDir, chainID)
+	fmt.Printf("Node i is started with \"dexterd start --home %s/<node>/%s\" and its RPC listens on port %d+10*i\n",
+		outputDir, viper.GetString(flagNodeDaemonHome), startingPort+1)
+	return nil
+}
+
+// Writes the home directories of a node, with a validator key in its cli home that funds and signs its gentx
+func initTestnetNode(cdc *codec.Codec, chainID string, outputDir string, i int, ip string, port int,
+	coins sdk.Coins, bond sdk.Coin) (node testnetNode, err error) {
+
+	node.name = fmt.Sprintf("%s%d", viper.GetString(flagNodeDirPrefix), i)
+	nodeDir := filepath.Join(outputDir, node.name)
+	clientDir := filepath.Join(nodeDir, viper.GetString(flagNodeCLIHome))
+
+	config := cfg.DefaultConfig()
+	config.SetRoot(filepath.Join(nodeDir, viper.GetString(flagNodeDaemonHome)))
+	config.Moniker = node.name
+	config.P2P.ListenAddress = fmt.Sprintf("tcp://0.0.0.0:%d", port)
+	config.RPC.ListenAddress = fmt.Sprintf("tcp://0.0.0.0:%d", port+1)
+	config.ProxyApp = fmt.Sprintf("tcp://127.0.0.1:%d", port+2)
+	config.P2P.AddrBookStrict = false
+	config.P2P.AllowDuplicateIP = true
+	node.config = config
+
+	err = os.MkdirAll(filepath.Join(config.RootDir, "config"), 0700)
+	if err != nil {
+		return node, err
+	}
+	err = os.MkdirAll(clientDir, 0700)
+	if err != nil {
+		return node, err
+	}
+
+	nodeKey, err := p2p.LoadOrGenNodeKey(config.NodeKeyFile())
+	if err != nil {
+		return node, err
+	}
+	node.peerAddr = fmt.Sprintf("%s@%s:%d", nodeKey.ID(), ip, port)
+	valPubKey := gaiaInit.ReadOrCreatePrivValidator(config.PrivValidatorFile())
+
+	addr, secret, err := server.GenerateSaveCoinKey(clientDir, node.name, testnetKeyPass, true)
+	if err != nil {
+		return node, err
+	}
+	node.account = auth.BaseAccount{Address: addr, Coins: coins}
+
+	// the key's seed is kept with the node, so its account can be recovered
+	seedJSON, err := codec.MarshalJSONIndent(cdc, map[string]string{"secret": secret})
+	if err != nil {
+		return node, err
+	}
+	err = ioutil.WriteFile(filepath.Join(clientDir, "key_seed.json"), seedJSON, 0600)
+	if err != nil {
+		return node, err
+	}
+
+	msg := stake.NewMsgCreateValidator(
+		sdk.ValAddress(addr),
+		valPubKey,
+		bond,
+		stake.Description{Moniker: node.name},
+		stake.NewCommissionMsg(sdk.NewDecWithPrec(1, 1), sdk.NewDecWithPrec(2, 1), sdk.NewDecWithPrec(1, 2)),
+	)
+	kb, err := keys.GetKeyBaseFromDir(clientDir)
+	if err != nil {
+		return node, err
+	}
+	tx, err := buildGenTx(cdc, kb, node.name, testnetKeyPass, chainID, msg, node.peerAddr)
+	if err != nil {
+		return node, err
+	}
+	node.genTx, err = cdc.MarshalJSON(tx)
+	if err != nil {
+		return node, err
+	}
+
+	err = os.MkdirAll(genTxsDir(config), 0700)
+	if err != nil {
+		return node, err
+	}
+	err = ioutil.WriteFile(filepath.Join(genTxsDir(config), fmt.Sprintf("gentx-%s.json", nodeKey.ID())), node.genTx, 0644)
+	return node, err
+}
+
+// Parses comma separated markets written as base/quote
+func parseMarkets(marketsStr string) (markets []orderbook.Market, err error) {
+	for _, marketStr := range strings.Split(marketsStr, ",") {
+		marketStr = strings.TrimSpace(marketStr)
+		if marketStr == "" {
+			continue
+		}
+		denoms := strings.Split(marketStr, "/")
+		if len(denoms) != 2 {
+			return nil, fmt.Errorf("market %s must be written as base/quote", marketStr)
+		}
+		market := orderbook.NewMarket(denoms[0], denoms[1])
+		err = market.Validate()
+		if err != nil {
+			return nil, err
+		}
+		markets = append(markets, market)
+	}
+	return markets, nil
+}
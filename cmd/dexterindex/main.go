@@ -0,0 +1,90 @@
+package main
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/tendermint/tendermint/libs/cli"
+	"github.com/tendermint/tendermint/libs/log"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/context"
+
+	app "github.com/sunnya97/sdk-dex-mvp"
+	"github.com/sunnya97/sdk-dex-mvp/indexer"
+)
+
+const (
+	flagDB            = "db"
+	flagNodeHome      = "node-home"
+	flagConfirmations = "confirmations"
+	flagInterval      = "interval"
+	flagOnce          = "once"
+)
+
+// DefaultIndexHome is where the index is written by default
+var DefaultIndexHome = os.ExpandEnv("$HOME/.dexterindex")
+
+func main() {
+	cdc := app.MakeCodec()
+
+	rootCmd := &cobra.Command{
+		Use:   "dexterindex",
+		Short: "Index the orders, trades and trading balance changes of a Dexter chain into SQLite",
+		Long: `Index the orders, trades and trading balance changes of a Dexter chain into SQLite.
+Blocks are followed from the RPC of a running node, or replayed from the block store of a stopped node with --node-home.
+Indexing resumes after the last indexed block when restarted.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger := log.NewTMLogger(log.NewSyncWriter(os.Stdout))
+
+			dbPath := viper.GetString(flagDB)
+			err := os.MkdirAll(filepath.Dir(dbPath), 0755)
+			if err != nil {
+				return err
+			}
+			db, err := sql.Open("sqlite3", dbPath)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			var source indexer.Source
+			if nodeHome := viper.GetString(flagNodeHome); nodeHome != "" {
+				source = indexer.NewBlockStoreSource(nodeHome)
+			} else {
+				source = indexer.NewNodeSource(context.NewCLIContext().WithCodec(cdc))
+			}
+
+			ix, err := indexer.NewIndexer(db, source, cdc, viper.GetInt64(flagConfirmations), logger)
+			if err != nil {
+				return err
+			}
+
+			if viper.GetBool(flagOnce) {
+				indexed, err := ix.Sync()
+				logger.Info("indexed blocks", "count", indexed)
+				return err
+			}
+			return ix.Run(viper.GetDuration(flagInterval))
+		},
+	}
+	rootCmd.Flags().String(flagDB, filepath.Join(DefaultIndexHome, "index.db"), "Path of the SQLite database to write")
+	rootCmd.Flags().String(flagNodeHome, "", "Replay the block store of the stopped node with this home instead of following --node")
+	rootCmd.Flags().Int64(flagConfirmations, 1, "Number of blocks a block must be buried under before it's indexed")
+	rootCmd.Flags().Duration(flagInterval, time.Second, "How often to check for new blocks")
+	rootCmd.Flags().Bool(flagOnce, false, "Index the blocks available now and exit")
+	rootCmd = client.GetCommands(rootCmd)[0]
+
+	executor := cli.PrepareMainCmd(rootCmd, "DEX", DefaultIndexHome)
+	err := executor.Execute()
+	if err != nil {
+		panic(err)
+	}
+}
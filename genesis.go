@@ -0,0 +1,153 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	distr "github.com/cosmos/cosmos-sdk/x/distribution"
+	"github.com/cosmos/cosmos-sdk/x/gov"
+	"github.com/cosmos/cosmos-sdk/x/slashing"
+	"github.com/cosmos/cosmos-sdk/x/stake"
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+	"github.com/sunnya97/sdk-dex-mvp/x/token"
+)
+
+// GenesisState is the state a Dexter chain starts with.
+// GenTxs are signed MsgCreateValidator transactions delivered at genesis to create the initial validator set
+type GenesisState struct {
+	Accounts      []auth.BaseAccount     `json:"accounts"`
+	AuthData      auth.GenesisState      `json:"auth"`
+	StakeData     stake.GenesisState     `json:"stake"`
+	SlashingData  slashing.GenesisState  `json:"slashing"`
+	DistrData     distr.GenesisState     `json:"distr"`
+	GovData       gov.GenesisState       `json:"gov"`
+	OrderbookData orderbook.GenesisState `json:"orderbook"`
+	TokenData     token.GenesisState     `json:"token"`
+	GenTxs        []json.RawMessage      `json:"gentxs"`
+}
+
+// Returns the genesis state of a new chain with no accounts and the default state of every module
+func NewDefaultGenesisState() GenesisState {
+	return GenesisState{
+		AuthData:      auth.DefaultGenesisState(),
+		StakeData:     stake.DefaultGenesisState(),
+		SlashingData:  slashing.DefaultGenesisState(),
+		DistrData:     distr.DefaultGenesisState(),
+		GovData:       gov.DefaultGenesisState(),
+		OrderbookData: orderbook.DefaultGenesisState(),
+		TokenData:     token.DefaultGenesisState(),
+	}
+}
+
+// Checks that a genesis state can be used to start a chain: accounts hold valid coins and are listed once,
+// the module states validate, and every gentx creates a validator funded by a genesis account
+func ValidateGenesisState(cdc *codec.Codec, genesisState GenesisState) error {
+	err := ValidateGenesisAccounts(genesisState.Accounts)
+	if err != nil {
+		return err
+	}
+
+	err = stake.ValidateGenesis(genesisState.StakeData)
+	if err != nil {
+		return err
+	}
+	err = gov.ValidateGenesis(genesisState.GovData)
+	if err != nil {
+		return err
+	}
+	err = orderbook.ValidateGenesis(genesisState.OrderbookData)
+	if err != nil {
+		return err
+	}
+	err = token.ValidateGenesis(genesisState.TokenData)
+	if err != nil {
+		return err
+	}
+
+	for i, genTx := range genesisState.GenTxs {
+		var tx auth.StdTx
+		err = cdc.UnmarshalJSON(genTx, &tx)
+		if err != nil {
+			return fmt.Errorf("failed to read gentx %d: %s", i, err)
+		}
+		err = ValidateGenTx(genesisState.Accounts, tx)
+		if err != nil {
+			return fmt.Errorf("invalid gentx %d: %s", i, err)
+		}
+	}
+	return nil
+}
+
+// Checks that every genesis account has an address, is listed once, and holds valid coins
+func ValidateGenesisAccounts(accounts []auth.BaseAccount) error {
+	listed := make(map[string]bool)
+	for _, acc := range accounts {
+		if acc.Address.Empty() {
+			return fmt.Errorf("genesis account without an address")
+		}
+		if listed[acc.Address.String()] {
+			return fmt.Errorf("account %s is listed twice", acc.Address)
+		}
+		listed[acc.Address.String()] = true
+
+		err := ValidateGenesisCoins(acc.Coins)
+		if err != nil {
+			return fmt.Errorf("account %s: %s", acc.Address, err)
+		}
+	}
+	return nil
+}
+
+// Checks that coins are sorted, positive and of valid denoms
+func ValidateGenesisCoins(coins sdk.Coins) error {
+	for _, coin := range coins {
+		if _, err := sdk.ParseCoin(coin.String()); err != nil {
+			return fmt.Errorf("invalid coin %s", coin)
+		}
+	}
+	if !coins.IsValid() {
+		return fmt.Errorf("coins %s must be positive, sorted by denom and list each denom once", coins)
+	}
+	return nil
+}
+
+// Checks that a gentx only creates a validator, whose self delegation is funded by a genesis account
+func ValidateGenTx(accounts []auth.BaseAccount, tx auth.StdTx) error {
+	msgs := tx.GetMsgs()
+	if len(msgs) != 1 {
+		return fmt.Errorf("a gentx must have exactly one message, has %d", len(msgs))
+	}
+	msg, ok := msgs[0].(stake.MsgCreateValidator)
+	if !ok {
+		return fmt.Errorf("a gentx must create a validator, has a %s message", msgs[0].Type())
+	}
+	if err := msg.ValidateBasic(); err != nil {
+		return err
+	}
+
+	for _, acc := range accounts {
+		if acc.Address.Equals(msg.DelegatorAddr) {
+			if !acc.Coins.IsGTE(sdk.Coins{msg.Delegation}) {
+				return fmt.Errorf("account %s has %s, not enough to delegate %s", acc.Address, acc.Coins, msg.Delegation)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("account %s delegating to validator %s is not in genesis", msg.DelegatorAddr, msg.Description.Moniker)
+}
+
+// Sets the loose tokens of the stake pool to the bond denom coins of the genesis accounts, which the gentxs then bond.
+// The stake module doesn't count account balances itself, and its pool must hold the tokens a validator bonds.
+// The tokens are recounted rather than added, so the genesis state can be rebuilt after adding accounts or gentxs
+func SetGenesisLooseTokens(genesisState GenesisState) GenesisState {
+	bondDenom := genesisState.StakeData.Params.BondDenom
+	looseTokens := sdk.ZeroDec()
+	for _, acc := range genesisState.Accounts {
+		looseTokens = looseTokens.Add(sdk.NewDecFromInt(acc.Coins.AmountOf(bondDenom)))
+	}
+	genesisState.StakeData.Pool.LooseTokens = looseTokens
+	return genesisState
+}
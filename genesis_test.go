@@ -0,0 +1,43 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+)
+
+func TestValidateGenesisAccounts(t *testing.T) {
+	alice := sdk.AccAddress([]byte("alice_______________"))
+	bob := sdk.AccAddress([]byte("bob_________________"))
+
+	tests := []struct {
+		name     string
+		accounts []auth.BaseAccount
+		valid    bool
+	}{
+		{"funded accounts", []auth.BaseAccount{
+			{Address: alice, Coins: sdk.Coins{sdk.NewInt64Coin("BTC", 10), sdk.NewInt64Coin("ETH", 10)}},
+			{Address: bob, Coins: sdk.Coins{sdk.NewInt64Coin("steak", 100)}},
+		}, true},
+		{"no address", []auth.BaseAccount{{Coins: sdk.Coins{sdk.NewInt64Coin("BTC", 10)}}}, false},
+		{"listed twice", []auth.BaseAccount{{Address: alice}, {Address: alice}}, false},
+		{"unsorted coins", []auth.BaseAccount{
+			{Address: alice, Coins: sdk.Coins{sdk.NewInt64Coin("ETH", 10), sdk.NewInt64Coin("BTC", 10)}},
+		}, false},
+		{"zero coins", []auth.BaseAccount{{Address: alice, Coins: sdk.Coins{sdk.NewInt64Coin("BTC", 0)}}}, false},
+		{"invalid denom", []auth.BaseAccount{{Address: alice, Coins: sdk.Coins{sdk.NewInt64Coin("B T C", 10)}}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateGenesisAccounts(tt.accounts)
+			if tt.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,543 @@
+package indexer
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	cmn "github.com/tendermint/tendermint/libs/common"
+	"github.com/tendermint/tendermint/libs/log"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+)
+
+// Indexer follows the blocks of a Source and writes the orders, trades and trading balance changes they contain
+// into a SQL database.  Every block is written in a single database transaction along with the cursor, so that
+// the indexer can be stopped at any time and resumes after the last block it wrote.
+// Blocks are only indexed once they are confirmations blocks deep, and if the source turns out to be on another
+// fork than the indexed blocks, the blocks after the fork are rolled back and indexed again
+type Indexer struct {
+	db            *sql.DB
+	source        Source
+	cdc           *codec.Codec
+	txDecoder     sdk.TxDecoder
+	confirmations int64
+	logger        log.Logger
+}
+
+func NewIndexer(db *sql.DB, source Source, cdc *codec.Codec, confirmations int64, logger log.Logger) (*Indexer, error) {
+	_, err := db.Exec(schema)
+	if err != nil {
+		return nil, err
+	}
+	return &Indexer{
+		db:            db,
+		source:        source,
+		cdc:           cdc,
+		txDecoder:     auth.DefaultTxDecoder(cdc),
+		confirmations: confirmations,
+		logger:        logger,
+	}, nil
+}
+
+// Returns the height and hash of the last indexed block, or a height of zero if nothing was indexed yet
+func (ix *Indexer) Cursor() (height int64, hash cmn.HexBytes, err error) {
+	var hashStr string
+	err = ix.db.QueryRow("SELECT height, hash FROM cursor WHERE id = 1").Scan(&height, &hashStr)
+	if err == sql.ErrNoRows {
+		return 0, nil, nil
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	return height, hexBytes(hashStr), nil
+}
+
+// Indexes new blocks every interval until an error happens
+func (ix *Indexer) Run(interval time.Duration) error {
+	for {
+		indexed, err := ix.Sync()
+		if err != nil {
+			return err
+		}
+		if indexed == 0 {
+			time.Sleep(interval)
+		}
+	}
+}
+
+// Indexes the blocks of the source that are confirmed and not indexed yet.  Returns the number of blocks indexed
+func (ix *Indexer) Sync() (indexed int64, err error) {
+	latest, err := ix.source.LatestHeight()
+	if err != nil {
+		return 0, err
+	}
+	target := latest - ix.confirmations
+
+	for {
+		height, hash, err := ix.Cursor()
+		if err != nil {
+			return indexed, err
+		}
+		if height >= target {
+			return indexed, nil
+		}
+
+		block, err := ix.source.Block(height + 1)
+		if err != nil {
+			return indexed, err
+		}
+
+		if height > 0 && !bytes.Equal(block.LastHash, hash) {
+			forkHeight, err := ix.findForkHeight(height)
+			if err != nil {
+				return indexed, err
+			}
+			ix.logger.Info(fmt.Sprintf("block %d doesn't follow the indexed block %d, rolling back to %d", height+1, height, forkHeight))
+			err = ix.Rollback(forkHeight)
+			if err != nil {
+				return indexed, err
+			}
+			continue
+		}
+
+		err = ix.IndexBlock(block)
+		if err != nil {
+			return indexed, err
+		}
+		indexed++
+	}
+}
+
+// Returns the height of the last indexed block that the source agrees with
+func (ix *Indexer) findForkHeight(height int64) (int64, error) {
+	for ; height > 0; height-- {
+		var hashStr string
+		err := ix.db.QueryRow("SELECT hash FROM blocks WHERE height = ?", height).Scan(&hashStr)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		block, err := ix.source.Block(height)
+		if err != nil {
+			return 0, err
+		}
+		if bytes.Equal(block.Hash, hexBytes(hashStr)) {
+			return height, nil
+		}
+	}
+	return 0, nil
+}
+
+// Writes the orders, trades and balance changes of a block and moves the cursor to it
+func (ix *Indexer) IndexBlock(block Block) error {
+	tx, err := ix.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("INSERT INTO blocks (height, hash, time) VALUES (?, ?, ?)", block.Height, block.Hash.String(), block.Time)
+	if err != nil {
+		return err
+	}
+
+	for i, txBytes := range block.Txs {
+		if i >= len(block.DeliverTxs) || !block.DeliverTxs[i].IsOK() {
+			continue
+		}
+		stdTx, err := ix.txDecoder(txBytes)
+		if err != nil {
+			ix.logger.Info(fmt.Sprintf("could not decode tx %d of block %d: %s", i, block.Height, err))
+			continue
+		}
+		err = ix.indexTx(tx, block, cmn.HexBytes(txBytes.Hash()), stdTx.GetMsgs(), block.DeliverTxs[i].Data, block.DeliverTxs[i].Tags)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = ix.indexEndBlock(tx, block)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("INSERT OR REPLACE INTO cursor (id, height, hash) VALUES (1, ?, ?)", block.Height, block.Hash.String())
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Writes the orders made and removed by a successful tx and the trades they made
+func (ix *Indexer) indexTx(tx *sql.Tx, block Block, txHash cmn.HexBytes, msgs []sdk.Msg, data []byte, tags []cmn.KVPair) error {
+	// the data of the msgs of a tx are concatenated, and every MsgMakeOrder has a MakeOrderResult
+	results, err := splitResults(data)
+	if err != nil {
+		ix.logger.Info(fmt.Sprintf("could not read the results of tx %s: %s", txHash, err))
+		results = nil
+	}
+
+	for _, msg := range msgs {
+		makeOrder, ok := msg.(orderbook.MsgMakeOrder)
+		if !ok || len(results) == 0 {
+			continue
+		}
+		var result orderbook.MakeOrderResult
+		err = ix.cdc.UnmarshalJSON(results[0], &result)
+		results = results[1:]
+		if err != nil {
+			ix.logger.Info(fmt.Sprintf("could not read the result of an order of tx %s: %s", txHash, err))
+			continue
+		}
+
+		err = ix.indexOrder(tx, block, txHash, makeOrder, result)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, tag := range tags {
+		if string(tag.Key) != orderbook.TagCancelledOrderID {
+			continue
+		}
+		var orderID int64
+		fmt.Sscanf(string(tag.Value), "%d", &orderID)
+		err = ix.addOrderEvent(tx, orderID, block.Height, orderbook.OrderStatusCancelled)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Writes the orders expired by the EndBlocker of a block and the trades of the TWAP slices it executed.
+// The trades are read from the tags of the block, as they may be pruned from the state by the time it's indexed
+func (ix *Indexer) indexEndBlock(tx *sql.Tx, block Block) error {
+	for _, tag := range block.EndBlockTags {
+		switch string(tag.Key) {
+		case orderbook.TagTrade:
+			var trade orderbook.Trade
+			err := ix.cdc.UnmarshalJSON(tag.Value, &trade)
+			if err != nil {
+				return fmt.Errorf("could not read a trade of block %d: %s", block.Height, err)
+			}
+			err = ix.indexTrade(tx, trade)
+			if err != nil {
+				return err
+			}
+
+		case orderbook.TagExpiredOrderID:
+			var orderID int64
+			fmt.Sscanf(string(tag.Value), "%d", &orderID)
+			err := ix.addOrderEvent(tx, orderID, block.Height, orderbook.OrderStatusExpired)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Writes an order made by a MsgMakeOrder along with the trades it made
+func (ix *Indexer) indexOrder(tx *sql.Tx, block Block, txHash cmn.HexBytes, msg orderbook.MsgMakeOrder, result orderbook.MakeOrderResult) error {
+	_, err := tx.Exec(`INSERT INTO orders (order_id, client_order_id, owner, sell_denom, buy_denom, price, original_amount,
+		filled_amount, received_amount, status, created_height, closed_height, tx_hash)
+		VALUES (?, ?, ?, ?, ?, ?, ?, '0', '0', ?, ?, 0, ?)`,
+		result.OrderID, result.ClientOrderID, msg.OwnerAddr.String(), msg.SellCoins.Denom, msg.Price.NumeratorDenom,
+		msg.Price.Ratio.String(), msg.SellCoins.Amount.String(), result.Status, block.Height, txHash.String())
+	if err != nil {
+		return err
+	}
+
+	for _, trade := range result.Trades {
+		err = ix.indexTrade(tx, trade)
+		if err != nil {
+			return err
+		}
+	}
+	return ix.addOrderEvent(tx, result.OrderID, block.Height, result.Status)
+}
+
+// Writes a trade and the balance changes of its maker and taker, and updates the fills of the maker order
+func (ix *Indexer) indexTrade(tx *sql.Tx, trade orderbook.Trade) error {
+	_, err := tx.Exec(`INSERT INTO trades (trade_id, market, maker_order_id, taker_order_id, maker, taker,
+		maker_sold_amount, maker_sold_denom, taker_sold_amount, taker_sold_denom, maker_fee_amount, taker_fee_amount,
+		price, height, time) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		trade.TradeID, trade.Pair().String(), trade.MakerOrderID, trade.TakerOrderID, trade.Maker.String(), trade.Taker.String(),
+		trade.MakerSold.Amount.String(), trade.MakerSold.Denom, trade.TakerSold.Amount.String(), trade.TakerSold.Denom,
+		trade.MakerFee.Amount.String(), trade.TakerFee.Amount.String(), trade.Price.Ratio.String(), trade.Height, trade.Time)
+	if err != nil {
+		return err
+	}
+
+	changes := []struct {
+		owner  sdk.AccAddress
+		denom  string
+		amount sdk.Int
+	}{
+		{trade.Maker, trade.MakerSold.Denom, trade.MakerSold.Amount.Neg()},
+		{trade.Maker, trade.TakerSold.Denom, trade.TakerSold.Amount.Sub(trade.MakerFee.Amount)},
+		{trade.Taker, trade.TakerSold.Denom, trade.TakerSold.Amount.Neg()},
+		{trade.Taker, trade.MakerSold.Denom, trade.MakerSold.Amount.Sub(trade.TakerFee.Amount)},
+	}
+	for _, change := range changes {
+		_, err = tx.Exec("INSERT INTO balance_changes (height, trade_id, address, denom, amount) VALUES (?, ?, ?, ?, ?)",
+			trade.Height, trade.TradeID, change.owner.String(), change.denom, change.amount.String())
+		if err != nil {
+			return err
+		}
+		err = refreshBalance(tx, change.owner.String(), change.denom)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the status of the taker order is written from its result, once all of its trades are
+	status := orderbook.OrderStatusPartiallyFilled
+	var original string
+	err = tx.QueryRow("SELECT original_amount FROM orders WHERE order_id = ?", trade.MakerOrderID).Scan(&original)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	filled, _, err := orderFills(tx, trade.MakerOrderID)
+	if err != nil {
+		return err
+	}
+	if filled.GTE(parseInt(original)) {
+		status = orderbook.OrderStatusFilled
+	}
+	return ix.addOrderEvent(tx, trade.MakerOrderID, trade.Height, status)
+}
+
+// Records a change of the status of an order and updates the order
+func (ix *Indexer) addOrderEvent(tx *sql.Tx, orderID int64, height int64, status string) error {
+	_, err := tx.Exec("INSERT INTO order_events (order_id, height, status) VALUES (?, ?, ?)", orderID, height, status)
+	if err != nil {
+		return err
+	}
+	return refreshOrder(tx, orderID)
+}
+
+// Removes everything written for the blocks above height and moves the cursor back to it
+func (ix *Indexer) Rollback(height int64) error {
+	tx, err := ix.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	orderIDs, err := queryInt64s(tx, `SELECT order_id FROM order_events WHERE height > ?
+		UNION SELECT maker_order_id FROM trades WHERE height > ?
+		UNION SELECT taker_order_id FROM trades WHERE height > ?`, height, height, height)
+	if err != nil {
+		return err
+	}
+	balances, err := queryBalanceKeys(tx, "SELECT DISTINCT address, denom FROM balance_changes WHERE height > ?", height)
+	if err != nil {
+		return err
+	}
+
+	for _, query := range []string{
+		"DELETE FROM orders WHERE created_height > ?",
+		"DELETE FROM order_events WHERE height > ?",
+		"DELETE FROM trades WHERE height > ?",
+		"DELETE FROM balance_changes WHERE height > ?",
+		"DELETE FROM blocks WHERE height > ?",
+	} {
+		_, err = tx.Exec(query, height)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, orderID := range orderIDs {
+		err = refreshOrder(tx, orderID)
+		if err != nil {
+			return err
+		}
+	}
+	for _, balance := range balances {
+		err = refreshBalance(tx, balance[0], balance[1])
+		if err != nil {
+			return err
+		}
+	}
+
+	var hashStr string
+	err = tx.QueryRow("SELECT hash FROM blocks WHERE height = ?", height).Scan(&hashStr)
+	switch {
+	case err == sql.ErrNoRows:
+		_, err = tx.Exec("DELETE FROM cursor")
+	case err == nil:
+		_, err = tx.Exec("INSERT OR REPLACE INTO cursor (id, height, hash) VALUES (1, ?, ?)", height, hashStr)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Recomputes the fills and the status of an order from its trades and events
+func refreshOrder(tx *sql.Tx, orderID int64) error {
+	filled, received, err := orderFills(tx, orderID)
+	if err != nil {
+		return err
+	}
+
+	var status string
+	var height int64
+	err = tx.QueryRow("SELECT status, height FROM order_events WHERE order_id = ? ORDER BY height DESC, rowid DESC LIMIT 1",
+		orderID).Scan(&status, &height)
+	if err == sql.ErrNoRows {
+		status = orderbook.OrderStatusOpen
+	} else if err != nil {
+		return err
+	}
+
+	closedHeight := int64(0)
+	if (orderbook.Order{Status: status}).IsClosed() {
+		closedHeight = height
+	}
+
+	_, err = tx.Exec("UPDATE orders SET filled_amount = ?, received_amount = ?, status = ?, closed_height = ? WHERE order_id = ?",
+		filled.String(), received.String(), status, closedHeight, orderID)
+	return err
+}
+
+// Returns the coins an order sold and received in its trades, as maker and as taker
+func orderFills(tx *sql.Tx, orderID int64) (filled sdk.Int, received sdk.Int, err error) {
+	filled, received = sdk.ZeroInt(), sdk.ZeroInt()
+	rows, err := tx.Query(`SELECT maker_sold_amount, taker_sold_amount, maker_fee_amount FROM trades WHERE maker_order_id = ?
+		UNION ALL SELECT taker_sold_amount, maker_sold_amount, taker_fee_amount FROM trades WHERE taker_order_id = ?`, orderID, orderID)
+	if err != nil {
+		return filled, received, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sold, bought, fee string
+		err = rows.Scan(&sold, &bought, &fee)
+		if err != nil {
+			return filled, received, err
+		}
+		filled = filled.Add(parseInt(sold))
+		received = received.Add(parseInt(bought).Sub(parseInt(fee)))
+	}
+	return filled, received, rows.Err()
+}
+
+// Recomputes the balance of an address in a denom from its balance changes
+func refreshBalance(tx *sql.Tx, address string, denom string) error {
+	amounts, err := queryStrings(tx, "SELECT amount FROM balance_changes WHERE address = ? AND denom = ?", address, denom)
+	if err != nil {
+		return err
+	}
+	if len(amounts) == 0 {
+		_, err = tx.Exec("DELETE FROM balances WHERE address = ? AND denom = ?", address, denom)
+		return err
+	}
+
+	balance := sdk.ZeroInt()
+	for _, amount := range amounts {
+		balance = balance.Add(parseInt(amount))
+	}
+	_, err = tx.Exec("INSERT OR REPLACE INTO balances (address, denom, amount) VALUES (?, ?, ?)", address, denom, balance.String())
+	return err
+}
+
+// Splits the concatenated JSON results of the msgs of a tx
+func splitResults(data []byte) (results []json.RawMessage, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var result json.RawMessage
+		err = decoder.Decode(&result)
+		if err == io.EOF {
+			return results, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+}
+
+func queryInt64s(tx *sql.Tx, query string, args ...interface{}) (values []int64, err error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var value int64
+		err = rows.Scan(&value)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+func queryStrings(tx *sql.Tx, query string, args ...interface{}) (values []string, err error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var value string
+		err = rows.Scan(&value)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+func queryBalanceKeys(tx *sql.Tx, query string, args ...interface{}) (keys [][2]string, err error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key [2]string
+		err = rows.Scan(&key[0], &key[1])
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// parses an amount written by the indexer
+func parseInt(str string) sdk.Int {
+	amount, ok := sdk.NewIntFromString(str)
+	if !ok {
+		panic(fmt.Sprintf("invalid amount %s in the index", str))
+	}
+	return amount
+}
+
+// parses a hash written by the indexer
+func hexBytes(str string) cmn.HexBytes {
+	hash, _ := hex.DecodeString(str)
+	return hash
+}
@@ -0,0 +1,209 @@
+package indexer
+
+import (
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	cmn "github.com/tendermint/tendermint/libs/common"
+	"github.com/tendermint/tendermint/libs/log"
+	tmtypes "github.com/tendermint/tendermint/types"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+
+	app "github.com/sunnya97/sdk-dex-mvp"
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+)
+
+var (
+	alice = sdk.AccAddress([]byte("alice_______________"))
+	bob   = sdk.AccAddress([]byte("bob_________________"))
+)
+
+// fakeSource serves blocks built by the test
+type fakeSource struct {
+	blocks []Block
+}
+
+func (s *fakeSource) LatestHeight() (int64, error) {
+	return int64(len(s.blocks)), nil
+}
+
+func (s *fakeSource) Block(height int64) (Block, error) {
+	return s.blocks[height-1], nil
+}
+
+// appends a block with txs, chained to the previous block
+func (s *fakeSource) addBlock(fork string, txs []tmtypes.Tx, results []*abci.ResponseDeliverTx) {
+	height := int64(len(s.blocks)) + 1
+	block := Block{
+		Height:     height,
+		Hash:       cmn.HexBytes(fmt.Sprintf("%s-%d", fork, height)),
+		Time:       time.Unix(height, 0).UTC(),
+		Txs:        txs,
+		DeliverTxs: results,
+	}
+	if height > 1 {
+		block.LastHash = s.blocks[height-2].Hash
+	}
+	s.blocks = append(s.blocks, block)
+}
+
+func makeOrderTx(t *testing.T, cdc *codec.Codec, owner sdk.AccAddress, sellCoins sdk.Coin, buyDenom string, price int64,
+	result orderbook.MakeOrderResult) (tmtypes.Tx, *abci.ResponseDeliverTx) {
+	msg := orderbook.NewMsgMakeOrder(owner, "", sellCoins, orderbook.NewPrice(sdk.NewDec(price), buyDenom, sellCoins.Denom),
+		time.Time{}, sdk.ZeroInt(), orderbook.STPNone)
+	txBytes, err := auth.DefaultTxEncoder(cdc)(auth.NewStdTx([]sdk.Msg{msg}, auth.StdFee{}, nil, ""))
+	require.NoError(t, err)
+	return txBytes, &abci.ResponseDeliverTx{Data: cdc.MustMarshalJSON(result)}
+}
+
+func removeOrderTx(t *testing.T, cdc *codec.Codec, owner sdk.AccAddress, orderID int64) (tmtypes.Tx, *abci.ResponseDeliverTx) {
+	msg := orderbook.NewMsgRemoveOrder(owner, orderID)
+	txBytes, err := auth.DefaultTxEncoder(cdc)(auth.NewStdTx([]sdk.Msg{msg}, auth.StdFee{}, nil, ""))
+	require.NoError(t, err)
+	tags := sdk.NewTags(orderbook.TagCancelledOrderID, []byte(fmt.Sprintf("%d", orderID)))
+	return txBytes, &abci.ResponseDeliverTx{Tags: tags}
+}
+
+func orderStatus(t *testing.T, db *sql.DB, orderID int64) (status string, filled string) {
+	err := db.QueryRow("SELECT status, filled_amount FROM orders WHERE order_id = ?", orderID).Scan(&status, &filled)
+	require.NoError(t, err)
+	return status, filled
+}
+
+func balance(t *testing.T, db *sql.DB, address sdk.AccAddress, denom string) string {
+	var amount string
+	err := db.QueryRow("SELECT amount FROM balances WHERE address = ? AND denom = ?", address.String(), denom).Scan(&amount)
+	if err == sql.ErrNoRows {
+		return "0"
+	}
+	require.NoError(t, err)
+	return amount
+}
+
+func TestIndexer(t *testing.T) {
+	cdc := app.MakeCodec()
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+
+	source := &fakeSource{}
+	ix, err := NewIndexer(db, source, cdc, 0, log.NewNopLogger())
+	require.NoError(t, err)
+
+	// bob rests 100 BTC at 2 ETH/BTC
+	tx, res := makeOrderTx(t, cdc, bob, sdk.NewInt64Coin("BTC", 100), "ETH", 2, orderbook.MakeOrderResult{
+		OrderID: 1, Status: orderbook.OrderStatusOpen,
+	})
+	source.addBlock("a", []tmtypes.Tx{tx}, []*abci.ResponseDeliverTx{res})
+
+	// alice takes half of it, paying a fee of 1 BTC
+	trade := orderbook.Trade{
+		TradeID: 1, MakerOrderID: 1, TakerOrderID: 2, Maker: bob, Taker: alice,
+		MakerSold: sdk.NewInt64Coin("BTC", 50), TakerSold: sdk.NewInt64Coin("ETH", 100),
+		MakerFee: sdk.NewInt64Coin("ETH", 0), TakerFee: sdk.NewInt64Coin("BTC", 1),
+		Price: orderbook.NewPrice(sdk.NewDec(2), "ETH", "BTC"), Height: 2,
+	}
+	tx, res = makeOrderTx(t, cdc, alice, sdk.NewInt64Coin("ETH", 100), "BTC", 1, orderbook.MakeOrderResult{
+		OrderID: 2, Status: orderbook.OrderStatusFilled, Consumed: true, Trades: []orderbook.Trade{trade},
+	})
+	source.addBlock("a", []tmtypes.Tx{tx}, []*abci.ResponseDeliverTx{res})
+
+	// bob removes the rest of his order
+	tx, res = removeOrderTx(t, cdc, bob, 1)
+	source.addBlock("a", []tmtypes.Tx{tx}, []*abci.ResponseDeliverTx{res})
+
+	indexed, err := ix.Sync()
+	require.NoError(t, err)
+	require.Equal(t, int64(3), indexed)
+
+	status, filled := orderStatus(t, db, 1)
+	require.Equal(t, orderbook.OrderStatusCancelled, status)
+	require.Equal(t, "50", filled)
+	status, filled = orderStatus(t, db, 2)
+	require.Equal(t, orderbook.OrderStatusFilled, status)
+	require.Equal(t, "100", filled)
+	require.Equal(t, "49", balance(t, db, alice, "BTC"))
+	require.Equal(t, "-100", balance(t, db, alice, "ETH"))
+	require.Equal(t, "100", balance(t, db, bob, "ETH"))
+
+	// the source switches to another fork after block 1, where bob's order is never taken
+	source.blocks = source.blocks[:1]
+	source.addBlock("b", nil, nil)
+	source.addBlock("b", nil, nil)
+	source.addBlock("b", nil, nil)
+
+	indexed, err = ix.Sync()
+	require.NoError(t, err)
+	require.Equal(t, int64(3), indexed)
+
+	height, hash, err := ix.Cursor()
+	require.NoError(t, err)
+	require.Equal(t, int64(4), height)
+	require.Equal(t, source.blocks[3].Hash, hash)
+
+	status, filled = orderStatus(t, db, 1)
+	require.Equal(t, orderbook.OrderStatusOpen, status)
+	require.Equal(t, "0", filled)
+	require.Equal(t, "0", balance(t, db, alice, "BTC"))
+
+	var trades int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM trades").Scan(&trades))
+	require.Equal(t, 0, trades)
+}
+
+func TestIndexerEndBlockTrades(t *testing.T) {
+	cdc := app.MakeCodec()
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+
+	source := &fakeSource{}
+	ix, err := NewIndexer(db, source, cdc, 0, log.NewNopLogger())
+	require.NoError(t, err)
+
+	tx, res := makeOrderTx(t, cdc, bob, sdk.NewInt64Coin("BTC", 100), "ETH", 2, orderbook.MakeOrderResult{
+		OrderID: 1, Status: orderbook.OrderStatusOpen,
+	})
+	source.addBlock("a", []tmtypes.Tx{tx}, []*abci.ResponseDeliverTx{res})
+
+	// a slice of alice's TWAPOrder takes all of bob's order in the EndBlocker
+	trade := orderbook.Trade{
+		TradeID: 1, MakerOrderID: 1, TakerOrderID: 2, Maker: bob, Taker: alice,
+		MakerSold: sdk.NewInt64Coin("BTC", 100), TakerSold: sdk.NewInt64Coin("ETH", 200),
+		MakerFee: sdk.NewInt64Coin("ETH", 0), TakerFee: sdk.NewInt64Coin("BTC", 0),
+		Price: orderbook.NewPrice(sdk.NewDec(2), "ETH", "BTC"), Height: 2,
+	}
+	source.addBlock("a", nil, nil)
+	source.blocks[1].EndBlockTags = sdk.NewTags(
+		orderbook.TagTradeID, []byte("1"),
+		orderbook.TagTrade, cdc.MustMarshalJSON(trade),
+	)
+
+	indexed, err := ix.Sync()
+	require.NoError(t, err)
+	require.Equal(t, int64(2), indexed)
+
+	status, filled := orderStatus(t, db, 1)
+	require.Equal(t, orderbook.OrderStatusFilled, status)
+	require.Equal(t, "100", filled)
+	require.Equal(t, "100", balance(t, db, alice, "BTC"))
+	require.Equal(t, "200", balance(t, db, bob, "ETH"))
+
+	// a trade that can't be read stops the indexer before the cursor moves past it
+	source.addBlock("a", nil, nil)
+	source.blocks[2].EndBlockTags = sdk.NewTags(orderbook.TagTrade, []byte("{"))
+	_, err = ix.Sync()
+	require.Error(t, err)
+	height, _, err := ix.Cursor()
+	require.NoError(t, err)
+	require.Equal(t, int64(2), height)
+}
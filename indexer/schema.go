@@ -0,0 +1,76 @@
+package indexer
+
+// Amounts are stored as decimal strings, as they don't fit in SQLite integers.
+// Every row is tagged with the height it was written at, so that the blocks above a height can be rolled back
+const schema = `
+CREATE TABLE IF NOT EXISTS cursor (
+	id     INTEGER PRIMARY KEY CHECK (id = 1),
+	height INTEGER NOT NULL,
+	hash   TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS blocks (
+	height INTEGER PRIMARY KEY,
+	hash   TEXT NOT NULL,
+	time   TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS orders (
+	order_id        INTEGER PRIMARY KEY,
+	client_order_id TEXT NOT NULL,
+	owner           TEXT NOT NULL,
+	sell_denom      TEXT NOT NULL,
+	buy_denom       TEXT NOT NULL,
+	price           TEXT NOT NULL,
+	original_amount TEXT NOT NULL,
+	filled_amount   TEXT NOT NULL,
+	received_amount TEXT NOT NULL,
+	status          TEXT NOT NULL,
+	created_height  INTEGER NOT NULL,
+	closed_height   INTEGER NOT NULL,
+	tx_hash         TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS orders_owner ON orders (owner);
+
+CREATE TABLE IF NOT EXISTS order_events (
+	order_id INTEGER NOT NULL,
+	height   INTEGER NOT NULL,
+	status   TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS order_events_order ON order_events (order_id, height);
+
+CREATE TABLE IF NOT EXISTS trades (
+	trade_id          INTEGER PRIMARY KEY,
+	market            TEXT NOT NULL,
+	maker_order_id    INTEGER NOT NULL,
+	taker_order_id    INTEGER NOT NULL,
+	maker             TEXT NOT NULL,
+	taker             TEXT NOT NULL,
+	maker_sold_amount TEXT NOT NULL,
+	maker_sold_denom  TEXT NOT NULL,
+	taker_sold_amount TEXT NOT NULL,
+	taker_sold_denom  TEXT NOT NULL,
+	maker_fee_amount  TEXT NOT NULL,
+	taker_fee_amount  TEXT NOT NULL,
+	price             TEXT NOT NULL,
+	height            INTEGER NOT NULL,
+	time              TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS trades_market ON trades (market, height);
+
+CREATE TABLE IF NOT EXISTS balance_changes (
+	height   INTEGER NOT NULL,
+	trade_id INTEGER NOT NULL,
+	address  TEXT NOT NULL,
+	denom    TEXT NOT NULL,
+	amount   TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS balance_changes_height ON balance_changes (height);
+
+CREATE TABLE IF NOT EXISTS balances (
+	address TEXT NOT NULL,
+	denom   TEXT NOT NULL,
+	amount  TEXT NOT NULL,
+	PRIMARY KEY (address, denom)
+);
+`
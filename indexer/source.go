@@ -0,0 +1,123 @@
+package indexer
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/blockchain"
+	cmn "github.com/tendermint/tendermint/libs/common"
+	dbm "github.com/tendermint/tendermint/libs/db"
+	"github.com/tendermint/tendermint/state"
+	tmtypes "github.com/tendermint/tendermint/types"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+)
+
+// Block is a block along with the results of executing it
+type Block struct {
+	Height       int64
+	Hash         cmn.HexBytes
+	LastHash     cmn.HexBytes
+	Time         time.Time
+	Txs          tmtypes.Txs
+	DeliverTxs   []*abci.ResponseDeliverTx
+	EndBlockTags []cmn.KVPair
+}
+
+// Source is where the indexer reads blocks from
+type Source interface {
+	// height of the last block whose results are available
+	LatestHeight() (int64, error)
+	Block(height int64) (Block, error)
+}
+
+// NodeSource reads blocks from the RPC of a running node
+type NodeSource struct {
+	cliCtx context.CLIContext
+}
+
+var _ Source = NodeSource{}
+
+func NewNodeSource(cliCtx context.CLIContext) NodeSource {
+	return NodeSource{
+		cliCtx: cliCtx,
+	}
+}
+
+func (s NodeSource) LatestHeight() (int64, error) {
+	node, err := s.cliCtx.GetNode()
+	if err != nil {
+		return 0, err
+	}
+	status, err := node.Status()
+	if err != nil {
+		return 0, err
+	}
+	return status.SyncInfo.LatestBlockHeight, nil
+}
+
+func (s NodeSource) Block(height int64) (block Block, err error) {
+	node, err := s.cliCtx.GetNode()
+	if err != nil {
+		return block, err
+	}
+	resBlock, err := node.Block(&height)
+	if err != nil {
+		return block, err
+	}
+	results, err := node.BlockResults(&height)
+	if err != nil {
+		return block, err
+	}
+	return newBlock(resBlock.Block, resBlock.BlockMeta.BlockID.Hash, results.Results), nil
+}
+
+// BlockStoreSource replays the block store and the saved block results of a node's home, while the node is stopped
+type BlockStoreSource struct {
+	blockStore *blockchain.BlockStore
+	stateDB    dbm.DB
+}
+
+var _ Source = BlockStoreSource{}
+
+func NewBlockStoreSource(nodeHome string) BlockStoreSource {
+	dataDir := filepath.Join(nodeHome, "data")
+	return BlockStoreSource{
+		blockStore: blockchain.NewBlockStore(dbm.NewDB("blockstore", dbm.LevelDBBackend, dataDir)),
+		stateDB:    dbm.NewDB("state", dbm.LevelDBBackend, dataDir),
+	}
+}
+
+func (s BlockStoreSource) LatestHeight() (int64, error) {
+	return s.blockStore.Height(), nil
+}
+
+func (s BlockStoreSource) Block(height int64) (block Block, err error) {
+	tmBlock := s.blockStore.LoadBlock(height)
+	meta := s.blockStore.LoadBlockMeta(height)
+	if tmBlock == nil || meta == nil {
+		return block, fmt.Errorf("block %d is not in the block store", height)
+	}
+	results, err := state.LoadABCIResponses(s.stateDB, height)
+	if err != nil {
+		return block, err
+	}
+	return newBlock(tmBlock, meta.BlockID.Hash, results), nil
+}
+
+func newBlock(tmBlock *tmtypes.Block, hash cmn.HexBytes, results *state.ABCIResponses) Block {
+	block := Block{
+		Height:     tmBlock.Height,
+		Hash:       hash,
+		LastHash:   tmBlock.LastBlockID.Hash,
+		Time:       tmBlock.Time,
+		Txs:        tmBlock.Data.Txs,
+		DeliverTxs: results.DeliverTx,
+	}
+	if results.EndBlock != nil {
+		block.EndBlockTags = results.EndBlock.Tags
+	}
+	return block
+}
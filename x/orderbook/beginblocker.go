@@ -0,0 +1,28 @@
+package orderbook
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// BeginBlocker is called at the beginning of every block.  At the upgrade height it migrates the orderbook store
+// to the schema version of this code, before anything else reads it.
+// A node running this code on a store it can't read yet stops, as it would diverge from the other nodes
+func BeginBlocker(ctx sdk.Context, keeper Keeper) sdk.Tags {
+	version := keeper.GetSchemaVersion(ctx)
+	if version == SchemaVersion() {
+		return nil
+	}
+
+	if keeper.upgradeHeight == 0 || ctx.BlockHeight() < keeper.upgradeHeight {
+		panic(fmt.Sprintf("orderbook store is at schema version %d, which this node can only migrate to version %d at upgrade height %d",
+			version, SchemaVersion(), keeper.upgradeHeight))
+	}
+
+	tags, err := keeper.MigrateStore(ctx)
+	if err != nil {
+		panic(err)
+	}
+	return tags
+}
@@ -0,0 +1,209 @@
+package orderbook
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const benchmarkWallSize = 10000
+
+// places n resting orders selling 10 BTC at 2 ETH/BTC
+func fillOrderwall(b *testing.B, ctx sdk.Context, keeper Keeper, n int) {
+	params := keeper.GetParams(ctx)
+	params.MaxOrdersPerAccount = int64(n)
+	keeper.SetParams(ctx, params)
+
+	ratio, _ := sdk.NewDecFromStr("2")
+	for i := 0; i < n; i++ {
+		order := Order{
+			OrderID:     keeper.GetNextOrderID(ctx),
+			Owner:       bob,
+			SellCoins:   sdk.NewInt64Coin("BTC", 10),
+			BuyDenom:    "ETH",
+			Price:       NewPrice(ratio, "ETH", "BTC"),
+			HiddenCoins: sdk.NewInt64Coin("BTC", 0),
+		}
+		_, _, err := keeper.AddNewOrder(ctx, order)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// an incoming order that crosses the whole orderwall made by fillOrderwall
+func sweepingOrder(ctx sdk.Context, keeper Keeper) Order {
+	ratio, _ := sdk.NewDecFromStr("0.5")
+	return Order{
+		OrderID:     keeper.GetNextOrderID(ctx),
+		Owner:       alice,
+		SellCoins:   sdk.NewInt64Coin("ETH", 20*benchmarkWallSize),
+		BuyDenom:    "BTC",
+		Price:       NewPrice(ratio, "BTC", "ETH"),
+		HiddenCoins: sdk.NewInt64Coin("ETH", 0),
+	}
+}
+
+// Sweeps the orderwall with ExecuteOrderAgainstOrderWall, which walks the wall in batches with a single iterator each
+func BenchmarkSweepOrderwall(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		ctx, keeper := createTestInput(b)
+		setMaxFills(ctx, keeper, benchmarkWallSize)
+		fillOrderwall(b, ctx, keeper, benchmarkWallSize)
+		order := sweepingOrder(ctx, keeper)
+		b.StartTimer()
+
+		_, consumed := keeper.ExecuteOrderAgainstOrderWall(ctx, order)
+		if !consumed {
+			b.Fatal("sweeping order was not consumed")
+		}
+	}
+}
+
+// Matches an order against the opposing orderwall the way matching did before the walk in batches:
+// every fill peeks at the top of the orderwall with an iterator of its own
+func executeOrderPeekPerFill(ctx sdk.Context, keeper Keeper, order Order) (consumed bool) {
+	opposingPair := order.Pair().ReversePair()
+	for order.SellCoins.IsPositive() {
+		orderWall := keeper.OrderWallIterator(ctx, opposingPair)
+		if !orderWall.Valid() {
+			orderWall.Close()
+			return false
+		}
+		var orderID int64
+		keeper.cdc.MustUnmarshalBinaryBare(orderWall.Value(), &orderID)
+		orderWall.Close()
+		peekWallOrder, _ := keeper.GetOrder(ctx, orderID)
+
+		askPrice := peekWallOrder.Price.Reciprocal()
+		if order.Price.GT(askPrice) {
+			return false
+		}
+
+		bidAtAskingPrice, _ := MulCoinsPrice(order.SellCoins, askPrice)
+		if bidAtAskingPrice.IsGTE(peekWallOrder.SellCoins) {
+			executeAmount, _ := MulCoinsPrice(peekWallOrder.SellCoins, askPrice.Reciprocal())
+			keeper.coinKeeper.AddCoins(ctx, peekWallOrder.Owner, sdk.Coins{executeAmount})
+			order.SellCoins = order.SellCoins.Minus(executeAmount)
+			keeper.coinKeeper.AddCoins(ctx, order.Owner, sdk.Coins{peekWallOrder.SellCoins})
+			keeper.RemoveOrder(ctx, peekWallOrder.OrderID)
+		} else {
+			executeAmount, _ := MulCoinsPrice(order.SellCoins, askPrice)
+			keeper.coinKeeper.AddCoins(ctx, order.Owner, sdk.Coins{executeAmount})
+			peekWallOrder.SellCoins = peekWallOrder.SellCoins.Minus(executeAmount)
+			keeper.SetOrder(ctx, peekWallOrder)
+			keeper.coinKeeper.AddCoins(ctx, peekWallOrder.Owner, sdk.Coins{order.SellCoins})
+			return true
+		}
+	}
+	return true
+}
+
+// Sweeps the orderwall the way matching used to, as a baseline for BenchmarkSweepOrderwall
+func BenchmarkSweepOrderwallPeekPerFill(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		ctx, keeper := createTestInput(b)
+		fillOrderwall(b, ctx, keeper, benchmarkWallSize)
+		order := sweepingOrder(ctx, keeper)
+		b.StartTimer()
+
+		if !executeOrderPeekPerFill(ctx, keeper, order) {
+			b.Fatal("sweeping order was not consumed")
+		}
+	}
+}
+
+// an incoming order that doesn't cross the orderwall made by fillOrderwall
+func restingOrder(ctx sdk.Context, keeper Keeper) Order {
+	order := sweepingOrder(ctx, keeper)
+	order.SellCoins = sdk.NewInt64Coin("ETH", 20)
+	order.Price.Ratio, _ = sdk.NewDecFromStr("0.6")
+	return order
+}
+
+// Places an order that doesn't cross the orderwall, which only has to read the top of the orderwall
+func BenchmarkNonCrossingOrder(b *testing.B) {
+	ctx, keeper := createTestInput(b)
+	fillOrderwall(b, ctx, keeper, benchmarkWallSize)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, consumed := keeper.ExecuteOrderAgainstOrderWall(ctx, restingOrder(ctx, keeper)); consumed {
+			b.Fatal("resting order was consumed")
+		}
+	}
+}
+
+// Places an order that doesn't cross the orderwall the way matching used to, as a baseline for BenchmarkNonCrossingOrder
+func BenchmarkNonCrossingOrderPeekPerFill(b *testing.B) {
+	ctx, keeper := createTestInput(b)
+	fillOrderwall(b, ctx, keeper, benchmarkWallSize)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if executeOrderPeekPerFill(ctx, keeper, restingOrder(ctx, keeper)) {
+			b.Fatal("resting order was consumed")
+		}
+	}
+}
+
+// Measures only the walk of the orderwall, without matching
+func BenchmarkPeekOrderwallOrders(b *testing.B) {
+	ctx, keeper := createTestInput(b)
+	fillOrderwall(b, ctx, keeper, benchmarkWallSize)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		orders := keeper.PeekOrderwallOrders(ctx, NewDenomPair("BTC", "ETH"), benchmarkWallSize)
+		if len(orders) != benchmarkWallSize {
+			b.Fatalf("peeked %d orders, want %d", len(orders), benchmarkWallSize)
+		}
+	}
+}
+
+// Sweeps the orderwall with a Keeper that caches the top of the orderwall
+func BenchmarkSweepOrderwallCached(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		ctx, keeper := createTestInput(b)
+		keeper = keeper.WithOrderbookCache(DefaultCacheDepth)
+		setMaxFills(ctx, keeper, benchmarkWallSize)
+		fillOrderwall(b, ctx, keeper, benchmarkWallSize)
+		order := sweepingOrder(ctx, keeper)
+		b.StartTimer()
+
+		_, consumed := keeper.ExecuteOrderAgainstOrderWall(ctx, order)
+		if !consumed {
+			b.Fatal("sweeping order was not consumed")
+		}
+	}
+}
+
+// Measures peeking at the top of the orderwall, which every incoming order does before matching
+func BenchmarkPeekOrderwallOrder(b *testing.B) {
+	ctx, keeper := createTestInput(b)
+	fillOrderwall(b, ctx, keeper, benchmarkWallSize)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, found := keeper.PeekOrderwallOrder(ctx, NewDenomPair("BTC", "ETH")); !found {
+			b.Fatal("orderwall is empty")
+		}
+	}
+}
+
+// Measures peeking at the top of the orderwall from the cache
+func BenchmarkPeekOrderwallOrderCached(b *testing.B) {
+	ctx, keeper := createTestInput(b)
+	keeper = keeper.WithOrderbookCache(DefaultCacheDepth)
+	fillOrderwall(b, ctx, keeper, benchmarkWallSize)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, found := keeper.PeekOrderwallOrder(ctx, NewDenomPair("BTC", "ETH")); !found {
+			b.Fatal("orderwall is empty")
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package orderbook
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+var orderwallVersionsPrefix = KeyFromComponents([]byte("orderwallVersions"))
+
+// number of orders at the top of every orderwall kept in the cache
+const DefaultCacheDepth = 64
+
+// orderbookCache keeps the top of every orderwall decoded in memory.
+// Every write to an orderwall bumps a version for its DenomPair in the store, and a cached orderwall is only used
+// while its version matches the one in the store of the context.  The writes of a failed transaction are discarded
+// along with its cache-wrapped store, so the versions no longer match and the orderwall is rebuilt from the store.
+// The cache is read and maintained through the store without gas metering, and orderwalls are peeked without gas
+// metering whether they're read from the cache or the store, at a fixed GasPerPeekedOrder for every order peeked.
+// That way the gas used by a transaction doesn't depend on what happens to be in the cache of a node
+type orderbookCache struct {
+	mtx   sync.Mutex
+	depth int
+	// CheckTx and DeliverTx states get separate cached orderwalls so they don't keep invalidating each other.
+	// The CheckTx ones are dropped on every Commit by ResetCheckTxOrderbookCache
+	walls map[bool]map[string]*cachedOrderwall
+}
+
+// the top orders of one orderwall, sorted by their key in the orderwall
+type cachedOrderwall struct {
+	version  int64
+	complete bool // whether orders is the entire orderwall
+	keys     [][]byte
+	orders   []Order
+}
+
+func newOrderbookCache(depth int) *orderbookCache {
+	return &orderbookCache{
+		depth: depth,
+		walls: map[bool]map[string]*cachedOrderwall{
+			true:  make(map[string]*cachedOrderwall),
+			false: make(map[string]*cachedOrderwall),
+		},
+	}
+}
+
+// Returns a copy of the Keeper that caches the top depth orders of every orderwall in memory
+func (k Keeper) WithOrderbookCache(depth int) Keeper {
+	k.cache = newOrderbookCache(depth)
+	return k
+}
+
+// get key in store to get the version of an orderwall
+func OrderwallVersionKey(pair DenomPair) []byte {
+	return AppendKeyComponents(orderwallVersionsPrefix, pairComponents(pair)...)
+}
+
+// the store of the context without gas metering, used for keeping the cache in sync with the store
+func (k Keeper) unmeteredStore(ctx sdk.Context) sdk.KVStore {
+	return ctx.MultiStore().GetKVStore(k.storeKey)
+}
+
+// Gets the version of an orderwall, which changes every time the orderwall or one of its orders is written
+func (k Keeper) GetOrderwallVersion(ctx sdk.Context, pair DenomPair) (version int64) {
+	bz := k.unmeteredStore(ctx).Get(OrderwallVersionKey(pair))
+	if bz == nil {
+		return 0
+	}
+	k.cdc.MustUnmarshalBinaryBare(bz, &version)
+	return version
+}
+
+// Bumps the version of an orderwall after a write and applies the write to its cached copy.
+// The cached copy is dropped instead if it wasn't made from the version before the write
+func (k Keeper) updateCachedOrderwall(ctx sdk.Context, pair DenomPair, apply func(wall *cachedOrderwall)) {
+	prevVersion := k.GetOrderwallVersion(ctx, pair)
+	version := prevVersion + 1
+	k.unmeteredStore(ctx).Set(OrderwallVersionKey(pair), k.cdc.MustMarshalBinaryBare(version))
+
+	if k.cache == nil {
+		return
+	}
+	k.cache.mtx.Lock()
+	defer k.cache.mtx.Unlock()
+
+	walls := k.cache.walls[ctx.IsCheckTx()]
+	wall, ok := walls[pair.String()]
+	if !ok {
+		return
+	}
+	if wall.version != prevVersion {
+		delete(walls, pair.String())
+		return
+	}
+
+	apply(wall)
+	wall.version = version
+
+	// an incomplete cached orderwall that ran out of orders has to be reloaded from the store
+	if !wall.complete && len(wall.orders) == 0 {
+		delete(walls, pair.String())
+	}
+}
+
+// Peeks at up to limit of the lowest orders of an orderwall from the cache, for as long as while returns true for them,
+// loading the orderwall from the store if needed.
+// Returns false if the cache doesn't hold enough of the orderwall
+func (k Keeper) peekCachedOrderwall(ctx sdk.Context, pair DenomPair, limit int64, while func(order Order) bool) (orders []Order, ok bool) {
+	k.cache.mtx.Lock()
+	defer k.cache.mtx.Unlock()
+
+	version := k.GetOrderwallVersion(ctx, pair)
+	walls := k.cache.walls[ctx.IsCheckTx()]
+	wall, found := walls[pair.String()]
+	if !found || wall.version != version {
+		wall = k.loadCachedOrderwall(ctx, pair, version)
+		walls[pair.String()] = wall
+	}
+
+	n, peeked := int64(0), int64(0)
+	for n < limit {
+		if n == int64(len(wall.orders)) {
+			if !wall.complete {
+				return nil, false
+			}
+			break
+		}
+		peeked++
+		if !while(wall.orders[n]) {
+			break
+		}
+		n++
+	}
+
+	ctx.GasMeter().ConsumeGas(GasPerPeekedOrder*sdk.Gas(peeked), "orderbook peek")
+	orders = make([]Order, n)
+	copy(orders, wall.orders[:n])
+	return orders, true
+}
+
+// Reads the top of an orderwall from the store into a new cached orderwall
+func (k Keeper) loadCachedOrderwall(ctx sdk.Context, pair DenomPair, version int64) *cachedOrderwall {
+	store := k.unmeteredStore(ctx)
+	wall := &cachedOrderwall{
+		version:  version,
+		complete: true,
+	}
+
+	iterator := store.Iterator(OrderwallPrefix(pair), sdk.PrefixEndBytes(OrderwallPrefix(pair)))
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		if len(wall.orders) == k.cache.depth {
+			wall.complete = false
+			break
+		}
+
+		var orderID int64
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &orderID)
+
+		bz := store.Get(OrderKey(orderID))
+		if bz == nil {
+			continue
+		}
+		var order Order
+		k.cdc.MustUnmarshalBinaryBare(bz, &order)
+
+		wall.keys = append(wall.keys, append([]byte{}, iterator.Key()...))
+		wall.orders = append(wall.orders, order)
+	}
+
+	return wall
+}
+
+// Loads the top of every orderwall in the store into the cache, for both the CheckTx and DeliverTx states.
+// Meant to be called once the latest version of the store has been loaded.
+// A store that hasn't been migrated to the schema version of this code yet is left to be cached once it is
+func (k Keeper) RebuildOrderbookCache(ctx sdk.Context) {
+	if k.cache == nil || k.GetSchemaVersion(ctx) != SchemaVersion() {
+		return
+	}
+
+	// find all pairs that have an orderwall
+	store := k.unmeteredStore(ctx)
+	pairs := make(map[string]DenomPair)
+	var pairStrs []string
+	iterator := store.Iterator(orderwallPrefix, sdk.PrefixEndBytes(orderwallPrefix))
+	for ; iterator.Valid(); iterator.Next() {
+		var orderID int64
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &orderID)
+
+		order, found := k.GetOrder(ctx, orderID)
+		if !found {
+			continue
+		}
+		if _, ok := pairs[order.Pair().String()]; !ok {
+			pairs[order.Pair().String()] = order.Pair()
+			pairStrs = append(pairStrs, order.Pair().String())
+		}
+	}
+	iterator.Close()
+
+	k.cache.mtx.Lock()
+	defer k.cache.mtx.Unlock()
+
+	for _, isCheckTx := range []bool{true, false} {
+		walls := make(map[string]*cachedOrderwall)
+		for _, pairStr := range pairStrs {
+			pair := pairs[pairStr]
+			walls[pairStr] = k.loadCachedOrderwall(ctx, pair, k.GetOrderwallVersion(ctx, pair))
+		}
+		k.cache.walls[isCheckTx] = walls
+	}
+}
+
+// Drops the orderwalls cached for the CheckTx state.  Meant to be called on Commit, when the CheckTx state is reset
+// to the committed state: the versions of its orderwalls can match those of the orderwalls written by CheckTx
+// since the last Commit, even though the orders committed in the block differ from those of the mempool
+func (k Keeper) ResetCheckTxOrderbookCache() {
+	if k.cache == nil {
+		return
+	}
+	k.cache.mtx.Lock()
+	defer k.cache.mtx.Unlock()
+
+	k.cache.walls[true] = make(map[string]*cachedOrderwall)
+}
+
+// Drops every cached orderwall, so they're loaded from the store again when next peeked at
+func (k Keeper) dropOrderbookCache() {
+	if k.cache == nil {
+		return
+	}
+	k.cache.mtx.Lock()
+	defer k.cache.mtx.Unlock()
+
+	for isCheckTx := range k.cache.walls {
+		k.cache.walls[isCheckTx] = make(map[string]*cachedOrderwall)
+	}
+}
+
+// Inserts an order at its place in the cached orderwall, if that place is within the cached part of the orderwall
+func (wall *cachedOrderwall) insert(key []byte, order Order, depth int) {
+	i := sort.Search(len(wall.keys), func(i int) bool { return bytes.Compare(wall.keys[i], key) >= 0 })
+	if i == len(wall.keys) && !wall.complete {
+		return
+	}
+
+	wall.keys = append(wall.keys, nil)
+	copy(wall.keys[i+1:], wall.keys[i:])
+	wall.keys[i] = key
+
+	wall.orders = append(wall.orders, Order{})
+	copy(wall.orders[i+1:], wall.orders[i:])
+	wall.orders[i] = order
+
+	if len(wall.orders) > depth {
+		wall.keys = wall.keys[:depth]
+		wall.orders = wall.orders[:depth]
+		wall.complete = false
+	}
+}
+
+// Removes the order with a key from the cached orderwall
+func (wall *cachedOrderwall) remove(key []byte) {
+	for i := range wall.keys {
+		if bytes.Equal(wall.keys[i], key) {
+			wall.keys = append(wall.keys[:i], wall.keys[i+1:]...)
+			wall.orders = append(wall.orders[:i], wall.orders[i+1:]...)
+			return
+		}
+	}
+}
+
+// Updates an order in the cached orderwall
+func (wall *cachedOrderwall) update(order Order) {
+	for i := range wall.orders {
+		if wall.orders[i].OrderID == order.OrderID {
+			wall.orders[i] = order
+			return
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package orderbook
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// small enough for random tests to keep running past the cached part of the orderwalls
+const testCacheDepth = 4
+
+var testPairs = []DenomPair{NewDenomPair("BTC", "ETH"), NewDenomPair("ETH", "BTC")}
+
+// a random MsgMakeOrder or MsgRemoveOrder
+func randomOrderbookMsg(r *rand.Rand, lastOrderID int64) sdk.Msg {
+	owner := []sdk.AccAddress{alice, bob}[r.Intn(2)]
+	if lastOrderID > 0 && r.Intn(4) == 0 {
+		return NewMsgRemoveOrder(owner, 1+r.Int63n(lastOrderID))
+	}
+
+	amount := 1 + r.Int63n(20)
+	priceStr := fmt.Sprintf("%d.%d", 1+r.Intn(2), r.Intn(10))
+	if r.Intn(2) == 0 {
+		return makeOrderMsg(owner, amount, "BTC", priceStr, "ETH", STPNone)
+	}
+	return makeOrderMsg(owner, amount, "ETH", fmt.Sprintf("0.%d", 4+r.Intn(5)), "BTC", STPNone)
+}
+
+// requires the orderwalls, as peeked, and the whole orderbook store to be the same for both keepers
+func requireSameOrderbook(t *testing.T, ctx1 sdk.Context, keeper1 Keeper, ctx2 sdk.Context, keeper2 Keeper) {
+	for _, pair := range testPairs {
+		for _, limit := range []int64{1, testCacheDepth, 1000} {
+			orders1 := keeper1.PeekOrderwallOrders(ctx1, pair, limit)
+			orders2 := keeper2.PeekOrderwallOrders(ctx2, pair, limit)
+			require.Equal(t, keeper1.cdc.MustMarshalBinaryBare(orders1), keeper2.cdc.MustMarshalBinaryBare(orders2),
+				"orderwall %s differs peeking %d orders", pair, limit)
+		}
+	}
+
+	iter1 := ctx1.KVStore(keeper1.storeKey).Iterator(nil, nil)
+	iter2 := ctx2.KVStore(keeper2.storeKey).Iterator(nil, nil)
+	defer iter1.Close()
+	defer iter2.Close()
+	for ; iter1.Valid(); iter1.Next() {
+		require.True(t, iter2.Valid())
+		require.Equal(t, iter1.Key(), iter2.Key())
+		require.Equal(t, iter1.Value(), iter2.Value())
+		iter2.Next()
+	}
+	require.False(t, iter2.Valid())
+}
+
+// Runs the same random messages through a cached and an uncached keeper and compares them after every message
+func TestOrderbookCacheDifferential(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	cachedCtx, cachedKeeper := createTestInput(t)
+	cachedKeeper = cachedKeeper.WithOrderbookCache(testCacheDepth)
+
+	handler := NewHandler(keeper)
+	cachedHandler := NewHandler(cachedKeeper)
+
+	r := rand.New(rand.NewSource(42))
+	for i := 0; i < 500; i++ {
+		msg := randomOrderbookMsg(r, keeper.GetLastOrderID(ctx))
+
+		res := handler(ctx, msg)
+		cachedRes := cachedHandler(cachedCtx, msg)
+		require.Equal(t, res.Code, cachedRes.Code, "message %d", i)
+		require.Equal(t, res.Data, cachedRes.Data, "message %d", i)
+
+		requireSameOrderbook(t, ctx, keeper, cachedCtx, cachedKeeper)
+	}
+}
+
+// Messages run in a cache-wrapped context that is never written, like a failed transaction,
+// must not leave anything behind in the cache
+func TestOrderbookCacheDiscardsFailedTx(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	cachedCtx, cachedKeeper := createTestInput(t)
+	cachedKeeper = cachedKeeper.WithOrderbookCache(testCacheDepth)
+
+	handler := NewHandler(keeper)
+	cachedHandler := NewHandler(cachedKeeper)
+
+	r := rand.New(rand.NewSource(7))
+	for i := 0; i < 200; i++ {
+		msg := randomOrderbookMsg(r, keeper.GetLastOrderID(ctx))
+
+		// every other message is run in a transaction that fails after the message
+		if i%2 == 1 {
+			txCtx, _ := cachedCtx.CacheContext()
+			cachedHandler(txCtx, msg)
+			for _, pair := range testPairs {
+				cachedKeeper.PeekOrderwallOrders(txCtx, pair, 1000)
+			}
+			requireSameOrderbook(t, ctx, keeper, cachedCtx, cachedKeeper)
+			continue
+		}
+
+		res := handler(ctx, msg)
+		cachedRes := cachedHandler(cachedCtx, msg)
+		require.Equal(t, res.Data, cachedRes.Data, "message %d", i)
+		requireSameOrderbook(t, ctx, keeper, cachedCtx, cachedKeeper)
+	}
+}
+
+// The cache rebuilt from the store on startup serves the same orderwalls as the store
+func TestRebuildOrderbookCache(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	handler := NewHandler(keeper)
+
+	r := rand.New(rand.NewSource(3))
+	for i := 0; i < 100; i++ {
+		handler(ctx, randomOrderbookMsg(r, keeper.GetLastOrderID(ctx)))
+	}
+
+	cachedKeeper := keeper.WithOrderbookCache(testCacheDepth)
+	cachedKeeper.RebuildOrderbookCache(ctx)
+	for _, pair := range testPairs {
+		if len(keeper.PeekOrderwallOrders(ctx, pair, 1)) == 0 {
+			continue
+		}
+		wall, found := cachedKeeper.cache.walls[false][pair.String()]
+		require.True(t, found)
+		require.Equal(t, keeper.GetOrderwallVersion(ctx, pair), wall.version)
+	}
+
+	requireSameOrderbook(t, ctx, keeper, ctx, cachedKeeper)
+}
+
+// Peeking costs the same gas with a cache that holds less of an orderwall than the peek needs, as on a node that
+// kept running while orders were removed, and with one that holds all of it, as on a node that was restarted
+func TestOrderbookCacheGasDoesNotDependOnCache(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	pair := NewDenomPair("BTC", "ETH")
+	handler := NewHandler(keeper)
+	for i := 0; i < DefaultCacheDepth+20; i++ {
+		res := handler(ctx, makeOrderMsg(bob, 10, "BTC", "2", "ETH", STPNone))
+		require.True(t, res.IsOK(), res.Log)
+	}
+
+	warmKeeper := keeper.WithOrderbookCache(DefaultCacheDepth)
+	warmKeeper.PeekOrderwallOrders(ctx, pair, 1)
+	warmHandler := NewHandler(warmKeeper)
+	for orderID := int64(1); orderID <= 10; orderID++ {
+		res := warmHandler(ctx, NewMsgRemoveOrder(bob, orderID))
+		require.True(t, res.IsOK(), res.Log)
+	}
+	require.Len(t, warmKeeper.cache.walls[false][pair.String()].orders, DefaultCacheDepth-10)
+
+	coldKeeper := keeper.WithOrderbookCache(DefaultCacheDepth)
+	coldKeeper.RebuildOrderbookCache(ctx)
+	require.Len(t, coldKeeper.cache.walls[false][pair.String()].orders, DefaultCacheDepth)
+
+	var gasUsed []sdk.Gas
+	for _, k := range []Keeper{keeper, warmKeeper, coldKeeper} {
+		peekCtx := ctx.WithGasMeter(sdk.NewInfiniteGasMeter())
+		orders := k.PeekOrderwallOrders(peekCtx, pair, DefaultCacheDepth-4)
+		require.Len(t, orders, DefaultCacheDepth-4)
+		gasUsed = append(gasUsed, peekCtx.GasMeter().GasConsumed())
+	}
+	require.Equal(t, gasUsed[0], gasUsed[1])
+	require.Equal(t, gasUsed[0], gasUsed[2])
+}
+
+// Orders placed in CheckTx bump the version of an orderwall just like the different orders committed in the block,
+// so the orderwalls cached for CheckTx are only right again after being reset on Commit
+func TestResetCheckTxOrderbookCache(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	keeper = keeper.WithOrderbookCache(testCacheDepth)
+	handler := NewHandler(keeper)
+	pair := NewDenomPair("BTC", "ETH")
+
+	checkCtx := sdk.NewContext(ctx.MultiStore().CacheMultiStore(), ctx.BlockHeader(), true, ctx.Logger())
+	res := handler(checkCtx, makeOrderMsg(bob, 10, "BTC", "2", "ETH", STPNone))
+	require.True(t, res.IsOK(), res.Log)
+	require.Len(t, keeper.PeekOrderwallOrders(checkCtx, pair, 10), 1)
+
+	// the block commits another order instead
+	deliverStore := ctx.MultiStore().CacheMultiStore()
+	deliverCtx := ctx.WithMultiStore(deliverStore)
+	res = handler(deliverCtx, makeOrderMsg(alice, 5, "BTC", "3", "ETH", STPNone))
+	require.True(t, res.IsOK(), res.Log)
+	deliverStore.Write()
+
+	keeper.ResetCheckTxOrderbookCache()
+	checkCtx = sdk.NewContext(ctx.MultiStore().CacheMultiStore(), ctx.BlockHeader(), true, ctx.Logger())
+	orders := keeper.PeekOrderwallOrders(checkCtx, pair, 10)
+	require.Len(t, orders, 1)
+	require.Equal(t, alice, orders[0].Owner)
+}
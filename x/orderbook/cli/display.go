@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+	"github.com/sunnya97/sdk-dex-mvp/x/token"
+	tokencmd "github.com/sunnya97/sdk-dex-mvp/x/token/cli"
+)
+
+const (
+	flagBaseUnits = "base-units"
+
+	// query route of the token module, which keeps the denom metadata
+	tokenRoute = "token"
+)
+
+// orderUnits is the unit the sell coins of an order are written in
+type orderUnits struct {
+	metadata token.DenomMetadata
+	name     string
+}
+
+// Parses an amount written in the same unit as the sell coins, like an iceberg's display amount
+func (u orderUnits) parseAmount(amountStr string) (sdk.Int, error) {
+	return u.metadata.ParseAmount(amountStr, u.name)
+}
+
+// looks up the metadata of a denom written in an order, which has only base units with --base-units
+func queryOrderDenom(cliCtx context.CLIContext, name string) (token.DenomMetadata, error) {
+	if viper.GetBool(flagBaseUnits) {
+		return token.BaseDenomMetadata(name), nil
+	}
+	return tokencmd.QueryDenomMetadata(cliCtx, tokenRoute, name)
+}
+
+// Parses the [sellcoins] [priceratio] [numerDenom] [denomDenom] arguments of an order, written in the display
+// units of the denoms unless --base-units is set, and returns the sell coins and price in base units
+func parseOrderArgs(cliCtx context.CLIContext, args []string) (sellCoins sdk.Coin, price orderbook.Price, units orderUnits, err error) {
+	amountStr, sellName, err := token.SplitAmount(args[0])
+	if err != nil {
+		return sellCoins, price, units, err
+	}
+	sellMetadata, err := queryOrderDenom(cliCtx, sellName)
+	if err != nil {
+		return sellCoins, price, units, err
+	}
+	sellAmount, err := sellMetadata.ParseAmount(amountStr, sellName)
+	if err != nil {
+		return sellCoins, price, units, err
+	}
+	sellCoins = sdk.NewCoin(sellMetadata.Denom, sellAmount)
+	units = orderUnits{sellMetadata, sellName}
+
+	priceRatio, err := sdk.NewDecFromStr(args[1])
+	if err != nil {
+		return sellCoins, price, units, err
+	}
+
+	numerMetadata, err := queryOrderDenom(cliCtx, args[2])
+	if err != nil {
+		return sellCoins, price, units, err
+	}
+	denomMetadata, err := queryOrderDenom(cliCtx, args[3])
+	if err != nil {
+		return sellCoins, price, units, err
+	}
+
+	numerDenom := numerMetadata.Denom
+	denomDenom := denomMetadata.Denom
+
+	if numerDenom != sellCoins.Denom && denomDenom != sellCoins.Denom || numerDenom == denomDenom {
+		return sellCoins, price, units, orderbook.ErrInvalidDenomPair(orderbook.DefaultCodespace)
+	}
+
+	priceRatio = token.PriceToBase(priceRatio, numerMetadata.DecimalsOf(args[2]), denomMetadata.DecimalsOf(args[3]))
+	if !orderbook.ValidSortableDec(priceRatio) {
+		return sellCoins, price, units, orderbook.ErrInvalidPriceRange(orderbook.DefaultCodespace, priceRatio)
+	}
+	price = orderbook.NewPrice(priceRatio, numerDenom, denomDenom)
+
+	if denomDenom != sellCoins.Denom {
+		price = price.Reciprocal()
+	}
+
+	return sellCoins, price, units, nil
+}
+
+// Renders an order's sell coins and price in the display units of its denoms, looked up once per denom
+func formatOrders(cliCtx context.CLIContext, orders []orderbook.Order) ([]string, error) {
+	metadata := make(map[string]token.DenomMetadata)
+	lookup := func(denom string) (token.DenomMetadata, error) {
+		if m, found := metadata[denom]; found {
+			return m, nil
+		}
+		m, err := queryOrderDenom(cliCtx, denom)
+		metadata[denom] = m
+		return m, err
+	}
+
+	var lines []string
+	for _, order := range orders {
+		sellMetadata, err := lookup(order.SellCoins.Denom)
+		if err != nil {
+			return nil, err
+		}
+		numerMetadata, err := lookup(order.Price.NumeratorDenom)
+		if err != nil {
+			return nil, err
+		}
+		denomMetadata, err := lookup(order.Price.DenomenatorDenom)
+		if err != nil {
+			return nil, err
+		}
+
+		ratio := token.PriceFromBase(order.Price.Ratio, numerMetadata.DisplayDecimals(), denomMetadata.DisplayDecimals())
+		lines = append(lines, fmt.Sprintf("%d - %s @ %s %s/%s",
+			order.OrderID,
+			sellMetadata.FormatAmount(order.SellCoins.Amount),
+			ratio,
+			numerMetadata.Unit(),
+			denomMetadata.Unit(),
+		))
+	}
+	return lines, nil
+}
+
+// Renders the price levels of a depth in the display units of its pair: the amounts in the sell denom and the
+// prices in buy denom per sell denom, the units both sides of the depth are in
+func formatDepth(depth orderbook.Depth, sellMetadata token.DenomMetadata, buyMetadata token.DenomMetadata) []string {
+	lines := []string{"asks:"}
+	for _, level := range depth.Asks {
+		lines = append(lines, formatPriceLevel(level, sellMetadata, buyMetadata))
+	}
+	lines = append(lines, "bids:")
+	for _, level := range depth.Bids {
+		lines = append(lines, formatPriceLevel(level, sellMetadata, buyMetadata))
+	}
+	return lines
+}
+
+func formatPriceLevel(level orderbook.PriceLevel, sellMetadata token.DenomMetadata, buyMetadata token.DenomMetadata) string {
+	ratio := token.PriceFromBase(level.Price, buyMetadata.DisplayDecimals(), sellMetadata.DisplayDecimals())
+	return fmt.Sprintf("%s @ %s %s/%s", sellMetadata.FormatAmount(level.Amount), ratio, buyMetadata.Unit(), sellMetadata.Unit())
+}
@@ -2,15 +2,32 @@ package cli
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook/client"
 
+	cosmosclient "github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/context"
 	"github.com/cosmos/cosmos-sdk/codec"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
-// GetCmdGetOrder queries information about a name
+const flagLevels = "levels"
+
+// Returns the orderbook state at the height of --height, read from the raw store with proofs that are checked
+// against verified headers unless the node is trusted.  The orderbook store is called like its query route.
+// Returns nil when the node is trusted and no height is given, as the latest state is then read with custom queries
+func provenState(cliCtx context.CLIContext, queryRoute string, cdc *codec.Codec) *client.ProvenState {
+	height := viper.GetInt64(cosmosclient.FlagHeight)
+	if cliCtx.TrustNode && height == 0 {
+		return nil
+	}
+	return client.NewProvenState(client.NewNodeBackend(cliCtx), cdc, queryRoute, height, cliCtx.TrustNode)
+}
+
+// GetCmdGetOrder queries an order by orderID, at a past height with --height
 func GetCmdGetOrder(queryRoute string, cdc *codec.Codec) *cobra.Command {
 	return &cobra.Command{
 		Use:   "order [orderID]",
@@ -20,6 +37,23 @@ func GetCmdGetOrder(queryRoute string, cdc *codec.Codec) *cobra.Command {
 			cliCtx := context.NewCLIContext().WithCodec(cdc)
 			orderIDStr := args[0]
 
+			if state := provenState(cliCtx, queryRoute, cdc); state != nil {
+				orderID, err := strconv.ParseInt(orderIDStr, 10, 64)
+				if err != nil {
+					return err
+				}
+				order, err := state.Order(orderID)
+				if err != nil {
+					return err
+				}
+				res, err := codec.MarshalJSONIndent(cdc, order)
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(res))
+				return nil
+			}
+
 			res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/order/%s", queryRoute, orderIDStr), nil)
 			if err != nil {
 				fmt.Printf("could not find order with orderID %s \n", orderIDStr)
@@ -33,40 +67,202 @@ func GetCmdGetOrder(queryRoute string, cdc *codec.Codec) *cobra.Command {
 	}
 }
 
-// GetCmdWhois queries information about a domain
+// GetCmdGetOrderwall queries the orders resting in the orderwall of a pair, in display units, at a past height with --height
 func GetCmdGetOrderwall(queryRoute string, cdc *codec.Codec) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "orderwall [sellDenom] [buyDenom]",
 		Short: "Get orderwall of a specific pair",
 		Args:  cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cliCtx := context.NewCLIContext().WithCodec(cdc)
-			sellDenom := args[0]
-			buyDenom := args[1]
+			sellMetadata, err := queryOrderDenom(cliCtx, args[0])
+			if err != nil {
+				return err
+			}
+			buyMetadata, err := queryOrderDenom(cliCtx, args[1])
+			if err != nil {
+				return err
+			}
 
 			denomPair := orderbook.DenomPair{
-				SellDenom: sellDenom,
-				BuyDenom:  buyDenom,
+				SellDenom: sellMetadata.Denom,
+				BuyDenom:  buyMetadata.Denom,
 			}
 
-			res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/orderwall/%s", queryRoute, denomPair.String()), nil)
+			var orders []orderbook.Order
+			if state := provenState(cliCtx, queryRoute, cdc); state != nil {
+				orders, err = state.Orderwall(denomPair)
+				if err != nil {
+					return err
+				}
+			} else {
+				res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/orderwall/%s", queryRoute, denomPair.String()), nil)
+				if err != nil {
+					fmt.Printf("could not find orderwall \n")
+					return nil
+				}
+
+				err = cdc.UnmarshalJSON(res, &orders)
+				if err != nil {
+					return err
+				}
+			}
+
+			lines, err := formatOrders(cliCtx, orders)
+			if err != nil {
+				return err
+			}
+			for _, line := range lines {
+				fmt.Println(line)
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().Bool(flagBaseUnits, false, "Show amounts and prices in base units of the denoms instead of display units")
+	return cmd
+}
+
+// GetCmdGetDepth queries the price levels of both sides of the book of a pair, in display units, at a past height with --height
+func GetCmdGetDepth(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "depth [sellDenom] [buyDenom]",
+		Short: "Get the price levels of both sides of the book of a pair",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			sellMetadata, err := queryOrderDenom(cliCtx, args[0])
+			if err != nil {
+				return err
+			}
+			buyMetadata, err := queryOrderDenom(cliCtx, args[1])
 			if err != nil {
-				fmt.Printf("could not find orderwall \n")
+				return err
+			}
+
+			denomPair := orderbook.NewDenomPair(sellMetadata.Denom, buyMetadata.Denom)
+			levels := viper.GetInt64(flagLevels)
+			if levels <= 0 {
+				return fmt.Errorf("--%s must be positive", flagLevels)
+			}
+
+			var depth orderbook.Depth
+			if state := provenState(cliCtx, queryRoute, cdc); state != nil {
+				depth, err = state.Depth(denomPair, levels)
+				if err != nil {
+					return err
+				}
+			} else {
+				res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/depth/%s/%d", queryRoute, denomPair, levels), nil)
+				if err != nil {
+					return err
+				}
+				err = cdc.UnmarshalJSON(res, &depth)
+				if err != nil {
+					return err
+				}
+			}
+
+			for _, line := range formatDepth(depth, sellMetadata, buyMetadata) {
+				fmt.Println(line)
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().Bool(flagBaseUnits, false, "Show amounts and prices in base units of the denoms instead of display units")
+	cmd.Flags().Int64(flagLevels, orderbook.DefaultDepthLevels, "Number of price levels of each side of the book")
+	return cmd
+}
+
+// GetCmdGetTWAPOrder queries the progress of a TWAP order
+func GetCmdGetTWAPOrder(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "twap-order [twapID]",
+		Short: "get the progress of a TWAP order by twapID",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			twapIDStr := args[0]
+
+			res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/twap/%s", queryRoute, twapIDStr), nil)
+			if err != nil {
+				fmt.Printf("could not find TWAP order with twapID %s \n", twapIDStr)
 				return nil
 			}
 
-			var orders []orderbook.Order
+			fmt.Println(string(res))
+
+			return nil
+		},
+	}
+}
 
-			cdc.UnmarshalJSON(res, &orders)
+// GetCmdGetClientOrder queries an order by the client order ID its owner gave it
+func GetCmdGetClientOrder(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "client-order [owner] [clientOrderID]",
+		Short: "get order by its owner and client order ID",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			owner := args[0]
+			clientOrderID := args[1]
+
+			res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/client-order/%s/%s", queryRoute, owner, clientOrderID), nil)
+			if err != nil {
+				fmt.Printf("could not find order with client order ID %s \n", clientOrderID)
+				return nil
+			}
+
+			fmt.Println(string(res))
+
+			return nil
+		},
+	}
+}
+
+// GetCmdGetParams queries the orderbook params
+func GetCmdGetParams(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "params",
+		Short: "get the orderbook params",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/params", queryRoute), nil)
+			if err != nil {
+				return err
+			}
+
+			var params orderbook.Params
+			cdc.MustUnmarshalJSON(res, &params)
+			fmt.Println(params)
+
+			return nil
+		},
+	}
+}
+
+// GetCmdGetMarkets queries the listed markets
+func GetCmdGetMarkets(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "markets",
+		Short: "get the listed markets and whether they are halted",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/markets", queryRoute), nil)
+			if err != nil {
+				return err
+			}
 
-			for _, order := range orders {
-				fmt.Printf("%d - %s @ %d %s/%s \n",
-					order.OrderID,
-					order.SellCoins,
-					order.Price.Ratio,
-					order.Price.NumeratorDenom,
-					order.Price.DenomenatorDenom,
-				)
+			var markets []orderbook.Market
+			cdc.MustUnmarshalJSON(res, &markets)
+			for _, market := range markets {
+				fmt.Println(market)
 			}
 
 			return nil
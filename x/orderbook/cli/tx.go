@@ -1,10 +1,12 @@
 package cli
 
 import (
+	"io/ioutil"
 	"strconv"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 
 	"github.com/cosmos/cosmos-sdk/client/context"
 	"github.com/cosmos/cosmos-sdk/client/utils"
@@ -17,12 +19,24 @@ import (
 	authtxb "github.com/cosmos/cosmos-sdk/x/auth/client/txbuilder"
 )
 
+const (
+	flagDisplay  = "display"
+	flagSTP      = "stp"
+	flagClientID = "client-id"
+	flagSlice    = "slice"
+	flagInterval = "interval"
+	flagMarket   = "market"
+)
+
 // GetCmdMakeOrder is the CLI command for sending a MakeOrder transaction
 func GetCmdMakeOrder(cdc *codec.Codec) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "make-order [sellcoins] @ [priceratio] [numerDenom] / [denomDenom]",
 		Short: "make an order for selling coins for another coin at a certain price",
-		Args:  cobra.ExactArgs(4),
+		Long: `Make an order for selling coins for another coin at a certain price, e.g.
+	make-order 1.5BTC 30.25 ETH BTC
+Amounts and prices are in the display units of the denoms, unless --base-units is set.`,
+		Args: cobra.ExactArgs(4),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cliCtx := context.NewCLIContext().
 				WithCodec(cdc).
@@ -34,35 +48,131 @@ func GetCmdMakeOrder(cdc *codec.Codec) *cobra.Command {
 				return err
 			}
 
-			sellCoins, err := sdk.ParseCoin(args[0])
+			account, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+
+			sellCoins, price, units, err := parseOrderArgs(cliCtx, args)
+			if err != nil {
+				return err
+			}
+
+			displayAmount := sdk.ZeroInt()
+			if displayStr := viper.GetString(flagDisplay); displayStr != "" {
+				displayAmount, err = units.parseAmount(displayStr)
+				if err != nil {
+					return err
+				}
+			}
+
+			stp, err := orderbook.SelfTradePreventionFromStr(viper.GetString(flagSTP))
 			if err != nil {
 				return err
 			}
 
+			msg := orderbook.NewMsgMakeOrder(account, viper.GetString(flagClientID), sellCoins, price, time.Time{}, displayAmount, stp)
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			cliCtx.PrintResponse = true
+
+			return utils.CompleteAndBroadcastTxCli(txBldr, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	cmd.Flags().String(flagDisplay, "", "Amount of sellcoins to show in the orderwall at a time (makes an iceberg order)")
+	cmd.Flags().String(flagClientID, "", "Optional ID for the order, unique among your orders")
+	cmd.Flags().String(flagSTP, "none", "Self-trade prevention mode: none, cancel-newest, cancel-oldest, cancel-both or decrement-and-cancel")
+	cmd.Flags().Bool(flagBaseUnits, false, "Write amounts and prices in base units of the denoms instead of display units")
+
+	return cmd
+}
+
+// GetCmdMakeOrder is the CLI command for sending a MakeOrder transaction
+func GetCmdRemoveOrder(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove-order [orderID]",
+		Short: "remove an order by its orderID, or by its client order ID with --client-id",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithAccountDecoder(authcmd.GetAccountDecoder(cdc))
+
+			txBldr := authtxb.NewTxBuilderFromCLI().WithCodec(cdc)
+
+			if err := cliCtx.EnsureAccountExists(); err != nil {
+				return err
+			}
+
 			account, err := cliCtx.GetFromAddress()
 			if err != nil {
 				return err
 			}
 
-			priceRatio, err := sdk.NewDecFromStr(args[1])
+			var msg orderbook.MsgRemoveOrder
+			if len(args) == 0 {
+				msg = orderbook.NewMsgRemoveOrderByClientOrderID(account, viper.GetString(flagClientID))
+			} else {
+				orderID, err := strconv.ParseInt(args[0], 10, 64)
+				if err != nil {
+					return err
+				}
+				msg = orderbook.NewMsgRemoveOrder(account, orderID)
+			}
+
+			err = msg.ValidateBasic()
 			if err != nil {
 				return err
 			}
 
-			numerDenom := args[2]
-			denomDenom := args[3]
+			cliCtx.PrintResponse = true
+
+			return utils.CompleteAndBroadcastTxCli(txBldr, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	cmd.Flags().String(flagClientID, "", "Client order ID of the order to remove, instead of its orderID")
+
+	return cmd
+}
+
+// GetCmdMakeTWAPOrder is the CLI command for sending a MakeTWAPOrder transaction
+func GetCmdMakeTWAPOrder(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "make-twap-order [sellcoins] @ [priceratio] [numerDenom] / [denomDenom]",
+		Short: "sell coins gradually, in slices submitted every few blocks",
+		Args:  cobra.ExactArgs(4),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithAccountDecoder(authcmd.GetAccountDecoder(cdc))
+
+			txBldr := authtxb.NewTxBuilderFromCLI().WithCodec(cdc)
+
+			if err := cliCtx.EnsureAccountExists(); err != nil {
+				return err
+			}
 
-			if numerDenom != sellCoins.Denom && denomDenom != sellCoins.Denom || numerDenom == denomDenom {
-				return orderbook.ErrInvalidDenomPair(orderbook.DefaultCodespace)
+			account, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
 			}
 
-			price := orderbook.NewPrice(priceRatio, args[2], args[3])
+			sellCoins, price, units, err := parseOrderArgs(cliCtx, args)
+			if err != nil {
+				return err
+			}
 
-			if denomDenom != sellCoins.Denom {
-				price = price.Reciprocal()
+			sliceAmount, err := units.parseAmount(viper.GetString(flagSlice))
+			if err != nil {
+				return orderbook.ErrInvalidTWAPSchedule(orderbook.DefaultCodespace)
 			}
 
-			msg := orderbook.NewMsgMakeOrder(account, sellCoins, price, time.Time{})
+			msg := orderbook.NewMsgMakeTWAPOrder(account, sellCoins, price, viper.GetBool(flagMarket), sliceAmount, viper.GetInt64(flagInterval))
 			err = msg.ValidateBasic()
 			if err != nil {
 				return err
@@ -73,13 +183,60 @@ func GetCmdMakeOrder(cdc *codec.Codec) *cobra.Command {
 			return utils.CompleteAndBroadcastTxCli(txBldr, cliCtx, []sdk.Msg{msg})
 		},
 	}
+
+	cmd.Flags().String(flagSlice, "", "Amount of sellcoins to submit in each slice")
+	cmd.Flags().Int64(flagInterval, 1, "Number of blocks between slices")
+	cmd.Flags().Bool(flagMarket, false, "Execute slices at any price instead of at most priceratio")
+	cmd.Flags().Bool(flagBaseUnits, false, "Write amounts and prices in base units of the denoms instead of display units")
+
+	return cmd
 }
 
-// GetCmdMakeOrder is the CLI command for sending a MakeOrder transaction
-func GetCmdRemoveOrder(cdc *codec.Codec) *cobra.Command {
+// GetCmdCancelTWAPOrder is the CLI command for sending a CancelTWAPOrder transaction
+func GetCmdCancelTWAPOrder(cdc *codec.Codec) *cobra.Command {
 	return &cobra.Command{
-		Use:   "remove-order [orderID]",
-		Short: "make an order for selling coins for another coin at a certain price",
+		Use:   "cancel-twap-order [twapID]",
+		Short: "stop a TWAP order and refund whatever hasn't been sold yet",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithAccountDecoder(authcmd.GetAccountDecoder(cdc))
+
+			txBldr := authtxb.NewTxBuilderFromCLI().WithCodec(cdc)
+
+			if err := cliCtx.EnsureAccountExists(); err != nil {
+				return err
+			}
+
+			account, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+
+			twapID, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return err
+			}
+
+			msg := orderbook.NewMsgCancelTWAPOrder(account, twapID)
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			cliCtx.PrintResponse = true
+
+			return utils.CompleteAndBroadcastTxCli(txBldr, cliCtx, []sdk.Msg{msg})
+		},
+	}
+}
+
+// GetCmdUpdateParams is the CLI command for sending an UpdateParams transaction as the orderbook admin
+func GetCmdUpdateParams(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update-params [params.json]",
+		Short: "replace the orderbook params with the ones in a JSON file, as the orderbook admin",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cliCtx := context.NewCLIContext().
@@ -97,12 +254,18 @@ func GetCmdRemoveOrder(cdc *codec.Codec) *cobra.Command {
 				return err
 			}
 
-			orderID, err := strconv.ParseInt(args[0], 10, 64)
+			bz, err := ioutil.ReadFile(args[0])
 			if err != nil {
 				return err
 			}
 
-			msg := orderbook.NewMsgRemoveOrder(account, orderID)
+			var params orderbook.Params
+			err = cdc.UnmarshalJSON(bz, &params)
+			if err != nil {
+				return err
+			}
+
+			msg := orderbook.NewMsgUpdateParams(account, params)
 			err = msg.ValidateBasic()
 			if err != nil {
 				return err
@@ -113,4 +276,107 @@ func GetCmdRemoveOrder(cdc *codec.Codec) *cobra.Command {
 			return utils.CompleteAndBroadcastTxCli(txBldr, cliCtx, []sdk.Msg{msg})
 		},
 	}
+
+	return cmd
+}
+
+const (
+	flagTitle       = "title"
+	flagDescription = "description"
+	flagDeposit     = "deposit"
+)
+
+// GetCmdProposeListMarket is the CLI command for submitting a proposal to list a market, or resume a halted one
+func GetCmdProposeListMarket(cdc *codec.Codec) *cobra.Command {
+	return proposalCmd(cdc, "propose-list-market [baseDenom] [quoteDenom]", "submit a proposal to list a market, or resume trading in a halted one",
+		func(args []string) (orderbook.ProposalAction, error) {
+			return orderbook.NewListMarketAction(args[0], args[1]), nil
+		})
+}
+
+// GetCmdProposeHaltMarket is the CLI command for submitting a proposal to halt trading in a market
+func GetCmdProposeHaltMarket(cdc *codec.Codec) *cobra.Command {
+	return proposalCmd(cdc, "propose-halt-market [baseDenom] [quoteDenom]", "submit a proposal to stop new orders from being made in a market",
+		func(args []string) (orderbook.ProposalAction, error) {
+			return orderbook.NewHaltMarketAction(args[0], args[1]), nil
+		})
+}
+
+// GetCmdProposeDelistMarket is the CLI command for submitting a proposal to delist a market
+func GetCmdProposeDelistMarket(cdc *codec.Codec) *cobra.Command {
+	return proposalCmd(cdc, "propose-delist-market [baseDenom] [quoteDenom]", "submit a proposal to delist a market and cancel all of its orders",
+		func(args []string) (orderbook.ProposalAction, error) {
+			return orderbook.NewDelistMarketAction(args[0], args[1]), nil
+		})
+}
+
+// GetCmdProposeChangeParams is the CLI command for submitting a proposal to replace the orderbook params
+func GetCmdProposeChangeParams(cdc *codec.Codec) *cobra.Command {
+	cmd := proposalCmd(cdc, "propose-change-params [params.json]", "submit a proposal to replace the orderbook params with the ones in a JSON file",
+		func(args []string) (orderbook.ProposalAction, error) {
+			bz, err := ioutil.ReadFile(args[0])
+			if err != nil {
+				return orderbook.ProposalAction{}, err
+			}
+
+			var params orderbook.Params
+			err = cdc.UnmarshalJSON(bz, &params)
+			if err != nil {
+				return orderbook.ProposalAction{}, err
+			}
+			return orderbook.NewChangeParamsAction(params), nil
+		})
+	cmd.Args = cobra.ExactArgs(1)
+	return cmd
+}
+
+// builds a command that submits an orderbook proposal with the action made from its args
+func proposalCmd(cdc *codec.Codec, use string, short string, makeAction func(args []string) (orderbook.ProposalAction, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().
+				WithCodec(cdc).
+				WithAccountDecoder(authcmd.GetAccountDecoder(cdc))
+
+			txBldr := authtxb.NewTxBuilderFromCLI().WithCodec(cdc)
+
+			if err := cliCtx.EnsureAccountExists(); err != nil {
+				return err
+			}
+
+			account, err := cliCtx.GetFromAddress()
+			if err != nil {
+				return err
+			}
+
+			deposit, err := sdk.ParseCoins(viper.GetString(flagDeposit))
+			if err != nil {
+				return err
+			}
+
+			action, err := makeAction(args)
+			if err != nil {
+				return err
+			}
+
+			msg := orderbook.NewMsgSubmitOrderbookProposal(account, viper.GetString(flagTitle), viper.GetString(flagDescription), deposit, action)
+			err = msg.ValidateBasic()
+			if err != nil {
+				return err
+			}
+
+			cliCtx.PrintResponse = true
+
+			return utils.CompleteAndBroadcastTxCli(txBldr, cliCtx, []sdk.Msg{msg})
+		},
+	}
+
+	cmd.Flags().String(flagTitle, "", "Title of the proposal")
+	cmd.Flags().String(flagDescription, "", "Description of the proposal")
+	cmd.Flags().String(flagDeposit, "", "Initial deposit of the proposal")
+
+	return cmd
 }
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	cmn "github.com/tendermint/tendermint/libs/common"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+)
+
+// Backend is the chain a Client queries and sends its transactions to
+type Backend interface {
+	// runs an ABCI query at the latest height, like custom/orderbook/order/1
+	QueryWithData(path string, data []byte) ([]byte, error)
+	// gets an account, to know the account number and sequence to sign with
+	GetAccount(address sdk.AccAddress) (auth.Account, error)
+	// broadcasts a signed transaction and waits for it to be committed
+	BroadcastTx(txBytes []byte) (TxResult, error)
+}
+
+// TxResult is the result of a transaction committed in a block
+type TxResult struct {
+	Height int64
+	Hash   cmn.HexBytes
+	Data   []byte
+	Log    string
+	Tags   sdk.Tags
+}
+
+// TxError is returned for transactions that failed, either when being checked or when being delivered.
+// Transactions that failed when being delivered were included in a block and used up their sequence
+type TxError struct {
+	Code      sdk.CodeType
+	Codespace sdk.CodespaceType
+	Log       string
+	Delivered bool
+}
+
+func (err TxError) Error() string {
+	return fmt.Sprintf("transaction failed with code %d: %s", err.Code, err.Log)
+}
+
+// NodeBackend is a Backend reaching a node through a CLIContext
+type NodeBackend struct {
+	cliCtx context.CLIContext
+}
+
+var _ Backend = NodeBackend{}
+var _ StoreBackend = NodeBackend{}
+
+func NewNodeBackend(cliCtx context.CLIContext) NodeBackend {
+	return NodeBackend{cliCtx: cliCtx}
+}
+
+func (b NodeBackend) QueryWithData(path string, data []byte) ([]byte, error) {
+	return b.cliCtx.QueryWithData(path, data)
+}
+
+func (b NodeBackend) GetAccount(address sdk.AccAddress) (auth.Account, error) {
+	return b.cliCtx.GetAccount(address)
+}
+
+func (b NodeBackend) BroadcastTx(txBytes []byte) (result TxResult, err error) {
+	res, err := b.cliCtx.BroadcastTx(txBytes)
+	if err != nil {
+		return result, err
+	}
+	if !res.CheckTx.IsOK() {
+		return result, TxError{Code: sdk.CodeType(res.CheckTx.Code), Codespace: sdk.CodespaceType(res.CheckTx.Codespace), Log: res.CheckTx.Log}
+	}
+	if !res.DeliverTx.IsOK() {
+		return result, TxError{Code: sdk.CodeType(res.DeliverTx.Code), Codespace: sdk.CodespaceType(res.DeliverTx.Codespace), Log: res.DeliverTx.Log, Delivered: true}
+	}
+	return TxResult{
+		Height: res.Height,
+		Hash:   res.Hash,
+		Data:   res.DeliverTx.Data,
+		Log:    res.DeliverTx.Log,
+		Tags:   res.DeliverTx.Tags,
+	}, nil
+}
+
+func (b NodeBackend) QueryStore(req abci.RequestQuery) (res abci.ResponseQuery, err error) {
+	node, err := b.cliCtx.GetNode()
+	if err != nil {
+		return res, err
+	}
+	result, err := node.ABCIQueryWithOptions(req.Path, req.Data, rpcclient.ABCIQueryOptions{Height: req.Height, Prove: req.Prove})
+	if err != nil {
+		return res, err
+	}
+	return result.Response, nil
+}
+
+// The app hash of the state committed at a height is in the header of the next block, which is verified by
+// the light client of the CLIContext
+func (b NodeBackend) TrustedAppHash(height int64) ([]byte, error) {
+	if b.cliCtx.Verifier == nil {
+		return nil, errors.New("no light client to verify headers with, --chain-id must be set unless the node is trusted")
+	}
+	header, err := b.cliCtx.Verify(height + 1)
+	if err != nil {
+		return nil, err
+	}
+	return header.AppHash, nil
+}
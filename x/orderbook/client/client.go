@@ -0,0 +1,270 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/crypto/keys"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+)
+
+// Gas limit of the transactions of a Client, unless changed with WithGas
+const DefaultGas uint64 = 200000
+
+// Order is an order to place, with amounts in base units.  Price is in units of BuyDenom/SellCoins.Denom
+type Order struct {
+	ClientOrderID       string
+	SellCoins           sdk.Coin
+	BuyDenom            string
+	Price               sdk.Dec
+	ExpirationTime      time.Time
+	DisplayAmount       sdk.Int
+	SelfTradePrevention orderbook.SelfTradePrevention
+}
+
+// Returns the MsgMakeOrder placing the order for an owner
+func (o Order) Msg(owner sdk.AccAddress) orderbook.MsgMakeOrder {
+	displayAmount := o.DisplayAmount
+	if displayAmount == (sdk.Int{}) {
+		displayAmount = sdk.ZeroInt()
+	}
+	return orderbook.NewMsgMakeOrder(owner, o.ClientOrderID, o.SellCoins, orderbook.NewPrice(o.Price, o.BuyDenom, o.SellCoins.Denom),
+		o.ExpirationTime, displayAmount, o.SelfTradePrevention)
+}
+
+// Client places and cancels the orders of one key of a keybase, and queries the orderbook.
+// The account number and sequence of the key are loaded once and then tracked locally, so that orders can be
+// sent in quick succession without waiting for the node to catch up.  A Client is safe for concurrent use
+type Client struct {
+	backend    Backend
+	cdc        *codec.Codec
+	keybase    keys.Keybase
+	name       string
+	passphrase string
+	address    sdk.AccAddress
+	chainID    string
+	queryRoute string
+	gas        uint64
+	fees       sdk.Coins
+
+	mtx           sync.Mutex
+	loaded        bool
+	accountNumber int64
+	sequence      int64
+}
+
+// Creates a Client signing with the key called name in a keybase
+func NewClient(backend Backend, cdc *codec.Codec, keybase keys.Keybase, name string, passphrase string, chainID string) (*Client, error) {
+	info, err := keybase.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		backend:    backend,
+		cdc:        cdc,
+		keybase:    keybase,
+		name:       name,
+		passphrase: passphrase,
+		address:    sdk.AccAddress(info.GetPubKey().Address()),
+		chainID:    chainID,
+		queryRoute: "orderbook",
+		gas:        DefaultGas,
+	}, nil
+}
+
+// Sets the gas limit and the fees of the transactions of the Client
+func (c *Client) WithGas(gas uint64, fees sdk.Coins) *Client {
+	c.gas = gas
+	c.fees = fees
+	return c
+}
+
+// Returns the address of the key the Client signs with
+func (c *Client) Address() sdk.AccAddress {
+	return c.address
+}
+
+// Places an order, returning the result of running it against the opposing orderwall
+func (c *Client) PlaceOrder(order Order) (result orderbook.MakeOrderResult, err error) {
+	res, err := c.SendMsgs(order.Msg(c.address))
+	if err != nil {
+		return result, err
+	}
+	err = c.cdc.UnmarshalJSON(res.Data, &result)
+	return result, err
+}
+
+// Decodes the MakeOrderResults of the MsgMakeOrders of a transaction, in the order of the msgs.
+// The data of the msgs of a transaction are concatenated, and only MsgMakeOrders have JSON data
+func (c *Client) MakeOrderResults(data []byte) (results []orderbook.MakeOrderResult, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for decoder.More() {
+		var raw json.RawMessage
+		err = decoder.Decode(&raw)
+		if err != nil {
+			return nil, err
+		}
+		var result orderbook.MakeOrderResult
+		err = c.cdc.UnmarshalJSON(raw, &result)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Removes a resting order of the Client's key
+func (c *Client) CancelOrder(orderID int64) error {
+	_, err := c.SendMsgs(orderbook.NewMsgRemoveOrder(c.address, orderID))
+	return err
+}
+
+// Removes a resting order of the Client's key by the client order ID it was placed with
+func (c *Client) CancelOrderByClientOrderID(clientOrderID string) error {
+	_, err := c.SendMsgs(orderbook.NewMsgRemoveOrderByClientOrderID(c.address, clientOrderID))
+	return err
+}
+
+// Removes a resting order and places another one in its stead, in a single transaction.
+// If the order can't be removed, for example because it was filled, the new order isn't placed either
+func (c *Client) ReplaceOrder(orderID int64, order Order) (result orderbook.MakeOrderResult, err error) {
+	res, err := c.SendMsgs(orderbook.NewMsgRemoveOrder(c.address, orderID), order.Msg(c.address))
+	if err != nil {
+		return result, err
+	}
+	err = c.cdc.UnmarshalJSON(res.Data, &result)
+	return result, err
+}
+
+// Signs msgs in a transaction and broadcasts it.  If the transaction is rejected because the locally tracked
+// sequence is out of date, for example because the key was also used elsewhere, the sequence is reloaded and
+// the transaction is sent once more
+func (c *Client) SendMsgs(msgs ...sdk.Msg) (result TxResult, err error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for attempt := 0; ; attempt++ {
+		if !c.loaded {
+			err = c.loadAccount()
+			if err != nil {
+				return result, err
+			}
+		}
+
+		txBytes, err := c.sign(msgs)
+		if err != nil {
+			return result, err
+		}
+
+		result, err = c.backend.BroadcastTx(txBytes)
+		if txErr, ok := err.(TxError); ok && txErr.Code == sdk.CodeInvalidSequence && attempt == 0 {
+			c.loaded = false
+			continue
+		}
+		// transactions that failed past the AnteHandler still used up their sequence
+		if err == nil || isDeliverError(err) {
+			c.sequence++
+		} else {
+			c.loaded = false
+		}
+		return result, err
+	}
+}
+
+// Loads the account number and sequence of the Client's key
+func (c *Client) loadAccount() error {
+	account, err := c.backend.GetAccount(c.address)
+	if err != nil {
+		return err
+	}
+	c.accountNumber = account.GetAccountNumber()
+	c.sequence = account.GetSequence()
+	c.loaded = true
+	return nil
+}
+
+// Builds and signs a transaction with the current account number and sequence
+func (c *Client) sign(msgs []sdk.Msg) ([]byte, error) {
+	fee := auth.StdFee{Amount: c.fees, Gas: c.gas}
+	signBytes := auth.StdSignBytes(c.chainID, c.accountNumber, c.sequence, fee, msgs, "")
+
+	sig, pubKey, err := c.keybase.Sign(c.name, c.passphrase, signBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := auth.NewStdTx(msgs, fee, []auth.StdSignature{{
+		PubKey:        pubKey,
+		Signature:     sig,
+		AccountNumber: c.accountNumber,
+		Sequence:      c.sequence,
+	}}, "")
+	return auth.DefaultTxEncoder(c.cdc)(tx)
+}
+
+// Returns whether a transaction failed while running its msgs, after its sequence was used
+func isDeliverError(err error) bool {
+	txErr, ok := err.(TxError)
+	return ok && txErr.Delivered
+}
+
+// Gets the coins of the Client's key that aren't escrowed in orders
+func (c *Client) Coins() (sdk.Coins, error) {
+	account, err := c.backend.GetAccount(c.address)
+	if err != nil {
+		return nil, err
+	}
+	return account.GetCoins(), nil
+}
+
+// Gets an order, resting or closed
+func (c *Client) Order(orderID int64) (order orderbook.Order, err error) {
+	err = c.query(fmt.Sprintf("%s/%d", orderbook.QueryOrder, orderID), &order)
+	return order, err
+}
+
+// Gets the orders resting in the orderwall of a pair
+func (c *Client) Orderwall(pair orderbook.DenomPair) (orders []orderbook.Order, err error) {
+	err = c.query(fmt.Sprintf("%s/%s", orderbook.QueryOrderwall, pair), &orders)
+	return orders, err
+}
+
+// Gets up to levels price levels of each side of the book of a pair
+func (c *Client) Depth(pair orderbook.DenomPair, levels int64) (depth orderbook.Depth, err error) {
+	err = c.query(fmt.Sprintf("%s/%s/%d", orderbook.QueryDepth, pair, levels), &depth)
+	return depth, err
+}
+
+// Gets up to limit of the most recent trades of a pair, newest first
+func (c *Client) Trades(pair orderbook.DenomPair, limit int64) (trades []orderbook.Trade, err error) {
+	err = c.query(fmt.Sprintf("%s/%s/%d", orderbook.QueryTrades, pair, limit), &trades)
+	return trades, err
+}
+
+// Gets the orders an owner has resting in the orderwalls
+func (c *Client) OwnerOrders(owner sdk.AccAddress) (orders []orderbook.Order, err error) {
+	err = c.query(fmt.Sprintf("%s/%s", orderbook.QueryOwnerOrders, owner), &orders)
+	return orders, err
+}
+
+// Gets the listed markets
+func (c *Client) Markets() (markets []orderbook.Market, err error) {
+	err = c.query(orderbook.QueryMarkets, &markets)
+	return markets, err
+}
+
+func (c *Client) query(path string, result interface{}) error {
+	res, err := c.backend.QueryWithData(fmt.Sprintf("custom/%s/%s", c.queryRoute, path), nil)
+	if err != nil {
+		return err
+	}
+	return c.cdc.UnmarshalJSON(res, result)
+}
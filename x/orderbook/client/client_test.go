@@ -0,0 +1,94 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	dbm "github.com/tendermint/tendermint/libs/db"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	app "github.com/sunnya97/sdk-dex-mvp"
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+)
+
+const passphrase = "12345678"
+
+// creates a MockBackend with a BTC/ETH market and a funded Client for every name
+func createTestClients(t *testing.T, names ...string) (*MockBackend, []*Client) {
+	cdc := app.MakeCodec()
+	backend := NewMockBackend(cdc, "test-chain")
+	backend.ListMarket("BTC", "ETH")
+	kb := keys.New(dbm.NewMemDB())
+
+	var clients []*Client
+	for _, name := range names {
+		_, _, err := kb.CreateMnemonic(name, keys.English, passphrase, keys.Secp256k1)
+		require.NoError(t, err)
+		client, err := NewClient(backend, cdc, kb, name, passphrase, "test-chain")
+		require.NoError(t, err)
+		backend.Fund(client.Address(), sdk.Coins{sdk.NewInt64Coin("BTC", 1000), sdk.NewInt64Coin("ETH", 1000)})
+		clients = append(clients, client)
+	}
+	return backend, clients
+}
+
+func sellOrder(amount int64, sellDenom string, price int64, buyDenom string) Order {
+	return Order{
+		SellCoins: sdk.NewInt64Coin(sellDenom, amount),
+		BuyDenom:  buyDenom,
+		Price:     sdk.NewDec(price),
+	}
+}
+
+func TestClient(t *testing.T) {
+	backend, clients := createTestClients(t, "alice", "bob")
+	alice, bob := clients[0], clients[1]
+	pair := orderbook.NewDenomPair("BTC", "ETH")
+
+	// orders in quick succession are sequenced locally
+	for i := int64(0); i < 3; i++ {
+		result, err := bob.PlaceOrder(sellOrder(10, "BTC", 2+i, "ETH"))
+		require.NoError(t, err)
+		require.Equal(t, orderbook.OrderStatusOpen, result.Status)
+	}
+	orders, err := bob.OwnerOrders(bob.Address())
+	require.NoError(t, err)
+	require.Len(t, orders, 3)
+
+	// alice takes bob's cheapest order
+	result, err := alice.PlaceOrder(Order{SellCoins: sdk.NewInt64Coin("ETH", 20), BuyDenom: "BTC", Price: sdk.NewDecWithPrec(5, 1)})
+	require.NoError(t, err)
+	require.True(t, result.Consumed)
+	require.Len(t, result.Trades, 1)
+
+	trades, err := alice.Trades(pair, 10)
+	require.NoError(t, err)
+	require.Len(t, trades, 1)
+	require.Equal(t, alice.Address(), trades[0].Taker)
+
+	depth, err := bob.Depth(pair, 10)
+	require.NoError(t, err)
+	require.Len(t, depth.Asks, 2)
+
+	// bob moves his best order up and cancels the other one
+	orders, err = bob.OwnerOrders(bob.Address())
+	require.NoError(t, err)
+	replaced, err := bob.ReplaceOrder(orders[0].OrderID, sellOrder(10, "BTC", 5, "ETH"))
+	require.NoError(t, err)
+	require.Equal(t, orderbook.OrderStatusOpen, replaced.Status)
+	require.NoError(t, bob.CancelOrder(orders[1].OrderID))
+
+	orders, err = bob.OwnerOrders(bob.Address())
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	require.Equal(t, replaced.OrderID, orders[0].OrderID)
+
+	// a key used elsewhere makes the local sequence out of date, which the client recovers from
+	account := backend.accountKeeper.GetAccount(backend.Context(), bob.Address())
+	require.NoError(t, account.SetSequence(account.GetSequence()+1))
+	backend.accountKeeper.SetAccount(backend.Context(), account)
+	require.NoError(t, bob.CancelOrder(replaced.OrderID))
+}
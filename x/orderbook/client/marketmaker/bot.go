@@ -0,0 +1,238 @@
+package marketmaker
+
+import (
+	"fmt"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook/client"
+)
+
+// Config of a Bot.  Prices are in units of QuoteDenom/BaseDenom and amounts in base units
+type Config struct {
+	BaseDenom  string
+	QuoteDenom string
+	// relative distance from the reference price to the first level of each side, e.g. 0.01 for 1%
+	Spread sdk.Dec
+	// relative distance between two levels of a side
+	LevelStep sdk.Dec
+	// number of levels quoted on each side
+	Levels int
+	// amount of BaseDenom bought or sold by each level
+	OrderSize sdk.Int
+	// the bot stops bidding so that it never holds more than MaxBaseInventory of BaseDenom,
+	// and stops asking so that it never holds less than MinBaseInventory
+	MaxBaseInventory sdk.Int
+	MinBaseInventory sdk.Int
+}
+
+// Checks that a Config can be quoted with
+func (cfg Config) Validate() error {
+	switch {
+	case cfg.BaseDenom == "" || cfg.QuoteDenom == "" || cfg.BaseDenom == cfg.QuoteDenom:
+		return fmt.Errorf("the market must be between two different denoms")
+	case cfg.Spread.IsNegative() || cfg.LevelStep.IsNegative():
+		return fmt.Errorf("spread and level step can't be negative")
+	case cfg.Levels <= 0:
+		return fmt.Errorf("at least one level must be quoted")
+	case sdk.OneDec().Sub(cfg.Spread).Sub(cfg.LevelStep.MulInt(sdk.NewInt(int64(cfg.Levels - 1)))).LTE(sdk.ZeroDec()):
+		return fmt.Errorf("the lowest bid must have a positive price")
+	case !cfg.OrderSize.IsPositive():
+		return fmt.Errorf("order size must be positive")
+	case cfg.MaxBaseInventory.LT(cfg.MinBaseInventory):
+		return fmt.Errorf("max base inventory must be at least min base inventory")
+	}
+	return nil
+}
+
+// PriceFeed gives the reference price the ladder is quoted around
+type PriceFeed interface {
+	Price() (sdk.Dec, error)
+}
+
+// StaticPrice is a PriceFeed always giving the same price
+type StaticPrice sdk.Dec
+
+func (p StaticPrice) Price() (sdk.Dec, error) {
+	return sdk.Dec(p), nil
+}
+
+// Bot quotes a symmetric ladder of orders around a reference price in a market, through a Client.
+// Whenever one of its orders is filled or the reference price moves, it removes its orders and quotes the ladder again,
+// with only as many levels on each side as its inventory limits allow
+type Bot struct {
+	cfg    Config
+	client *client.Client
+	feed   PriceFeed
+
+	quotedPrice sdk.Dec
+	quoted      map[int64]bool
+}
+
+func NewBot(cfg Config, c *client.Client, feed PriceFeed) (*Bot, error) {
+	err := cfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &Bot{
+		cfg:    cfg,
+		client: c,
+		feed:   feed,
+		quoted: make(map[int64]bool),
+	}, nil
+}
+
+// Quotes the ladder every interval until an error happens
+func (b *Bot) Run(interval time.Duration) error {
+	for {
+		_, err := b.Step()
+		if err != nil {
+			return err
+		}
+		time.Sleep(interval)
+	}
+}
+
+// Quotes the ladder again if any of the bot's orders was filled or the reference price moved.
+// Returns whether the ladder was quoted again
+func (b *Bot) Step() (requoted bool, err error) {
+	price, err := b.feed.Price()
+	if err != nil {
+		return false, err
+	}
+
+	orders, err := b.marketOrders()
+	if err != nil {
+		return false, err
+	}
+	if !b.needsRequote(orders, price) {
+		return false, nil
+	}
+
+	baseInventory, err := b.baseInventory(orders)
+	if err != nil {
+		return false, err
+	}
+
+	// the orders are removed and the ladder quoted again in a single transaction
+	var msgs []sdk.Msg
+	for _, order := range orders {
+		msgs = append(msgs, orderbook.NewMsgRemoveOrder(b.client.Address(), order.OrderID))
+	}
+	for _, order := range b.Ladder(price, baseInventory) {
+		msgs = append(msgs, order.Msg(b.client.Address()))
+	}
+	if len(msgs) == 0 {
+		return false, nil
+	}
+
+	res, err := b.client.SendMsgs(msgs...)
+	if err != nil {
+		return false, err
+	}
+
+	b.quoted = make(map[int64]bool)
+	results, err := b.client.MakeOrderResults(res.Data)
+	if err != nil {
+		return true, err
+	}
+	for _, result := range results {
+		if !result.Consumed {
+			b.quoted[result.OrderID] = true
+		}
+	}
+	b.quotedPrice = price
+	return true, nil
+}
+
+// Returns the orders of the ladder around price, given the amount of BaseDenom the bot holds.
+// Asks sell OrderSize of BaseDenom and bids buy OrderSize of BaseDenom, nearest levels first
+func (b *Bot) Ladder(price sdk.Dec, baseInventory sdk.Int) (orders []client.Order) {
+	cfg := b.cfg
+	bidLevels := levelsWithin(cfg.MaxBaseInventory.Sub(baseInventory), cfg.OrderSize, cfg.Levels)
+	askLevels := levelsWithin(baseInventory.Sub(cfg.MinBaseInventory), cfg.OrderSize, cfg.Levels)
+
+	for i := 0; i < askLevels; i++ {
+		offset := cfg.Spread.Add(cfg.LevelStep.MulInt(sdk.NewInt(int64(i))))
+		askPrice := price.Mul(sdk.OneDec().Add(offset))
+		orders = append(orders, client.Order{
+			SellCoins: sdk.NewCoin(cfg.BaseDenom, cfg.OrderSize),
+			BuyDenom:  cfg.QuoteDenom,
+			Price:     askPrice,
+		})
+	}
+
+	for i := 0; i < bidLevels; i++ {
+		offset := cfg.Spread.Add(cfg.LevelStep.MulInt(sdk.NewInt(int64(i))))
+		bidPrice := price.Mul(sdk.OneDec().Sub(offset))
+		quoteAmount := sdk.NewDecFromInt(cfg.OrderSize).Mul(bidPrice).RoundInt()
+		if !quoteAmount.IsPositive() {
+			continue
+		}
+		orders = append(orders, client.Order{
+			SellCoins: sdk.NewCoin(cfg.QuoteDenom, quoteAmount),
+			BuyDenom:  cfg.BaseDenom,
+			Price:     orderbook.SDKDecReciprocal(bidPrice),
+		})
+	}
+
+	return orders
+}
+
+// Returns whether the resting orders of the bot no longer match the ladder it last quoted
+func (b *Bot) needsRequote(orders []orderbook.Order, price sdk.Dec) bool {
+	if b.quotedPrice.IsNil() || !b.quotedPrice.Equal(price) || len(orders) != len(b.quoted) {
+		return true
+	}
+	for _, order := range orders {
+		if !b.quoted[order.OrderID] || order.FilledCoins.IsPositive() {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the bot's orders resting in either direction of its market
+func (b *Bot) marketOrders() (orders []orderbook.Order, err error) {
+	owned, err := b.client.OwnerOrders(b.client.Address())
+	if err != nil {
+		return nil, err
+	}
+	for _, order := range owned {
+		pair := order.Pair()
+		if (pair.SellDenom == b.cfg.BaseDenom && pair.BuyDenom == b.cfg.QuoteDenom) ||
+			(pair.SellDenom == b.cfg.QuoteDenom && pair.BuyDenom == b.cfg.BaseDenom) {
+			orders = append(orders, order)
+		}
+	}
+	return orders, nil
+}
+
+// Returns the BaseDenom the bot holds, including what's escrowed in its asks
+func (b *Bot) baseInventory(orders []orderbook.Order) (sdk.Int, error) {
+	coins, err := b.client.Coins()
+	if err != nil {
+		return sdk.Int{}, err
+	}
+	inventory := coins.AmountOf(b.cfg.BaseDenom)
+	for _, order := range orders {
+		if order.SellCoins.Denom == b.cfg.BaseDenom {
+			inventory = inventory.Add(order.TotalSellCoins().Amount)
+		}
+	}
+	return inventory, nil
+}
+
+// Returns how many levels of size fit in room, up to max
+func levelsWithin(room sdk.Int, size sdk.Int, max int) int {
+	if !room.IsPositive() {
+		return 0
+	}
+	levels := room.Div(size)
+	if levels.GT(sdk.NewInt(int64(max))) {
+		return max
+	}
+	return int(levels.Int64())
+}
@@ -0,0 +1,147 @@
+package marketmaker
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	app "github.com/sunnya97/sdk-dex-mvp"
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook/client"
+)
+
+// quotes 2 levels of 10 BTC on each side, 10% and 20% away from the reference price, holding between 0 and maxBase BTC
+func testConfig(maxBase int64) Config {
+	return Config{
+		BaseDenom:        "BTC",
+		QuoteDenom:       "ETH",
+		Spread:           sdk.NewDecWithPrec(1, 1),
+		LevelStep:        sdk.NewDecWithPrec(1, 1),
+		Levels:           2,
+		OrderSize:        sdk.NewInt(10),
+		MinBaseInventory: sdk.NewInt(0),
+		MaxBaseInventory: sdk.NewInt(maxBase),
+	}
+}
+
+// creates a Simulation around a reference price of 10 ETH per BTC, with the maker holding 30 BTC and 1000 ETH
+func createTestSimulation(t *testing.T, cfg Config) *Simulation {
+	sim, err := NewSimulation(app.MakeCodec(), cfg, StaticPrice(sdk.NewDec(10)),
+		sdk.Coins{sdk.NewInt64Coin("BTC", 30), sdk.NewInt64Coin("ETH", 1000)},
+		sdk.Coins{sdk.NewInt64Coin("BTC", 1000), sdk.NewInt64Coin("ETH", 10000)})
+	require.NoError(t, err)
+	return sim
+}
+
+// returns the coins of the maker, including those escrowed in its orders
+func makerHoldings(t *testing.T, sim *Simulation) sdk.Coins {
+	coins := sim.Backend.GetCoins(sim.Maker.Address())
+	orders, err := sim.Maker.OwnerOrders(sim.Maker.Address())
+	require.NoError(t, err)
+	for _, order := range orders {
+		coins = coins.Plus(sdk.Coins{order.TotalSellCoins()})
+	}
+	return coins
+}
+
+func TestLadder(t *testing.T) {
+	sim := createTestSimulation(t, testConfig(60))
+
+	// 30 BTC leaves room for 3 levels on each side, capped at 2
+	orders := sim.Bot.Ladder(sdk.NewDec(10), sdk.NewInt(30))
+	require.Len(t, orders, 4)
+	require.Equal(t, sdk.NewInt64Coin("BTC", 10), orders[0].SellCoins)
+	require.Equal(t, sdk.NewDec(11), orders[0].Price)
+	require.Equal(t, sdk.NewDec(12), orders[1].Price)
+	require.Equal(t, sdk.NewInt64Coin("ETH", 90), orders[2].SellCoins)
+	require.Equal(t, orderbook.SDKDecReciprocal(sdk.NewDec(9)), orders[2].Price)
+	require.Equal(t, sdk.NewInt64Coin("ETH", 80), orders[3].SellCoins)
+
+	// at the inventory limits only one side is quoted
+	orders = sim.Bot.Ladder(sdk.NewDec(10), sdk.NewInt(60))
+	require.Len(t, orders, 2)
+	require.Equal(t, "BTC", orders[0].SellCoins.Denom)
+	orders = sim.Bot.Ladder(sdk.NewDec(10), sdk.NewInt(5))
+	require.Len(t, orders, 2)
+	require.Equal(t, "ETH", orders[0].SellCoins.Denom)
+}
+
+func TestBotRequotes(t *testing.T) {
+	sim := createTestSimulation(t, testConfig(60))
+
+	requoted, err := sim.Bot.Step()
+	require.NoError(t, err)
+	require.True(t, requoted)
+	orders, err := sim.Maker.OwnerOrders(sim.Maker.Address())
+	require.NoError(t, err)
+	require.Len(t, orders, 4)
+
+	// nothing changed, so the ladder is left alone
+	requoted, err = sim.Bot.Step()
+	require.NoError(t, err)
+	require.False(t, requoted)
+
+	// the taker buys the nearest ask for 110 ETH, and the bot quotes the full ladder again
+	requoted, err = sim.Take(client.Order{
+		SellCoins: sdk.NewInt64Coin("ETH", 110),
+		BuyDenom:  "BTC",
+		Price:     orderbook.SDKDecReciprocal(sdk.NewDec(20)),
+	})
+	require.NoError(t, err)
+	require.True(t, requoted)
+	orders, err = sim.Maker.OwnerOrders(sim.Maker.Address())
+	require.NoError(t, err)
+	require.Len(t, orders, 4)
+	require.Equal(t, sdk.Coins{sdk.NewInt64Coin("BTC", 20), sdk.NewInt64Coin("ETH", 1110)}, makerHoldings(t, sim))
+}
+
+func TestBotInventoryLimits(t *testing.T) {
+	sim := createTestSimulation(t, testConfig(40))
+
+	// 30 BTC leaves room for a single bid below the 40 BTC limit
+	_, err := sim.Bot.Step()
+	require.NoError(t, err)
+	orders, err := sim.Maker.OwnerOrders(sim.Maker.Address())
+	require.NoError(t, err)
+	require.Len(t, orders, 3)
+
+	// the taker sells into the bid, after which the bot only asks
+	requoted, err := sim.Take(client.Order{
+		SellCoins: sdk.NewInt64Coin("BTC", 10),
+		BuyDenom:  "ETH",
+		Price:     sdk.NewDec(5),
+	})
+	require.NoError(t, err)
+	require.True(t, requoted)
+	orders, err = sim.Maker.OwnerOrders(sim.Maker.Address())
+	require.NoError(t, err)
+	require.Len(t, orders, 2)
+	for _, order := range orders {
+		require.Equal(t, "BTC", order.SellCoins.Denom)
+	}
+	require.Equal(t, sdk.NewInt(40), makerHoldings(t, sim).AmountOf("BTC"))
+}
+
+func TestSimulationDeterministic(t *testing.T) {
+	var holdings []sdk.Coins
+	for i := 0; i < 2; i++ {
+		sim := createTestSimulation(t, testConfig(60))
+		_, err := sim.Bot.Step()
+		require.NoError(t, err)
+
+		r := rand.New(rand.NewSource(7))
+		for step := 0; step < 20; step++ {
+			_, err = sim.TakeRandom(r, sdk.NewInt(25))
+			require.NoError(t, err)
+
+			// the bot never quotes its way past its inventory limits
+			base := makerHoldings(t, sim).AmountOf("BTC")
+			require.True(t, base.GTE(sdk.NewInt(0)) && base.LTE(sdk.NewInt(60)), "step %d: %s BTC", step, base)
+		}
+		holdings = append(holdings, makerHoldings(t, sim))
+	}
+	require.Equal(t, holdings[0], holdings[1])
+}
@@ -0,0 +1,158 @@
+package marketmaker
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tendermint/tendermint/libs/cli"
+
+	cosmosclient "github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/client/keys"
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook/client"
+)
+
+const (
+	flagBase         = "base"
+	flagQuote        = "quote"
+	flagPrice        = "price"
+	flagSpread       = "spread"
+	flagStep         = "step"
+	flagLevels       = "levels"
+	flagSize         = "size"
+	flagMinInventory = "min-inventory"
+	flagMaxInventory = "max-inventory"
+	flagInterval     = "interval"
+	flagSimulate     = "simulate"
+	flagSeed         = "seed"
+
+	defaultInterval = 5 * time.Second
+)
+
+// GetCmdMarketMaker is the CLI command running a Bot quoting a market with a key
+func GetCmdMarketMaker(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "market-maker",
+		Short: "Quote a ladder of orders around a reference price in a market",
+		Long: `Quote a symmetric ladder of orders around a reference price in a market, signing with the key --name.
+The orders are removed and quoted again whenever one of them is filled.  Bids stop once the key would hold more than
+--max-inventory of the base denom, and asks once it would hold less than --min-inventory.
+With --simulate, the bot runs against an in-process orderbook instead, with a random taker trading against it.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := configFromFlags()
+			if err != nil {
+				return err
+			}
+			price, err := sdk.NewDecFromStr(viper.GetString(flagPrice))
+			if err != nil {
+				return err
+			}
+
+			if steps := viper.GetInt(flagSimulate); steps > 0 {
+				return simulate(cdc, cfg, StaticPrice(price), steps)
+			}
+
+			name := viper.GetString(cosmosclient.FlagName)
+			kb, err := keys.GetKeyBaseFromDir(viper.GetString(cli.HomeFlag))
+			if err != nil {
+				return err
+			}
+			passphrase, err := keys.GetPassphrase(name)
+			if err != nil {
+				return err
+			}
+
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			c, err := client.NewClient(client.NewNodeBackend(cliCtx), cdc, kb, name, passphrase, viper.GetString(cosmosclient.FlagChainID))
+			if err != nil {
+				return err
+			}
+			bot, err := NewBot(cfg, c, StaticPrice(price))
+			if err != nil {
+				return err
+			}
+			return bot.Run(viper.GetDuration(flagInterval))
+		},
+	}
+	cmd.Flags().String(cosmosclient.FlagName, "", "Name of the key the orders are signed with")
+	cmd.Flags().String(flagBase, "", "Base denom of the market")
+	cmd.Flags().String(flagQuote, "", "Quote denom of the market")
+	cmd.Flags().String(flagPrice, "", "Reference price, in quote base units per base base unit")
+	cmd.Flags().String(flagSpread, "0.01", "Relative distance from the reference price to the first level of each side")
+	cmd.Flags().String(flagStep, "0.01", "Relative distance between two levels of a side")
+	cmd.Flags().Int(flagLevels, 5, "Number of levels quoted on each side")
+	cmd.Flags().String(flagSize, "", "Amount of the base denom bought or sold by each level")
+	cmd.Flags().String(flagMinInventory, "0", "Least amount of the base denom to hold")
+	cmd.Flags().String(flagMaxInventory, "", "Most amount of the base denom to hold")
+	cmd.Flags().Duration(flagInterval, defaultInterval, "How often to check the orders")
+	cmd.Flags().Int(flagSimulate, 0, "Run this many steps against an in-process orderbook instead of a node")
+	cmd.Flags().Int64(flagSeed, 0, "Seed of the random taker of --simulate")
+	cmd.MarkFlagRequired(flagBase)
+	cmd.MarkFlagRequired(flagQuote)
+	cmd.MarkFlagRequired(flagPrice)
+	cmd.MarkFlagRequired(flagSize)
+	cmd.MarkFlagRequired(flagMaxInventory)
+	return cosmosclient.GetCommands(cmd)[0]
+}
+
+func configFromFlags() (cfg Config, err error) {
+	cfg.BaseDenom = viper.GetString(flagBase)
+	cfg.QuoteDenom = viper.GetString(flagQuote)
+	cfg.Levels = viper.GetInt(flagLevels)
+	cfg.Spread, err = sdk.NewDecFromStr(viper.GetString(flagSpread))
+	if err != nil {
+		return cfg, err
+	}
+	cfg.LevelStep, err = sdk.NewDecFromStr(viper.GetString(flagStep))
+	if err != nil {
+		return cfg, err
+	}
+
+	var ok bool
+	for flag, amount := range map[string]*sdk.Int{
+		flagSize:         &cfg.OrderSize,
+		flagMinInventory: &cfg.MinBaseInventory,
+		flagMaxInventory: &cfg.MaxBaseInventory,
+	} {
+		*amount, ok = sdk.NewIntFromString(viper.GetString(flag))
+		if !ok {
+			return cfg, fmt.Errorf("invalid --%s %s", flag, viper.GetString(flag))
+		}
+	}
+	return cfg, cfg.Validate()
+}
+
+// runs the bot against an in-process orderbook, printing its coins after every step of the taker
+func simulate(cdc *codec.Codec, cfg Config, feed StaticPrice, steps int) error {
+	price := sdk.Dec(feed)
+	baseCoins := cfg.MaxBaseInventory.Add(cfg.MinBaseInventory).Div(sdk.NewInt(2))
+	quoteCoins := sdk.NewDecFromInt(cfg.MaxBaseInventory).Mul(price).RoundInt()
+	makerCoins := sdk.Coins{sdk.NewCoin(cfg.BaseDenom, baseCoins), sdk.NewCoin(cfg.QuoteDenom, quoteCoins)}.Sort()
+	takerCoins := sdk.Coins{sdk.NewCoin(cfg.BaseDenom, baseCoins.MulRaw(int64(steps))),
+		sdk.NewCoin(cfg.QuoteDenom, quoteCoins.MulRaw(int64(2*steps)))}.Sort()
+
+	sim, err := NewSimulation(cdc, cfg, feed, makerCoins, takerCoins)
+	if err != nil {
+		return err
+	}
+	_, err = sim.Bot.Step()
+	if err != nil {
+		return err
+	}
+	r := rand.New(rand.NewSource(viper.GetInt64(flagSeed)))
+	for i := 0; i < steps; i++ {
+		_, err = sim.TakeRandom(r, cfg.OrderSize.MulRaw(2))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("step %d: %s\n", i+1, sim.Backend.GetCoins(sim.Maker.Address()))
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package marketmaker
+
+import (
+	"math/rand"
+
+	dbm "github.com/tendermint/tendermint/libs/db"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/crypto/keys"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook/client"
+)
+
+const (
+	simulationChainID    = "marketmaker-simulation"
+	simulationPassphrase = "simulation"
+)
+
+// Simulation runs a Bot against an orderbook Keeper in process, through a MockBackend, along with a taker
+// that trades against the bot.  Everything is deterministic, so that the bot can be tested
+type Simulation struct {
+	Backend *client.MockBackend
+	Bot     *Bot
+	Maker   *client.Client
+	Taker   *client.Client
+}
+
+// Creates a Simulation with the market of the Config listed and the maker and the taker funded
+func NewSimulation(cdc *codec.Codec, cfg Config, feed PriceFeed, makerCoins sdk.Coins, takerCoins sdk.Coins) (*Simulation, error) {
+	backend := client.NewMockBackend(cdc, simulationChainID)
+	backend.ListMarket(cfg.BaseDenom, cfg.QuoteDenom)
+	kb := keys.New(dbm.NewMemDB())
+
+	var clients []*client.Client
+	for _, name := range []string{"maker", "taker"} {
+		_, _, err := kb.CreateMnemonic(name, keys.English, simulationPassphrase, keys.Secp256k1)
+		if err != nil {
+			return nil, err
+		}
+		c, err := client.NewClient(backend, cdc, kb, name, simulationPassphrase, simulationChainID)
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, c)
+	}
+	backend.Fund(clients[0].Address(), makerCoins)
+	backend.Fund(clients[1].Address(), takerCoins)
+
+	bot, err := NewBot(cfg, clients[0], feed)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Simulation{
+		Backend: backend,
+		Bot:     bot,
+		Maker:   clients[0],
+		Taker:   clients[1],
+	}, nil
+}
+
+// Has the taker place an order against the bot, with what isn't filled cancelled, then steps the bot
+func (s *Simulation) Take(order client.Order) (requoted bool, err error) {
+	result, err := s.Taker.PlaceOrder(order)
+	if err != nil {
+		return false, err
+	}
+	if !result.Consumed {
+		err = s.Taker.CancelOrder(result.OrderID)
+		if err != nil {
+			return false, err
+		}
+	}
+	return s.Bot.Step()
+}
+
+// Has the taker either sell up to maxSize of BaseDenom down to half the reference price, or spend the QuoteDenom worth
+// up to maxSize of BaseDenom at the reference price up to twice the reference price, then steps the bot
+func (s *Simulation) TakeRandom(r *rand.Rand, maxSize sdk.Int) (requoted bool, err error) {
+	cfg := s.Bot.cfg
+	price, err := s.Bot.feed.Price()
+	if err != nil {
+		return false, err
+	}
+
+	size := sdk.NewInt(r.Int63n(maxSize.Int64()) + 1)
+	order := client.Order{
+		SellCoins: sdk.NewCoin(cfg.BaseDenom, size),
+		BuyDenom:  cfg.QuoteDenom,
+		Price:     price.Quo(sdk.NewDec(2)),
+	}
+	if r.Intn(2) == 0 {
+		order = client.Order{
+			SellCoins: sdk.NewCoin(cfg.QuoteDenom, sdk.NewDecFromInt(size).Mul(price).RoundInt()),
+			BuyDenom:  cfg.BaseDenom,
+			Price:     orderbook.SDKDecReciprocal(price.MulInt(sdk.NewInt(2))),
+		}
+	}
+	return s.Take(order)
+}
@@ -0,0 +1,204 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	dbm "github.com/tendermint/tendermint/libs/db"
+	"github.com/tendermint/tendermint/libs/log"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/store"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+	"github.com/cosmos/cosmos-sdk/x/params"
+
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+)
+
+// MockBackend is a Backend running an orderbook Keeper in memory, for unit tests of code using a Client.
+// Transactions go through the auth AnteHandler, so that they are signed and sequenced like on a chain,
+// and every transaction is committed in a block of its own.  Governance proposals aren't supported.
+// Every block is committed to the stores, so that they can be read at past heights with proofs
+type MockBackend struct {
+	cdc           *codec.Codec
+	ms            sdk.CommitMultiStore
+	appHashes     map[int64][]byte
+	ctx           sdk.Context
+	accountKeeper auth.AccountKeeper
+	bankKeeper    bank.Keeper
+	keeper        orderbook.Keeper
+	anteHandler   sdk.AnteHandler
+	handler       sdk.Handler
+	querier       sdk.Querier
+}
+
+var _ Backend = &MockBackend{}
+var _ StoreBackend = &MockBackend{}
+
+func NewMockBackend(cdc *codec.Codec, chainID string) *MockBackend {
+	keyAcc := sdk.NewKVStoreKey("acc")
+	keyOrderbook := sdk.NewKVStoreKey("orderbook")
+	keyFeeCollection := sdk.NewKVStoreKey("fee")
+	keyParams := sdk.NewKVStoreKey("params")
+	tkeyParams := sdk.NewTransientStoreKey("transient_params")
+
+	db := dbm.NewMemDB()
+	ms := store.NewCommitMultiStore(db)
+	ms.MountStoreWithDB(keyAcc, sdk.StoreTypeIAVL, db)
+	ms.MountStoreWithDB(keyOrderbook, sdk.StoreTypeIAVL, db)
+	ms.MountStoreWithDB(keyFeeCollection, sdk.StoreTypeIAVL, db)
+	ms.MountStoreWithDB(keyParams, sdk.StoreTypeIAVL, db)
+	ms.MountStoreWithDB(tkeyParams, sdk.StoreTypeTransient, db)
+	ms.SetPruning(sdk.PruneNothing)
+	err := ms.LoadLatestVersion()
+	if err != nil {
+		panic(err)
+	}
+
+	ctx := sdk.NewContext(ms, abci.Header{ChainID: chainID, Height: 1, Time: time.Unix(0, 0).UTC()}, false, log.NewNopLogger())
+
+	accountKeeper := auth.NewAccountKeeper(cdc, keyAcc, auth.ProtoBaseAccount)
+	bankKeeper := bank.NewBaseKeeper(accountKeeper)
+	feeCollectionKeeper := auth.NewFeeCollectionKeeper(cdc, keyFeeCollection)
+	paramsKeeper := params.NewKeeper(cdc, keyParams, tkeyParams)
+	keeper := orderbook.NewKeeper(bankKeeper, feeCollectionKeeper, nil, keyOrderbook, cdc,
+		paramsKeeper.Subspace(orderbook.DefaultParamspace), orderbook.DefaultCodespace)
+	orderbook.InitGenesis(ctx, keeper, orderbook.DefaultGenesisState())
+
+	return &MockBackend{
+		cdc:           cdc,
+		ms:            ms,
+		appHashes:     make(map[int64][]byte),
+		ctx:           ctx,
+		accountKeeper: accountKeeper,
+		bankKeeper:    bankKeeper,
+		keeper:        keeper,
+		anteHandler:   auth.NewAnteHandler(accountKeeper, feeCollectionKeeper),
+		handler:       orderbook.NewHandler(keeper),
+		querier:       orderbook.NewQuerier(keeper),
+	}
+}
+
+// Returns the Keeper and the Context of the latest block, to set up or inspect the state directly
+func (b *MockBackend) Keeper() orderbook.Keeper {
+	return b.keeper
+}
+
+func (b *MockBackend) Context() sdk.Context {
+	return b.ctx
+}
+
+// Adds coins to an account, creating it if needed
+func (b *MockBackend) Fund(address sdk.AccAddress, coins sdk.Coins) {
+	_, _, err := b.bankKeeper.AddCoins(b.ctx, address, coins)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Gets the coins of an account
+func (b *MockBackend) GetCoins(address sdk.AccAddress) sdk.Coins {
+	return b.bankKeeper.GetCoins(b.ctx, address)
+}
+
+// Lists an active market between two denoms
+func (b *MockBackend) ListMarket(baseDenom, quoteDenom string) {
+	b.keeper.ListMarket(b.ctx, orderbook.NewMarket(baseDenom, quoteDenom))
+}
+
+// Ends the current block, running the orderbook EndBlocker and committing the stores, and starts the next one
+// blockTime later
+func (b *MockBackend) NextBlock(blockTime time.Duration) {
+	orderbook.EndBlocker(b.ctx, b.keeper)
+	b.appHashes[b.ctx.BlockHeight()] = b.ms.Commit().Hash
+	header := b.ctx.BlockHeader()
+	header.Height++
+	header.Time = header.Time.Add(blockTime)
+	b.ctx = b.ctx.WithBlockHeader(header)
+}
+
+func (b *MockBackend) QueryWithData(path string, data []byte) ([]byte, error) {
+	// only the custom queries of the orderbook are served, whatever route they are made to
+	parts := strings.Split(path, "/")
+	if len(parts) < 3 || parts[0] != "custom" {
+		return nil, fmt.Errorf("unsupported query %s", path)
+	}
+	res, err := b.querier(b.ctx, parts[2:], abci.RequestQuery{Path: path, Data: data})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (b *MockBackend) QueryStore(req abci.RequestQuery) (abci.ResponseQuery, error) {
+	// raw store queries are routed like by a node, e.g. /store/orderbook/key
+	if !strings.HasPrefix(req.Path, "/store/") {
+		return abci.ResponseQuery{}, fmt.Errorf("unsupported query %s", req.Path)
+	}
+	req.Path = strings.TrimPrefix(req.Path, "/store")
+	return b.ms.(sdk.Queryable).Query(req), nil
+}
+
+// The app hashes of the mock chain are known, as it commits them itself
+func (b *MockBackend) TrustedAppHash(height int64) ([]byte, error) {
+	appHash, ok := b.appHashes[height]
+	if !ok {
+		return nil, fmt.Errorf("no block was committed at height %d", height)
+	}
+	return appHash, nil
+}
+
+func (b *MockBackend) GetAccount(address sdk.AccAddress) (auth.Account, error) {
+	account := b.accountKeeper.GetAccount(b.ctx, address)
+	if account == nil {
+		return nil, fmt.Errorf("account %s does not exist", address)
+	}
+	return account, nil
+}
+
+func (b *MockBackend) BroadcastTx(txBytes []byte) (result TxResult, err error) {
+	tx, decodeErr := auth.DefaultTxDecoder(b.cdc)(txBytes)
+	if decodeErr != nil {
+		return result, decodeErr
+	}
+
+	for _, msg := range tx.GetMsgs() {
+		if err := msg.ValidateBasic(); err != nil {
+			return result, TxError{Code: err.Code(), Codespace: err.Codespace(), Log: err.Error()}
+		}
+	}
+
+	// the fees and sequence are kept even if the msgs fail, like on a chain
+	_, res, abort := b.anteHandler(b.ctx, tx, false)
+	if abort {
+		return result, TxError{Code: res.Code, Codespace: res.Codespace, Log: res.Log}
+	}
+
+	msgCtx, write := b.ctx.CacheContext()
+	var data []byte
+	tags := sdk.EmptyTags()
+	for _, msg := range tx.GetMsgs() {
+		res := b.handler(msgCtx, msg)
+		if !res.IsOK() {
+			b.NextBlock(time.Second)
+			return result, TxError{Code: res.Code, Codespace: res.Codespace, Log: res.Log, Delivered: true}
+		}
+		data = append(data, res.Data...)
+		tags = tags.AppendTags(res.Tags)
+	}
+	write()
+
+	result = TxResult{
+		Height: b.ctx.BlockHeight(),
+		Hash:   tmhash.Sum(txBytes),
+		Data:   data,
+		Tags:   tags,
+	}
+	b.NextBlock(time.Second)
+	return result, nil
+}
@@ -0,0 +1,90 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/client/utils"
+	"github.com/cosmos/cosmos-sdk/codec"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+)
+
+func registerQueryRoutes(cliCtx context.CLIContext, r *mux.Router, cdc *codec.Codec, queryRoute string) {
+	r.HandleFunc("/orderbook/orders/{orderID}", queryOrderHandlerFn(cdc, cliCtx, queryRoute)).Methods("GET")
+	r.HandleFunc("/orderbook/orderwalls/{sellDenom}/{buyDenom}", queryPairHandlerFn(cdc, cliCtx, queryRoute, orderbook.QueryOrderwall, "")).Methods("GET")
+	r.HandleFunc("/orderbook/depth/{sellDenom}/{buyDenom}", queryPairHandlerFn(cdc, cliCtx, queryRoute, orderbook.QueryDepth, "levels")).Methods("GET")
+	r.HandleFunc("/orderbook/trades/{sellDenom}/{buyDenom}", queryPairHandlerFn(cdc, cliCtx, queryRoute, orderbook.QueryTrades, "limit")).Methods("GET")
+	r.HandleFunc("/orderbook/owners/{address}/orders", queryOwnerOrdersHandlerFn(cdc, cliCtx, queryRoute)).Methods("GET")
+}
+
+// GET /orderbook/orders/{orderID}
+func queryOrderHandlerFn(cdc *codec.Codec, cliCtx context.CLIContext, queryRoute string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		orderID, err := strconv.ParseInt(mux.Vars(r)["orderID"], 10, 64)
+		if err != nil {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, "orderID must be an integer")
+			return
+		}
+
+		res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s/%d", queryRoute, orderbook.QueryOrder, orderID), nil)
+		if err != nil {
+			utils.WriteErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		utils.PostProcessResponse(w, cdc, res, cliCtx.Indent)
+	}
+}
+
+// GET /orderbook/{endpoint}/{sellDenom}/{buyDenom}, with an optional limit taken from the limitParam query parameter
+func queryPairHandlerFn(cdc *codec.Codec, cliCtx context.CLIContext, queryRoute string, endpoint string, limitParam string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		pair := orderbook.NewDenomPair(vars["sellDenom"], vars["buyDenom"])
+		path := fmt.Sprintf("custom/%s/%s/%s", queryRoute, endpoint, pair.String())
+
+		if limitParam != "" {
+			if limitStr := r.URL.Query().Get(limitParam); limitStr != "" {
+				limit, err := strconv.ParseInt(limitStr, 10, 64)
+				if err != nil || limit <= 0 {
+					utils.WriteErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("%s must be a positive integer", limitParam))
+					return
+				}
+				path = fmt.Sprintf("%s/%d", path, limit)
+			}
+		}
+
+		res, err := cliCtx.QueryWithData(path, nil)
+		if err != nil {
+			utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		utils.PostProcessResponse(w, cdc, res, cliCtx.Indent)
+	}
+}
+
+// GET /orderbook/owners/{address}/orders
+func queryOwnerOrdersHandlerFn(cdc *codec.Codec, cliCtx context.CLIContext, queryRoute string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		owner, err := sdk.AccAddressFromBech32(mux.Vars(r)["address"])
+		if err != nil {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s/%s", queryRoute, orderbook.QueryOwnerOrders, owner), nil)
+		if err != nil {
+			utils.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		utils.PostProcessResponse(w, cdc, res, cliCtx.Indent)
+	}
+}
@@ -0,0 +1,16 @@
+package rest
+
+import (
+	"github.com/gorilla/mux"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/crypto/keys"
+)
+
+// RegisterRoutes registers the orderbook REST routes: queries of orders, orderwalls, depth, trades and owner orders,
+// and building and broadcasting MakeOrder and RemoveOrder transactions
+func RegisterRoutes(cliCtx context.CLIContext, r *mux.Router, cdc *codec.Codec, kb keys.Keybase, queryRoute string) {
+	registerQueryRoutes(cliCtx, r, cdc, queryRoute)
+	registerTxRoutes(cliCtx, r, cdc, kb)
+}
@@ -0,0 +1,133 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/client/utils"
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/crypto/keys"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+)
+
+func registerTxRoutes(cliCtx context.CLIContext, r *mux.Router, cdc *codec.Codec, kb keys.Keybase) {
+	r.HandleFunc("/orderbook/orders", makeOrderHandlerFn(cdc, kb, cliCtx)).Methods("POST")
+	r.HandleFunc("/orderbook/orders/{orderID}/remove", removeOrderHandlerFn(cdc, kb, cliCtx)).Methods("POST")
+	r.HandleFunc("/orderbook/client-orders/{clientOrderID}/remove", removeOrderHandlerFn(cdc, kb, cliCtx)).Methods("POST")
+}
+
+// makeOrderReq is the body of a MakeOrder request.  Amounts are in base units of the denoms,
+// and Price is the ratio of BuyDenom to the denom of SellCoins
+type makeOrderReq struct {
+	BaseReq             utils.BaseReq `json:"base_req"`
+	ClientOrderID       string        `json:"client_order_id"`
+	SellCoins           sdk.Coin      `json:"sell_coins"`
+	BuyDenom            string        `json:"buy_denom"`
+	Price               sdk.Dec       `json:"price"`
+	ExpirationTime      time.Time     `json:"expiration_time"`
+	DisplayAmount       sdk.Int       `json:"display_amount"`
+	SelfTradePrevention string        `json:"self_trade_prevention"`
+}
+
+type removeOrderReq struct {
+	BaseReq utils.BaseReq `json:"base_req"`
+}
+
+// POST /orderbook/orders
+func makeOrderHandlerFn(cdc *codec.Codec, kb keys.Keybase, cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req makeOrderReq
+		err := utils.ReadRESTReq(w, r, cdc, &req)
+		if err != nil {
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		info, err := kb.Get(baseReq.Name)
+		if err != nil {
+			utils.WriteErrorResponse(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		stpStr := req.SelfTradePrevention
+		if stpStr == "" {
+			stpStr = "none"
+		}
+		stp, err := orderbook.SelfTradePreventionFromStr(stpStr)
+		if err != nil {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		displayAmount := req.DisplayAmount
+		if displayAmount == (sdk.Int{}) {
+			displayAmount = sdk.ZeroInt()
+		}
+
+		price := orderbook.NewPrice(req.Price, req.BuyDenom, req.SellCoins.Denom)
+		msg := orderbook.NewMsgMakeOrder(sdk.AccAddress(info.GetPubKey().Address()), req.ClientOrderID,
+			req.SellCoins, price, req.ExpirationTime, displayAmount, stp)
+		err = msg.ValidateBasic()
+		if err != nil {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		utils.CompleteAndBroadcastTxREST(w, r, cliCtx, baseReq, []sdk.Msg{msg}, cdc)
+	}
+}
+
+// POST /orderbook/orders/{orderID}/remove and /orderbook/client-orders/{clientOrderID}/remove
+func removeOrderHandlerFn(cdc *codec.Codec, kb keys.Keybase, cliCtx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		var req removeOrderReq
+		err := utils.ReadRESTReq(w, r, cdc, &req)
+		if err != nil {
+			return
+		}
+
+		baseReq := req.BaseReq.Sanitize()
+		if !baseReq.ValidateBasic(w) {
+			return
+		}
+
+		info, err := kb.Get(baseReq.Name)
+		if err != nil {
+			utils.WriteErrorResponse(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		owner := sdk.AccAddress(info.GetPubKey().Address())
+
+		var msg orderbook.MsgRemoveOrder
+		if clientOrderID, ok := vars["clientOrderID"]; ok {
+			msg = orderbook.NewMsgRemoveOrderByClientOrderID(owner, clientOrderID)
+		} else {
+			orderID, err := strconv.ParseInt(vars["orderID"], 10, 64)
+			if err != nil {
+				utils.WriteErrorResponse(w, http.StatusBadRequest, "orderID must be an integer")
+				return
+			}
+			msg = orderbook.NewMsgRemoveOrder(owner, orderID)
+		}
+
+		err = msg.ValidateBasic()
+		if err != nil {
+			utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		utils.CompleteAndBroadcastTxREST(w, r, cliCtx, baseReq, []sdk.Msg{msg}, cdc)
+	}
+}
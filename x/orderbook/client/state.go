@@ -0,0 +1,237 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/tendermint/iavl"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/merkle"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/store"
+
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+)
+
+// StoreBackend is a chain whose stores can be read key by key at past heights, with Merkle proofs of what was read
+type StoreBackend interface {
+	// runs a query of a raw store, like /store/orderbook/key, at req.Height.  Height 0 is the latest height with a proof
+	QueryStore(req abci.RequestQuery) (abci.ResponseQuery, error)
+	// returns the app hash of the state committed at a height, from a header that was verified against the validators
+	TrustedAppHash(height int64) ([]byte, error)
+}
+
+// ProvenState reads the orderbook as it was at a height, straight from the keys of its store, as nodes only serve
+// the custom queries of the orderbook from their latest state.
+// Unless the node is trusted, every key read comes with a Merkle proof of its value, or of its absence, that is checked
+// against the app hash of a verified header.  Orderwalls are walked key by key using the neighbours of the keys proven
+// absent, so a node can't make up orders nor leave any out
+type ProvenState struct {
+	backend      StoreBackend
+	cdc          *codec.Codec
+	storeName    string
+	height       int64
+	trustNode    bool
+	proofRuntime *merkle.ProofRuntime
+	appHash      []byte
+}
+
+// Creates a ProvenState reading the orderbook store called storeName at height, or at the latest height with a
+// proof if height is 0
+func NewProvenState(backend StoreBackend, cdc *codec.Codec, storeName string, height int64, trustNode bool) *ProvenState {
+	return &ProvenState{
+		backend:      backend,
+		cdc:          cdc,
+		storeName:    storeName,
+		height:       height,
+		trustNode:    trustNode,
+		proofRuntime: store.DefaultProofRuntime(),
+	}
+}
+
+// Returns the height the state is read at, which is only known after the first read if it was created with height 0
+func (s *ProvenState) Height() int64 {
+	return s.height
+}
+
+// Gets an order, resting or closed, like the order query
+func (s *ProvenState) Order(orderID int64) (order orderbook.Order, err error) {
+	for _, key := range [][]byte{orderbook.OrderKey(orderID), orderbook.OrderHistoryKey(orderID)} {
+		found, err := s.getObject(key, &order)
+		if err != nil {
+			return order, err
+		}
+		if found {
+			return order.Visible(), nil
+		}
+	}
+	return order, fmt.Errorf("order %d not found at height %d", orderID, s.height)
+}
+
+// Gets the orders resting in the orderwall of a pair, like the orderwall query
+func (s *ProvenState) Orderwall(pair orderbook.DenomPair) (orders []orderbook.Order, err error) {
+	err = s.iterateOrderwall(pair, func(order orderbook.Order) bool {
+		orders = append(orders, order.Visible())
+		return false
+	})
+	return orders, err
+}
+
+// Gets up to levels price levels of each side of the book of a pair, like the depth query
+func (s *ProvenState) Depth(pair orderbook.DenomPair, levels int64) (depth orderbook.Depth, err error) {
+	depth.Pair = pair
+	err = s.iterateOrderwall(pair, func(order orderbook.Order) bool {
+		var added bool
+		depth.Asks, added = orderbook.AddToPriceLevels(depth.Asks, order, levels, false)
+		return !added
+	})
+	if err != nil {
+		return depth, err
+	}
+	err = s.iterateOrderwall(pair.ReversePair(), func(order orderbook.Order) bool {
+		var added bool
+		depth.Bids, added = orderbook.AddToPriceLevels(depth.Bids, order, levels, true)
+		return !added
+	})
+	return depth, err
+}
+
+// Iterates over the orders of an orderwall by price, until handler returns true
+func (s *ProvenState) iterateOrderwall(pair orderbook.DenomPair, handler func(order orderbook.Order) (stop bool)) error {
+	prefix := orderbook.OrderwallPrefix(pair)
+	key := prefix
+	for {
+		next, value, found, err := s.seek(key)
+		if err != nil {
+			return err
+		}
+		if !found || !bytes.HasPrefix(next, prefix) {
+			return nil
+		}
+
+		var orderID int64
+		err = s.cdc.UnmarshalBinaryBare(value, &orderID)
+		if err != nil {
+			return err
+		}
+		var order orderbook.Order
+		found, err = s.getObject(orderbook.OrderKey(orderID), &order)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("order %d is in the orderwall of %s but not in the store at height %d", orderID, pair, s.height)
+		}
+		if handler(order) {
+			return nil
+		}
+
+		// the smallest key after next
+		key = append(append([]byte{}, next...), 0x00)
+	}
+}
+
+// Gets the value of a key and decodes it into ptr.  Returns false if there's no value
+func (s *ProvenState) getObject(key []byte, ptr interface{}) (found bool, err error) {
+	res, err := s.query(key)
+	if err != nil {
+		return false, err
+	}
+	if res.Value == nil {
+		return false, nil
+	}
+	return true, s.cdc.UnmarshalBinaryBare(res.Value, ptr)
+}
+
+// Returns the first key at or after key, and its value.  Returns false if there are no keys left in the store
+func (s *ProvenState) seek(key []byte) (next []byte, value []byte, found bool, err error) {
+	res, err := s.query(key)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if res.Value != nil {
+		return key, res.Value, true, nil
+	}
+
+	// the proof of absence of a key is made of the leaves around it, which were checked to be next to each other
+	op, err := iavl.IAVLAbsenceOpDecoder(res.Proof.Ops[0])
+	if err != nil {
+		return nil, nil, false, err
+	}
+	absence, ok := op.(iavl.IAVLAbsenceOp)
+	if !ok || absence.Proof == nil {
+		return nil, nil, false, fmt.Errorf("proof of absence of key %X has no leaves", key)
+	}
+	for _, leaf := range absence.Proof.Leaves {
+		if bytes.Compare(leaf.Key, key) > 0 {
+			res, err = s.query(leaf.Key)
+			if err != nil {
+				return nil, nil, false, err
+			}
+			if res.Value == nil {
+				return nil, nil, false, fmt.Errorf("key %X is in the proof of absence of key %X but has no value", leaf.Key, key)
+			}
+			return leaf.Key, res.Value, true, nil
+		}
+	}
+	return nil, nil, false, nil
+}
+
+// Reads a key of the store with its proof, and checks the proof unless the node is trusted.
+// The first read fixes the height the following ones are made at
+func (s *ProvenState) query(key []byte) (res abci.ResponseQuery, err error) {
+	res, err = s.backend.QueryStore(abci.RequestQuery{
+		Path:   fmt.Sprintf("/store/%s/key", s.storeName),
+		Data:   key,
+		Height: s.height,
+		Prove:  true,
+	})
+	if err != nil {
+		return res, err
+	}
+	if !res.IsOK() {
+		return res, fmt.Errorf("query of key %X failed: %s", key, res.Log)
+	}
+	if res.Proof == nil || len(res.Proof.Ops) == 0 {
+		return res, fmt.Errorf("query of key %X at height %d has no proof: %s", key, s.height, res.Log)
+	}
+
+	if s.height == 0 {
+		s.height = res.Height
+	}
+	if res.Height != s.height {
+		return res, fmt.Errorf("query of key %X was answered at height %d instead of %d", key, res.Height, s.height)
+	}
+	if s.trustNode {
+		return res, nil
+	}
+
+	return res, s.verify(key, res)
+}
+
+// Checks the proof of the value of a key, or of its absence, against the app hash of the state read
+func (s *ProvenState) verify(key []byte, res abci.ResponseQuery) error {
+	if s.appHash == nil {
+		appHash, err := s.backend.TrustedAppHash(s.height)
+		if err != nil {
+			return err
+		}
+		s.appHash = appHash
+	}
+
+	keyPath := merkle.KeyPath{}
+	keyPath = keyPath.AppendKey([]byte(s.storeName), merkle.KeyEncodingURL)
+	keyPath = keyPath.AppendKey(key, merkle.KeyEncodingURL)
+
+	var err error
+	if res.Value == nil {
+		err = s.proofRuntime.VerifyAbsence(res.Proof, s.appHash, keyPath.String())
+	} else {
+		err = s.proofRuntime.VerifyValue(res.Proof, s.appHash, keyPath.String(), res.Value)
+	}
+	if err != nil {
+		return fmt.Errorf("proof of key %X at height %d is invalid: %v", key, s.height, err)
+	}
+	return nil
+}
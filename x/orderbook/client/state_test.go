@@ -0,0 +1,215 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+)
+
+// tamperingBackend is a node that changes its answers to some of the raw store queries made to it
+type tamperingBackend struct {
+	*MockBackend
+	tamper func(req abci.RequestQuery, res *abci.ResponseQuery)
+}
+
+func (b tamperingBackend) QueryStore(req abci.RequestQuery) (abci.ResponseQuery, error) {
+	res, err := b.MockBackend.QueryStore(req)
+	if err == nil {
+		b.tamper(req, &res)
+	}
+	return res, err
+}
+
+// requires two results to be the same once written as JSON, as values decoded from JSON and from the store
+// can differ in how they hold the same numbers
+func requireSameJSON(t *testing.T, cdc *codec.Codec, expected interface{}, actual interface{}) {
+	require.Equal(t, string(cdc.MustMarshalJSON(expected)), string(cdc.MustMarshalJSON(actual)))
+}
+
+// makes a book with resting, partially filled and closed orders, then changes it in later blocks.
+// Returns the height of the book before the changes, and the orders 1 and 2 of bob resting in it then
+func createBookHistory(t *testing.T) (backend *MockBackend, clients []*Client, height int64, bobOrders []orderbook.Order) {
+	backend, clients = createTestClients(t, "alice", "bob")
+	alice, bob := clients[0], clients[1]
+
+	for i := int64(0); i < 3; i++ {
+		_, err := bob.PlaceOrder(sellOrder(10, "BTC", 2+i, "ETH"))
+		require.NoError(t, err)
+	}
+	// alice rests a bid, and takes 2 BTC of bob's first order with an order that's closed right away
+	_, err := alice.PlaceOrder(Order{SellCoins: sdk.NewInt64Coin("ETH", 10), BuyDenom: "BTC", Price: sdk.NewDecWithPrec(8, 1)})
+	require.NoError(t, err)
+	result, err := alice.PlaceOrder(Order{SellCoins: sdk.NewInt64Coin("ETH", 4), BuyDenom: "BTC", Price: sdk.NewDecWithPrec(5, 1)})
+	require.NoError(t, err)
+	require.True(t, result.Consumed)
+
+	// every transaction is committed in a block of its own, and the next block is empty so far
+	height = backend.Context().BlockHeight() - 1
+	for _, orderID := range []int64{1, 2} {
+		order, found := backend.Keeper().GetOrder(backend.Context(), orderID)
+		require.True(t, found)
+		bobOrders = append(bobOrders, order)
+	}
+	return backend, clients, height, bobOrders
+}
+
+// changes the book of createBookHistory: bob's second order is cancelled and alice takes the rest of the first one
+func changeBook(t *testing.T, clients []*Client) {
+	alice, bob := clients[0], clients[1]
+	require.NoError(t, bob.CancelOrder(2))
+	result, err := alice.PlaceOrder(Order{SellCoins: sdk.NewInt64Coin("ETH", 16), BuyDenom: "BTC", Price: sdk.NewDecWithPrec(5, 1)})
+	require.NoError(t, err)
+	require.True(t, result.Consumed)
+}
+
+func TestProvenStateAtPastHeight(t *testing.T) {
+	backend, clients, height, _ := createBookHistory(t)
+	alice := clients[0]
+	pair := orderbook.NewDenomPair("BTC", "ETH")
+
+	// what the custom queries answer at the height, before the book changes
+	var orders []orderbook.Order
+	for orderID := int64(1); orderID <= 5; orderID++ {
+		order, err := alice.Order(orderID)
+		require.NoError(t, err)
+		orders = append(orders, order)
+	}
+	asks, err := alice.Orderwall(pair)
+	require.NoError(t, err)
+	require.Len(t, asks, 3)
+	bids, err := alice.Orderwall(pair.ReversePair())
+	require.NoError(t, err)
+	require.Len(t, bids, 1)
+	depth, err := alice.Depth(pair, 10)
+	require.NoError(t, err)
+	topOfBook, err := alice.Depth(pair, 1)
+	require.NoError(t, err)
+
+	changeBook(t, clients)
+	changedAsks, err := alice.Orderwall(pair)
+	require.NoError(t, err)
+	require.Len(t, changedAsks, 1)
+
+	// the state at the height is read with proofs checked, and also the same when the node is trusted
+	for _, trustNode := range []bool{false, true} {
+		state := NewProvenState(backend, backend.cdc, "orderbook", height, trustNode)
+		for i, expected := range orders {
+			order, err := state.Order(expected.OrderID)
+			require.NoError(t, err, "order %d", i+1)
+			requireSameJSON(t, backend.cdc, expected, order)
+		}
+		provenAsks, err := state.Orderwall(pair)
+		require.NoError(t, err)
+		requireSameJSON(t, backend.cdc, asks, provenAsks)
+		provenBids, err := state.Orderwall(pair.ReversePair())
+		require.NoError(t, err)
+		requireSameJSON(t, backend.cdc, bids, provenBids)
+		provenDepth, err := state.Depth(pair, 10)
+		require.NoError(t, err)
+		requireSameJSON(t, backend.cdc, depth, provenDepth)
+		provenTopOfBook, err := state.Depth(pair, 1)
+		require.NoError(t, err)
+		requireSameJSON(t, backend.cdc, topOfBook, provenTopOfBook)
+
+		// orders that didn't exist yet, or never did, are proven absent
+		_, err = state.Order(6)
+		require.Error(t, err)
+		_, err = state.Order(1000)
+		require.Error(t, err)
+
+		// a pair without orders has an empty orderwall
+		empty, err := state.Orderwall(orderbook.NewDenomPair("BTC", "XRP"))
+		require.NoError(t, err)
+		require.Empty(t, empty)
+	}
+
+	// without a height, the state is read at the height before the latest block, as the app hash of the latest block
+	// is only in the header of the next one
+	backend.NextBlock(time.Second)
+	state := NewProvenState(backend, backend.cdc, "orderbook", 0, false)
+	latestAsks, err := state.Orderwall(pair)
+	require.NoError(t, err)
+	requireSameJSON(t, backend.cdc, changedAsks, latestAsks)
+	require.Equal(t, backend.Context().BlockHeight()-2, state.Height())
+}
+
+func TestProvenStateCatchesTampering(t *testing.T) {
+	backend, clients, height, bobOrders := createBookHistory(t)
+	changeBook(t, clients)
+	pair := orderbook.NewDenomPair("BTC", "ETH")
+	firstWallKey := orderbook.NewOrderwallKey(bobOrders[0]).Bytes()
+	secondWallKey := orderbook.NewOrderwallKey(bobOrders[1]).Bytes()
+
+	// answers a query with the answer for another key
+	answerFor := func(key []byte, req abci.RequestQuery, res *abci.ResponseQuery) {
+		req.Data = key
+		*res, _ = backend.QueryStore(req)
+	}
+
+	tests := []struct {
+		name       string
+		tamper     func(req abci.RequestQuery, res *abci.ResponseQuery)
+		read       func(state *ProvenState) error
+		trustedErr bool
+	}{
+		{"changed order", func(req abci.RequestQuery, res *abci.ResponseQuery) {
+			if string(req.Data) == string(orderbook.OrderKey(2)) {
+				var order orderbook.Order
+				backend.cdc.MustUnmarshalBinaryBare(res.Value, &order)
+				order.SellCoins = sdk.NewInt64Coin("BTC", 1)
+				res.Value = backend.cdc.MustMarshalBinaryBare(order)
+			}
+		}, func(state *ProvenState) error {
+			_, err := state.Order(2)
+			return err
+		}, false},
+		{"order hidden with a proof of another key", func(req abci.RequestQuery, res *abci.ResponseQuery) {
+			if string(req.Data) == string(orderbook.OrderKey(2)) {
+				answerFor(orderbook.OrderKey(1000), req, res)
+			}
+		}, func(state *ProvenState) error {
+			_, err := state.Order(2)
+			return err
+		}, true},
+		{"orderwall walked with a proof of another key", func(req abci.RequestQuery, res *abci.ResponseQuery) {
+			if string(req.Data) == string(append(firstWallKey, 0x00)) {
+				answerFor(append(secondWallKey, 0x00), req, res)
+			}
+		}, func(state *ProvenState) error {
+			_, err := state.Orderwall(pair)
+			return err
+		}, false},
+		{"answer at another height", func(req abci.RequestQuery, res *abci.ResponseQuery) {
+			if string(req.Data) == string(orderbook.OrderKey(2)) {
+				req.Height--
+				*res, _ = backend.QueryStore(req)
+			}
+		}, func(state *ProvenState) error {
+			_, err := state.Order(2)
+			return err
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampering := tamperingBackend{backend, tt.tamper}
+			require.Error(t, tt.read(NewProvenState(tampering, backend.cdc, "orderbook", height, false)))
+
+			// a trusted node is believed, as long as it answers at the height asked for
+			err := tt.read(NewProvenState(tampering, backend.cdc, "orderbook", height, true))
+			require.Equal(t, tt.trustedErr, err != nil, "%v", err)
+		})
+	}
+
+	state := NewProvenState(tamperingBackend{backend, tests[0].tamper}, backend.cdc, "orderbook", height, true)
+	order, err := state.Order(2)
+	require.NoError(t, err)
+	require.True(t, order.SellCoins.IsEqual(sdk.NewInt64Coin("BTC", 1)))
+}
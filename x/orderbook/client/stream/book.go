@@ -0,0 +1,133 @@
+package stream
+
+import (
+	"sort"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+)
+
+// Book is the local book of a market: the orders resting on each side, and the L2 price levels published for them
+type Book struct {
+	Market orderbook.DenomPair
+	Height int64
+	orders map[int64]orderbook.Order
+	asks   map[string]orderbook.PriceLevel
+	bids   map[string]orderbook.PriceLevel
+}
+
+func NewBook(market orderbook.DenomPair) *Book {
+	return &Book{
+		Market: market,
+		orders: make(map[int64]orderbook.Order),
+		asks:   make(map[string]orderbook.PriceLevel),
+		bids:   make(map[string]orderbook.PriceLevel),
+	}
+}
+
+// Returns whether an order rests on either side of the book's market
+func (b *Book) InMarket(order orderbook.Order) bool {
+	pair := order.Pair()
+	return pair == b.Market || pair == b.Market.ReversePair()
+}
+
+// Adds a resting order to the book, or replaces it with its new state
+func (b *Book) SetOrder(order orderbook.Order) {
+	b.orders[order.OrderID] = order
+}
+
+// Removes an order that no longer rests in the book
+func (b *Book) RemoveOrder(orderID int64) {
+	delete(b.orders, orderID)
+}
+
+// Returns the IDs of the orders resting in the book, or only of those of owner if it isn't nil
+func (b *Book) OrderIDs(owner sdk.AccAddress) (orderIDs []int64) {
+	for orderID, order := range b.orders {
+		if owner == nil || order.Owner.Equals(owner) {
+			orderIDs = append(orderIDs, orderID)
+		}
+	}
+	return orderIDs
+}
+
+// Aggregates the orders of the book into price levels as of height.
+// Returns the price levels that changed on each side, with an amount of zero for the levels that were removed
+func (b *Book) Update(height int64) (asks []orderbook.PriceLevel, bids []orderbook.PriceLevel) {
+	b.Height = height
+
+	askLevels := make(map[string]orderbook.PriceLevel)
+	bidLevels := make(map[string]orderbook.PriceLevel)
+	for _, order := range b.orders {
+		// the bids are converted to the units of the asks, like in the depth query
+		levels, opposing := askLevels, false
+		if order.Pair() != b.Market {
+			levels, opposing = bidLevels, true
+		}
+		priceLevels, _ := orderbook.AddToPriceLevels(nil, order, 1, opposing)
+		addLevel(levels, priceLevels[0])
+	}
+
+	return applySide(b.asks, askLevels), applySide(b.bids, bidLevels)
+}
+
+// Returns the price levels of the book, asks lowest price first and bids highest price first
+func (b *Book) Snapshot() orderbook.Depth {
+	return orderbook.Depth{
+		Pair: b.Market,
+		Asks: sortedLevels(b.asks, false),
+		Bids: sortedLevels(b.bids, true),
+	}
+}
+
+// Adds the amount of a level to the level of the same price
+func addLevel(levels map[string]orderbook.PriceLevel, level orderbook.PriceLevel) {
+	key := level.Price.String()
+	if old, found := levels[key]; found {
+		level.Amount = level.Amount.Add(old.Amount)
+	}
+	levels[key] = level
+}
+
+// Updates one side of the book to levels, returning the levels that changed
+func applySide(side map[string]orderbook.PriceLevel, levels map[string]orderbook.PriceLevel) (changed []orderbook.PriceLevel) {
+	for key, level := range levels {
+		old, found := side[key]
+		if found && old.Amount.Equal(level.Amount) {
+			continue
+		}
+		side[key] = level
+		changed = append(changed, level)
+	}
+
+	for key, old := range side {
+		if _, found := levels[key]; found {
+			continue
+		}
+		delete(side, key)
+		changed = append(changed, orderbook.PriceLevel{Price: old.Price, Amount: sdk.ZeroInt()})
+	}
+
+	sortLevels(changed, false)
+	return changed
+}
+
+// Returns the levels of one side of the book sorted by price
+func sortedLevels(side map[string]orderbook.PriceLevel, descending bool) (levels []orderbook.PriceLevel) {
+	for _, level := range side {
+		levels = append(levels, level)
+	}
+	sortLevels(levels, descending)
+	return levels
+}
+
+// Sorts price levels by price
+func sortLevels(levels []orderbook.PriceLevel, descending bool) {
+	sort.Slice(levels, func(i, j int) bool {
+		if descending {
+			return levels[i].Price.GT(levels[j].Price)
+		}
+		return levels[i].Price.LT(levels[j].Price)
+	})
+}
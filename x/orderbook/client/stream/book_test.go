@@ -0,0 +1,67 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+)
+
+var (
+	alice = sdk.AccAddress([]byte("alice"))
+	bob   = sdk.AccAddress([]byte("bob"))
+)
+
+func order(orderID int64, owner sdk.AccAddress, amount int64, sellDenom string, ratio sdk.Dec, buyDenom string) orderbook.Order {
+	return orderbook.Order{
+		OrderID:   orderID,
+		Owner:     owner,
+		SellCoins: sdk.NewInt64Coin(sellDenom, amount),
+		BuyDenom:  buyDenom,
+		Price:     orderbook.NewPrice(ratio, buyDenom, sellDenom),
+	}
+}
+
+func level(price int64, amount int64) orderbook.PriceLevel {
+	return orderbook.PriceLevel{Price: sdk.NewDec(price), Amount: sdk.NewInt(amount)}
+}
+
+func requireLevels(t *testing.T, expected []orderbook.PriceLevel, actual []orderbook.PriceLevel) {
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		require.True(t, expected[i].Price.Equal(actual[i].Price), "price %s, expected %s", actual[i].Price, expected[i].Price)
+		require.True(t, expected[i].Amount.Equal(actual[i].Amount), "amount %s, expected %s", actual[i].Amount, expected[i].Amount)
+	}
+}
+
+func TestBookUpdate(t *testing.T) {
+	book := NewBook(orderbook.NewDenomPair("BTC", "ETH"))
+	require.False(t, book.InMarket(order(1, alice, 100, "ETH", sdk.NewDec(2), "LTC")))
+
+	book.SetOrder(order(1, alice, 100, "BTC", sdk.NewDec(2), "ETH"))
+	book.SetOrder(order(2, bob, 50, "BTC", sdk.NewDec(3), "ETH"))
+	book.SetOrder(order(3, bob, 20, "BTC", sdk.NewDec(2), "ETH"))
+	// the bids are in units of the asks: 20 ETH at 0.5 BTC/ETH are 10 BTC at 2 ETH/BTC
+	book.SetOrder(order(4, alice, 20, "ETH", sdk.NewDecWithPrec(5, 1), "BTC"))
+
+	asks, bids := book.Update(1)
+	requireLevels(t, []orderbook.PriceLevel{level(2, 120), level(3, 50)}, asks)
+	requireLevels(t, []orderbook.PriceLevel{level(2, 10)}, bids)
+	require.Len(t, book.OrderIDs(nil), 4)
+	require.ElementsMatch(t, []int64{2, 3}, book.OrderIDs(bob))
+
+	// only the changed levels are returned, and removed levels have an amount of zero
+	book.SetOrder(order(1, alice, 60, "BTC", sdk.NewDec(2), "ETH"))
+	book.RemoveOrder(2)
+	asks, bids = book.Update(2)
+	requireLevels(t, []orderbook.PriceLevel{level(2, 80), level(3, 0)}, asks)
+	require.Empty(t, bids)
+
+	snapshot := book.Snapshot()
+	requireLevels(t, []orderbook.PriceLevel{level(2, 80)}, snapshot.Asks)
+	requireLevels(t, []orderbook.PriceLevel{level(2, 10)}, snapshot.Bids)
+	require.Equal(t, int64(2), book.Height)
+}
@@ -0,0 +1,43 @@
+package stream
+
+import (
+	"errors"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/tendermint/tendermint/libs/log"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/codec"
+)
+
+const flagListenAddr = "laddr"
+
+// GetCmdStream is the CLI command serving the market data of the orderbook over WebSockets
+func GetCmdStream(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stream",
+		Short: "Stream the books and trades of markets over WebSockets",
+		Long: `Follow the blocks of a node and stream the books and trades of markets over WebSockets.
+Connecting to ws://<laddr>/stream/<baseDenom>/<quoteDenom> sends a snapshot of the book of the market,
+followed by the price levels that changed and the trades made in every block that touched the market.
+The orders changed by every block are read from the store of the node at its height, so the node must be trusted.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+			if !cliCtx.TrustNode {
+				return errors.New("the stream reads the store of the node without proofs, run it with --trust-node")
+			}
+			logger := log.NewTMLogger(log.NewSyncWriter(os.Stdout))
+
+			// the store of the orderbook is named after its query route
+			server := NewServer(cliCtx, cdc, queryRoute, queryRoute, logger)
+			return server.Serve(viper.GetString(flagListenAddr))
+		},
+	}
+	cmd.Flags().String(flagListenAddr, "localhost:1319", "The address to serve the WebSocket stream on")
+	return client.GetCommands(cmd)[0]
+}
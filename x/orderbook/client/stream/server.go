@@ -0,0 +1,429 @@
+package stream
+
+import (
+	gocontext "context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+
+	cmn "github.com/tendermint/tendermint/libs/common"
+	"github.com/tendermint/tendermint/libs/log"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	"github.com/tendermint/tendermint/state"
+	tmtypes "github.com/tendermint/tendermint/types"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/codec"
+
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+)
+
+// Types of the messages sent to subscribers
+const (
+	// the full book of the market, sent once when subscribing
+	MessageSnapshot = "snapshot"
+	// the price levels that changed since the last message, with an amount of zero for removed levels
+	MessageDepth = "depth"
+	// a fill in the market
+	MessageTrade = "trade"
+)
+
+// number of messages buffered for a subscriber before it's dropped as too slow
+const subscriberBuffer = 256
+
+// Message is sent over the WebSocket to the subscribers of a market
+type Message struct {
+	Type   string                 `json:"type"`
+	Market orderbook.DenomPair    `json:"market"`
+	Height int64                  `json:"height"`
+	Asks   []orderbook.PriceLevel `json:"asks,omitempty"`
+	Bids   []orderbook.PriceLevel `json:"bids,omitempty"`
+	Trade  *orderbook.Trade       `json:"trade,omitempty"`
+}
+
+// Server follows the blocks of a node and re-publishes the changes of the markets subscribed to over WebSockets.
+// A subscriber first receives a snapshot of the book and then the deltas and trades of every block that touched it.
+// Books are kept order by order: every block re-reads the orders its tags touched from the store at its height
+type Server struct {
+	cliCtx     context.CLIContext
+	cdc        *codec.Codec
+	queryRoute string
+	storeName  string
+	logger     log.Logger
+
+	mtx         sync.Mutex
+	books       map[string]*Book
+	subscribers map[string]map[*subscriber]bool
+}
+
+type subscriber struct {
+	conn *websocket.Conn
+	send chan []byte
+}
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// Creates a Server reading the custom queries of the orderbook at queryRoute and its raw store called storeName.
+// The node must be trusted, as the orders changed by a block are read from the store without proofs
+func NewServer(cliCtx context.CLIContext, cdc *codec.Codec, queryRoute string, storeName string, logger log.Logger) *Server {
+	return &Server{
+		cliCtx:      cliCtx,
+		cdc:         cdc,
+		queryRoute:  queryRoute,
+		storeName:   storeName,
+		logger:      logger,
+		books:       make(map[string]*Book),
+		subscribers: make(map[string]map[*subscriber]bool),
+	}
+}
+
+// Subscribes to the new blocks of the node and serves the WebSocket stream at laddr
+func (s *Server) Serve(laddr string) error {
+	node, err := s.cliCtx.GetNode()
+	if err != nil {
+		return err
+	}
+	err = node.Start()
+	if err != nil {
+		return err
+	}
+
+	headers := make(chan interface{}, 100)
+	err = node.Subscribe(gocontext.Background(), "dextercli-stream", tmtypes.EventQueryNewBlockHeader, headers)
+	if err != nil {
+		return err
+	}
+	go s.followBlocks(node, headers)
+
+	r := mux.NewRouter()
+	r.HandleFunc("/stream/{baseDenom}/{quoteDenom}", s.handleSubscribe)
+	return http.ListenAndServe(laddr, r)
+}
+
+// Updates the books touched by every new block
+func (s *Server) followBlocks(node rpcclient.Client, headers <-chan interface{}) {
+	for event := range headers {
+		header, ok := event.(tmtypes.EventDataNewBlockHeader)
+		if !ok {
+			continue
+		}
+		height := header.Header.Height
+
+		results, err := node.BlockResults(&height)
+		if err != nil {
+			s.logger.Error(fmt.Sprintf("could not get the results of block %d: %s", height, err))
+			continue
+		}
+		changes := newBlockChanges(results.Results)
+
+		s.mtx.Lock()
+		for key, book := range s.books {
+			// books loaded after the block was committed already include it
+			if !changes.markets[marketString(book.Market)] || book.Height >= height {
+				continue
+			}
+			err = s.updateBook(key, book, changes, height)
+			if err != nil {
+				s.logger.Error(fmt.Sprintf("could not update the book of %s at height %d: %s", key, height, err))
+			}
+		}
+		s.mtx.Unlock()
+	}
+}
+
+// blockChanges are what the tags of the txs and of the EndBlocker of a block say it changed in the orderbook
+type blockChanges struct {
+	// markets that were changed
+	markets map[string]bool
+	// orders that were made, cancelled or expired
+	orderIDs []int64
+	// orders that were made, whose owners' resting orders may have been changed by self-trade prevention
+	madeOrderIDs []int64
+	// trades that were made, by orders in the txs and by TWAP slices in the EndBlocker
+	tradeIDs []int64
+	// whether a proposal was applied, like delisting a market, which may have cancelled any order of its markets
+	proposalApplied bool
+}
+
+func newBlockChanges(results *state.ABCIResponses) blockChanges {
+	changes := blockChanges{markets: make(map[string]bool)}
+
+	var tags []cmn.KVPair
+	for _, deliverTx := range results.DeliverTx {
+		tags = append(tags, deliverTx.Tags...)
+	}
+	if results.EndBlock != nil {
+		tags = append(tags, results.EndBlock.Tags...)
+	}
+
+	for _, tag := range tags {
+		key, value := string(tag.Key), string(tag.Value)
+		switch key {
+		case orderbook.TagMarket:
+			changes.markets[value] = true
+		case orderbook.TagAppliedProposalID:
+			changes.proposalApplied = true
+		}
+
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		switch key {
+		case orderbook.TagOrderID:
+			changes.orderIDs = append(changes.orderIDs, id)
+			changes.madeOrderIDs = append(changes.madeOrderIDs, id)
+		case orderbook.TagCancelledOrderID, orderbook.TagExpiredOrderID:
+			changes.orderIDs = append(changes.orderIDs, id)
+		case orderbook.TagTradeID:
+			changes.tradeIDs = append(changes.tradeIDs, id)
+		}
+	}
+	return changes
+}
+
+// Re-reads the orders of a book that a block changed from the store at its height, and publishes the price levels
+// that changed and the trades of the market to the book's subscribers
+func (s *Server) updateBook(key string, book *Book, changes blockChanges, height int64) error {
+	reread := make(map[int64]bool)
+	for _, orderID := range changes.orderIDs {
+		reread[orderID] = true
+	}
+	if changes.proposalApplied {
+		for _, orderID := range book.OrderIDs(nil) {
+			reread[orderID] = true
+		}
+	}
+
+	var trades []orderbook.Trade
+	for _, tradeID := range changes.tradeIDs {
+		trade, found, err := s.trade(book.Market, tradeID, height)
+		if err != nil {
+			return err
+		}
+		// the trade IDs of the block are those of all markets
+		if !found {
+			continue
+		}
+		trades = append(trades, trade)
+		reread[trade.MakerOrderID] = true
+		reread[trade.TakerOrderID] = true
+	}
+
+	for _, orderID := range changes.madeOrderIDs {
+		order, found, err := s.order(orderbook.OrderKey(orderID), height)
+		if err == nil && !found {
+			order, found, err = s.order(orderbook.OrderHistoryKey(orderID), height)
+		}
+		if err != nil {
+			return err
+		}
+		if !found || !book.InMarket(order) {
+			continue
+		}
+		for _, ownerOrderID := range book.OrderIDs(order.Owner) {
+			reread[ownerOrderID] = true
+		}
+	}
+
+	for orderID := range reread {
+		order, found, err := s.order(orderbook.OrderKey(orderID), height)
+		if err != nil {
+			return err
+		}
+		if found && book.InMarket(order) {
+			book.SetOrder(order.Visible())
+		} else {
+			book.RemoveOrder(orderID)
+		}
+	}
+
+	asks, bids := book.Update(height)
+	if len(asks) > 0 || len(bids) > 0 {
+		s.publish(key, Message{Type: MessageDepth, Market: book.Market, Height: height, Asks: asks, Bids: bids})
+	}
+
+	sort.Slice(trades, func(i, j int) bool { return trades[i].TradeID < trades[j].TradeID })
+	for i := range trades {
+		s.publish(key, Message{Type: MessageTrade, Market: book.Market, Height: height, Trade: &trades[i]})
+	}
+	return nil
+}
+
+// GET /stream/{baseDenom}/{quoteDenom} upgrades to a WebSocket streaming the book of a market
+func (s *Server) handleSubscribe(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	market := orderbook.NewDenomPair(vars["baseDenom"], vars["quoteDenom"])
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	sub := &subscriber{conn: conn, send: make(chan []byte, subscriberBuffer)}
+
+	err = s.subscribe(market, sub)
+	if err != nil {
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, err.Error()))
+		conn.Close()
+		return
+	}
+
+	go sub.writeLoop()
+
+	// nothing is expected from subscribers, reading only detects when they leave
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			break
+		}
+	}
+	s.unsubscribe(market, sub)
+}
+
+// Adds a subscriber to a market, loading its book if it's the first one, and sends it a snapshot of the book
+func (s *Server) subscribe(market orderbook.DenomPair, sub *subscriber) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	key := market.String()
+	book, found := s.books[key]
+	if !found {
+		book = NewBook(market)
+
+		// the orderwalls are read after the height, so the blocks after it re-read whatever they change
+		node, err := s.cliCtx.GetNode()
+		if err != nil {
+			return err
+		}
+		status, err := node.Status()
+		if err != nil {
+			return err
+		}
+		for _, pair := range []orderbook.DenomPair{market, market.ReversePair()} {
+			orders, err := s.queryOrderwall(pair)
+			if err != nil {
+				return err
+			}
+			for _, order := range orders {
+				book.SetOrder(order)
+			}
+		}
+
+		book.Update(status.SyncInfo.LatestBlockHeight)
+		s.books[key] = book
+		s.subscribers[key] = make(map[*subscriber]bool)
+	}
+
+	snapshot := book.Snapshot()
+	bz, err := s.cdc.MarshalJSON(Message{
+		Type:   MessageSnapshot,
+		Market: market,
+		Height: book.Height,
+		Asks:   snapshot.Asks,
+		Bids:   snapshot.Bids,
+	})
+	if err != nil {
+		return err
+	}
+	sub.send <- bz
+	s.subscribers[key][sub] = true
+	return nil
+}
+
+// Removes a subscriber from a market, forgetting the book once nobody follows it
+func (s *Server) unsubscribe(market orderbook.DenomPair, sub *subscriber) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	key := market.String()
+	subscribers, found := s.subscribers[key]
+	if !found {
+		return
+	}
+	// subscribers that fell behind were already removed by publish
+	if subscribers[sub] {
+		delete(subscribers, sub)
+		close(sub.send)
+	}
+
+	if len(subscribers) == 0 {
+		delete(s.subscribers, key)
+		delete(s.books, key)
+	}
+}
+
+// Sends a message to every subscriber of a book.  Subscribers that fell behind are disconnected
+func (s *Server) publish(key string, msg Message) {
+	bz, err := s.cdc.MarshalJSON(msg)
+	if err != nil {
+		return
+	}
+	for sub := range s.subscribers[key] {
+		select {
+		case sub.send <- bz:
+		default:
+			delete(s.subscribers[key], sub)
+			close(sub.send)
+		}
+	}
+}
+
+// Writes the messages of a subscriber until it's unsubscribed
+func (sub *subscriber) writeLoop() {
+	defer sub.conn.Close()
+	for bz := range sub.send {
+		err := sub.conn.WriteMessage(websocket.TextMessage, bz)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Queries all the orders of an orderwall
+func (s *Server) queryOrderwall(pair orderbook.DenomPair) (orders []orderbook.Order, err error) {
+	res, err := s.cliCtx.QueryWithData(fmt.Sprintf("custom/%s/%s/%s", s.queryRoute, orderbook.QueryOrderwall, pair), nil)
+	if err != nil {
+		return orders, err
+	}
+	err = s.cdc.UnmarshalJSON(res, &orders)
+	return orders, err
+}
+
+// Reads an order from the store at a height
+func (s *Server) order(key []byte, height int64) (order orderbook.Order, found bool, err error) {
+	found, err = s.storeObject(key, height, &order)
+	return order, found, err
+}
+
+// Reads a trade of a market from the store at a height
+func (s *Server) trade(market orderbook.DenomPair, tradeID int64, height int64) (trade orderbook.Trade, found bool, err error) {
+	found, err = s.storeObject(orderbook.TradeKey(market, tradeID), height, &trade)
+	return trade, found, err
+}
+
+// Reads the value of a key of the orderbook store at a height into ptr.  The custom queries can't be used for it,
+// as nodes only serve them from their latest state
+func (s *Server) storeObject(key []byte, height int64, ptr interface{}) (found bool, err error) {
+	cliCtx := s.cliCtx
+	cliCtx.Height = height
+	res, err := cliCtx.QueryStore(key, s.storeName)
+	if err != nil || len(res) == 0 {
+		return false, err
+	}
+	err = s.cdc.UnmarshalBinaryBare(res, ptr)
+	return err == nil, err
+}
+
+// Returns the value of the market tag of a pair, with its denoms in sorted order
+func marketString(pair orderbook.DenomPair) string {
+	if pair.SellDenom > pair.BuyDenom {
+		pair = pair.ReversePair()
+	}
+	return pair.String()
+}
@@ -0,0 +1,35 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/state"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+)
+
+func TestBlockChanges(t *testing.T) {
+	results := &state.ABCIResponses{
+		DeliverTx: []*abci.ResponseDeliverTx{
+			{Tags: orderbook.MarketTag(orderbook.NewDenomPair("ETH", "BTC")).
+				AppendTag(orderbook.TagOrderID, []byte("7")).
+				AppendTag(orderbook.TagTradeID, []byte("3"))},
+			{Tags: sdk.NewTags(orderbook.TagCancelledOrderID, []byte("5"))},
+		},
+		EndBlock: &abci.ResponseEndBlock{
+			Tags: sdk.NewTags(orderbook.TagExpiredOrderID, []byte("6"), orderbook.TagTradeID, []byte("4")),
+		},
+	}
+
+	changes := newBlockChanges(results)
+	require.True(t, changes.markets[marketString(orderbook.NewDenomPair("ETH", "BTC"))])
+	require.Equal(t, []int64{7, 5, 6}, changes.orderIDs)
+	require.Equal(t, []int64{7}, changes.madeOrderIDs)
+	require.Equal(t, []int64{3, 4}, changes.tradeIDs)
+	require.False(t, changes.proposalApplied)
+}
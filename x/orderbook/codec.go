@@ -8,4 +8,8 @@ import (
 func RegisterCodec(cdc *codec.Codec) {
 	cdc.RegisterConcrete(MsgMakeOrder{}, "orderbook/MakeOrder", nil)
 	cdc.RegisterConcrete(MsgRemoveOrder{}, "orderbook/RemoveOrder", nil)
+	cdc.RegisterConcrete(MsgMakeTWAPOrder{}, "orderbook/MakeTWAPOrder", nil)
+	cdc.RegisterConcrete(MsgCancelTWAPOrder{}, "orderbook/CancelTWAPOrder", nil)
+	cdc.RegisterConcrete(MsgUpdateParams{}, "orderbook/UpdateParams", nil)
+	cdc.RegisterConcrete(MsgSubmitOrderbookProposal{}, "orderbook/SubmitOrderbookProposal", nil)
 }
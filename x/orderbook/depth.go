@@ -0,0 +1,78 @@
+package orderbook
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Number of price levels of each side returned by a depth query when no limit is given
+const DefaultDepthLevels int64 = 50
+
+// PriceLevel is the total amount of SellDenom offered or asked for at one price.
+// Price is in units of BuyDenom/SellDenom of the Depth's pair
+type PriceLevel struct {
+	Price  sdk.Dec `json:"price"`
+	Amount sdk.Int `json:"amount"`
+}
+
+// Depth is the aggregated orderbook of a pair.  Asks are the orders selling SellDenom, lowest price first,
+// and Bids are the orders buying SellDenom, highest price first.  Only the visible part of iceberg orders is counted
+type Depth struct {
+	Pair DenomPair    `json:"pair"`
+	Asks []PriceLevel `json:"asks"`
+	Bids []PriceLevel `json:"bids"`
+}
+
+// Gets up to levels price levels of each side of the orderbook of a pair
+func (k Keeper) GetDepth(ctx sdk.Context, pair DenomPair, levels int64) Depth {
+	return Depth{
+		Pair: pair,
+		Asks: k.depthLevels(ctx, pair, levels, false),
+		Bids: k.depthLevels(ctx, pair.ReversePair(), levels, true),
+	}
+}
+
+// Aggregates the orders of an orderwall by price.  The orders of the opposing side of the book are converted
+// to the units of the reverse pair, so that both sides are priced in the same units
+func (k Keeper) depthLevels(ctx sdk.Context, wallPair DenomPair, levels int64, opposing bool) (priceLevels []PriceLevel) {
+	orderwallIterator := k.OrderWallIterator(ctx, wallPair)
+	defer orderwallIterator.Close()
+
+	for ; orderwallIterator.Valid(); orderwallIterator.Next() {
+		var orderID int64
+		k.cdc.MustUnmarshalBinaryBare(orderwallIterator.Value(), &orderID)
+
+		order, found := k.GetOrder(ctx, orderID)
+		if !found {
+			continue
+		}
+
+		var added bool
+		priceLevels, added = AddToPriceLevels(priceLevels, order, levels, opposing)
+		if !added {
+			break
+		}
+	}
+
+	return priceLevels
+}
+
+// Adds an order to the price levels of a side of the book, made from the orders of its orderwall before it.
+// If the order is opposing, it's converted to the units of the reverse pair.
+// Returns false if the order is past the last of levels price levels, after which the orderwall can be left
+func AddToPriceLevels(priceLevels []PriceLevel, order Order, levels int64, opposing bool) ([]PriceLevel, bool) {
+	price, amount := order.Price.Ratio, order.SellCoins.Amount
+	if opposing {
+		converted, _ := MulCoinsPrice(order.SellCoins, order.Price)
+		price, amount = SDKDecReciprocal(order.Price.Ratio), converted.Amount
+	}
+
+	last := len(priceLevels) - 1
+	if last >= 0 && priceLevels[last].Price.Equal(price) {
+		priceLevels[last].Amount = priceLevels[last].Amount.Add(amount)
+		return priceLevels, true
+	}
+	if int64(len(priceLevels)) >= levels {
+		return priceLevels, false
+	}
+	return append(priceLevels, PriceLevel{Price: price, Amount: amount}), true
+}
@@ -0,0 +1,16 @@
+package orderbook
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// EndBlocker is called at the end of every block, after the governance EndBlocker.  It applies passed orderbook
+// proposals, executes the slices of TWAPOrders that are due, expires orders and prunes the order and trade history
+func EndBlocker(ctx sdk.Context, keeper Keeper) (resTags sdk.Tags) {
+	resTags = keeper.ApplyPassedProposals(ctx)
+	resTags = resTags.AppendTags(keeper.ProcessTWAPQueue(ctx))
+	resTags = resTags.AppendTags(keeper.ExpireOrders(ctx))
+	keeper.PruneOrderHistory(ctx)
+	keeper.PruneTrades(ctx)
+	return resTags
+}
@@ -10,8 +10,21 @@ import (
 const (
 	DefaultCodespace sdk.CodespaceType = 431
 
-	CodeInvalidPriceRange  sdk.CodeType = 1
-	CodeInvalidPriceFormat sdk.CodeType = 2
+	CodeInvalidPriceRange          sdk.CodeType = 1
+	CodeInvalidPriceFormat         sdk.CodeType = 2
+	CodeInvalidDisplayAmount       sdk.CodeType = 3
+	CodeTWAPOrderNotFound          sdk.CodeType = 4
+	CodeTWAPOrderNotActive         sdk.CodeType = 5
+	CodeInvalidTWAPSchedule        sdk.CodeType = 6
+	CodeInvalidSelfTradePrevention sdk.CodeType = 7
+	CodeInvalidClientOrderID       sdk.CodeType = 8
+	CodeDuplicateClientOrderID     sdk.CodeType = 9
+	CodeClientOrderIDNotFound      sdk.CodeType = 10
+	CodeTooManyOrders              sdk.CodeType = 11
+	CodeInvalidParams              sdk.CodeType = 12
+	CodeMarketNotFound             sdk.CodeType = 13
+	CodeMarketNotActive            sdk.CodeType = 14
+	CodeInvalidProposal            sdk.CodeType = 15
 )
 
 //----------------------------------------
@@ -41,3 +54,68 @@ func ErrInvalidOrderID(codespace sdk.CodespaceType) sdk.Error {
 func ErrInvalidDenomPair(codespace sdk.CodespaceType) sdk.Error {
 	return sdk.NewError(codespace, CodeInvalidPriceFormat, fmt.Sprintf("Invalid DenomPair"))
 }
+
+// Error for when the display amount of an iceberg order is negative or larger than the order itself
+func ErrInvalidDisplayAmount(codespace sdk.CodespaceType, displayAmount sdk.Int) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidDisplayAmount, fmt.Sprintf("Invalid display amount %s. Must be between 0 and the amount of SellCoins.", displayAmount))
+}
+
+// Error for when there is no TWAPOrder with a given TWAPID
+func ErrTWAPOrderNotFound(codespace sdk.CodespaceType, twapID int64) sdk.Error {
+	return sdk.NewError(codespace, CodeTWAPOrderNotFound, fmt.Sprintf("Could not find a TWAP order with TWAPID %d", twapID))
+}
+
+// Error for when a TWAPOrder has already finished or been cancelled
+func ErrTWAPOrderNotActive(codespace sdk.CodespaceType, twapID int64) sdk.Error {
+	return sdk.NewError(codespace, CodeTWAPOrderNotActive, fmt.Sprintf("TWAP order %d is no longer active", twapID))
+}
+
+// Error for when a TWAPOrder has a non-positive slice amount or interval
+func ErrInvalidTWAPSchedule(codespace sdk.CodespaceType) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidTWAPSchedule, "TWAP slice amount and interval must be positive")
+}
+
+// Error for when an order has an unknown self-trade prevention mode
+func ErrInvalidSelfTradePrevention(codespace sdk.CodespaceType, stp SelfTradePrevention) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidSelfTradePrevention, fmt.Sprintf("Unknown self-trade prevention mode %d", stp))
+}
+
+// Error for when a client order ID is too long or has characters that can't be used in a query path
+func ErrInvalidClientOrderID(codespace sdk.CodespaceType, clientOrderID string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidClientOrderID, fmt.Sprintf("Invalid client order ID %s. Must be at most %d letters, digits, '-', '_', '.' or ':'.", clientOrderID, MaxClientOrderIDLength))
+}
+
+// Error for when an owner already made an order with a client order ID
+func ErrDuplicateClientOrderID(codespace sdk.CodespaceType, clientOrderID string) sdk.Error {
+	return sdk.NewError(codespace, CodeDuplicateClientOrderID, fmt.Sprintf("An order with client order ID %s already exists", clientOrderID))
+}
+
+// Error for when an owner has no order with a client order ID
+func ErrClientOrderIDNotFound(codespace sdk.CodespaceType, clientOrderID string) sdk.Error {
+	return sdk.NewError(codespace, CodeClientOrderIDNotFound, fmt.Sprintf("Could not find an order with client order ID %s", clientOrderID))
+}
+
+// Error for when an owner already has as many orders resting in the orderwalls as they are allowed
+func ErrTooManyOrders(codespace sdk.CodespaceType, maxOrders int64) sdk.Error {
+	return sdk.NewError(codespace, CodeTooManyOrders, fmt.Sprintf("Accounts can have at most %d orders in the orderwalls", maxOrders))
+}
+
+// Error for when orderbook params are outside the bounds the orderbook can work with
+func ErrInvalidParams(codespace sdk.CodespaceType, reason string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidParams, fmt.Sprintf("Invalid orderbook params: %s", reason))
+}
+
+// Error for when there is no market listed for a DenomPair
+func ErrMarketNotFound(codespace sdk.CodespaceType, pair DenomPair) sdk.Error {
+	return sdk.NewError(codespace, CodeMarketNotFound, fmt.Sprintf("No market is listed for %s", pair))
+}
+
+// Error for when orders are made for a DenomPair whose market isn't listed or is halted
+func ErrMarketNotActive(codespace sdk.CodespaceType, pair DenomPair) sdk.Error {
+	return sdk.NewError(codespace, CodeMarketNotActive, fmt.Sprintf("The market for %s is not listed or is halted", pair))
+}
+
+// Error for when an orderbook proposal is malformed or can't be applied to the current state
+func ErrInvalidProposal(codespace sdk.CodespaceType, reason string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidProposal, fmt.Sprintf("Invalid orderbook proposal: %s", reason))
+}
@@ -0,0 +1,66 @@
+package orderbook
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+var expirationQueuePrefix = KeyFromComponents([]byte("expirationQueue"))
+
+// get key in the queue of orders with an ExpirationTime, sorted by that time
+func ExpirationQueueKey(expirationTime time.Time, orderID int64) []byte {
+	return AppendKeyComponents(expirationQueuePrefix, sdk.FormatTimeBytes(expirationTime), Int64ToSortableBytes(orderID))
+}
+
+// Insert an orderID into the appropriate timeslice in the expiration queue
+func (k Keeper) InsertExpirationQueue(ctx sdk.Context, order Order) {
+	if order.ExpirationTime.IsZero() {
+		return
+	}
+	store := ctx.KVStore(k.storeKey)
+	store.Set(ExpirationQueueKey(order.ExpirationTime, order.OrderID), k.cdc.MustMarshalBinaryBare(order.OrderID))
+}
+
+// Remove an orderID from the expiration queue
+func (k Keeper) DeleteExpirationQueue(ctx sdk.Context, order Order) {
+	if order.ExpirationTime.IsZero() {
+		return
+	}
+	store := ctx.KVStore(k.storeKey)
+	store.Delete(ExpirationQueueKey(order.ExpirationTime, order.OrderID))
+}
+
+// Closes all orders that expired at or before the time of the current block and refunds their owners
+func (k Keeper) ExpireOrders(ctx sdk.Context) (resTags sdk.Tags) {
+	store := ctx.KVStore(k.storeKey)
+	resTags = sdk.NewTags()
+
+	end := sdk.PrefixEndBytes(AppendKeyComponents(expirationQueuePrefix, sdk.FormatTimeBytes(ctx.BlockHeader().Time)))
+	iterator := store.Iterator(expirationQueuePrefix, end)
+
+	var expiredKeys [][]byte
+	var expiredIDs []int64
+	for ; iterator.Valid(); iterator.Next() {
+		var orderID int64
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &orderID)
+		expiredKeys = append(expiredKeys, iterator.Key())
+		expiredIDs = append(expiredIDs, orderID)
+	}
+	iterator.Close()
+
+	for i, orderID := range expiredIDs {
+		order, found := k.GetOrder(ctx, orderID)
+		if !found {
+			store.Delete(expiredKeys[i])
+			continue
+		}
+		k.CloseOrder(ctx, order, OrderStatusExpired)
+		k.coinKeeper.AddCoins(ctx, order.Owner, sdk.Coins{order.TotalSellCoins()})
+
+		resTags = resTags.AppendTag(TagExpiredOrderID, idTagValue(orderID))
+		resTags = resTags.AppendTags(MarketTag(order.Pair()))
+	}
+
+	return resTags
+}
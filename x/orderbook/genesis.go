@@ -0,0 +1,61 @@
+package orderbook
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// GenesisState is the orderbook state a chain starts with
+type GenesisState struct {
+	Params  Params   `json:"params"`
+	Markets []Market `json:"markets"`
+}
+
+func NewGenesisState(params Params, markets []Market) GenesisState {
+	return GenesisState{
+		Params:  params,
+		Markets: markets,
+	}
+}
+
+// Returns the orderbook state of a new chain, with the default params and no markets listed
+func DefaultGenesisState() GenesisState {
+	return NewGenesisState(DefaultParams(), nil)
+}
+
+// Checks that the orderbook genesis state can be used to start a chain
+func ValidateGenesis(data GenesisState) error {
+	err := data.Params.Validate()
+	if err != nil {
+		return err
+	}
+
+	listed := make(map[string]bool)
+	for _, market := range data.Markets {
+		err = market.Validate()
+		if err != nil {
+			return err
+		}
+		key := string(MarketKey(market.Pair()))
+		if listed[key] {
+			return fmt.Errorf("market %s is listed twice", market)
+		}
+		listed[key] = true
+	}
+	return nil
+}
+
+// Sets the orderbook state from genesis
+func InitGenesis(ctx sdk.Context, keeper Keeper, data GenesisState) {
+	keeper.SetSchemaVersion(ctx, SchemaVersion())
+	keeper.SetParams(ctx, data.Params)
+	for _, market := range data.Markets {
+		keeper.SetMarket(ctx, market)
+	}
+}
+
+// Returns the orderbook state to export as genesis
+func ExportGenesis(ctx sdk.Context, keeper Keeper) GenesisState {
+	return NewGenesisState(keeper.GetParams(ctx), keeper.GetMarkets(ctx))
+}
@@ -15,6 +15,14 @@ func NewHandler(keeper Keeper) sdk.Handler {
 			return handleMsgMakeOrder(ctx, keeper, msg)
 		case MsgRemoveOrder:
 			return handleMsgRemoveOrder(ctx, keeper, msg)
+		case MsgMakeTWAPOrder:
+			return handleMsgMakeTWAPOrder(ctx, keeper, msg)
+		case MsgCancelTWAPOrder:
+			return handleMsgCancelTWAPOrder(ctx, keeper, msg)
+		case MsgUpdateParams:
+			return handleMsgUpdateParams(ctx, keeper, msg)
+		case MsgSubmitOrderbookProposal:
+			return handleMsgSubmitOrderbookProposal(ctx, keeper, msg)
 		default:
 			errMsg := fmt.Sprintf("Unrecognized orderbook Msg type: %v", reflect.TypeOf(msg).Name())
 			return sdk.ErrUnknownRequest(errMsg).Result()
@@ -28,10 +36,21 @@ func handleMsgMakeOrder(ctx sdk.Context, keeper Keeper, msg MsgMakeOrder) sdk.Re
 
 	order := Order{
 		OrderID:        orderID,
+		ClientOrderID:  msg.ClientOrderID,
 		Owner:          msg.OwnerAddr,
 		SellCoins:      msg.SellCoins,
+		BuyDenom:       msg.Price.NumeratorDenom,
 		Price:          msg.Price,
 		ExpirationTime: msg.ExpirationTime,
+		DisplayAmount:  msg.DisplayAmount,
+		HiddenCoins:    sdk.NewCoin(msg.SellCoins.Denom, sdk.ZeroInt()),
+
+		SelfTradePrevention: msg.SelfTradePrevention,
+	}
+
+	// orders made without an expiration time get the default expiry, if there is one
+	if order.ExpirationTime.IsZero() && keeper.DefaultExpiry(ctx) > 0 {
+		order.ExpirationTime = ctx.BlockHeader().Time.Add(keeper.DefaultExpiry(ctx))
 	}
 
 	_, _, err := keeper.coinKeeper.SubtractCoins(ctx, order.Owner, sdk.Coins{order.SellCoins})
@@ -39,20 +58,131 @@ func handleMsgMakeOrder(ctx sdk.Context, keeper Keeper, msg MsgMakeOrder) sdk.Re
 		return err.Result()
 	}
 
-	consumed, err := keeper.AddNewOrder(ctx, order)
+	lastTradeID := keeper.GetLastTradeID(ctx)
+	order, consumed, err := keeper.AddNewOrder(ctx, order)
 	if err != nil {
 		return err.Result()
 	}
 
+	tags := MarketTag(order.Pair()).AppendTag(TagOrderID, idTagValue(order.OrderID))
+	var trades []Trade
+	for tradeID := lastTradeID + 1; tradeID <= keeper.GetLastTradeID(ctx); tradeID++ {
+		tags = tags.AppendTag(TagTradeID, idTagValue(tradeID))
+		if trade, found := keeper.GetTrade(ctx, order.Pair(), tradeID); found {
+			trades = append(trades, trade)
+		}
+	}
+
+	result := MakeOrderResult{
+		OrderID:        order.OrderID,
+		ClientOrderID:  order.ClientOrderID,
+		Consumed:       consumed,
+		Status:         order.Status,
+		FilledCoins:    order.FilledCoins,
+		ReceivedCoins:  order.ReceivedCoins,
+		RemainingCoins: order.TotalSellCoins(),
+		Trades:         trades,
+	}
+
 	return sdk.Result{
-		Data: keeper.cdc.MustMarshalBinaryBare(consumed),
+		Data: keeper.cdc.MustMarshalJSON(result),
+		Tags: tags,
 	}
 }
 
 // Handle MsgRemoveOrder
 func handleMsgRemoveOrder(ctx sdk.Context, keeper Keeper, msg MsgRemoveOrder) sdk.Result {
-	removedOrder := keeper.RemoveOrder(ctx, msg.OrderID)
-	keeper.coinKeeper.AddCoins(ctx, removedOrder.Owner, sdk.Coins{removedOrder.SellCoins})
+	orderID := msg.OrderID
+	if msg.ClientOrderID != "" {
+		var found bool
+		orderID, found = keeper.GetOrderIDByClientOrderID(ctx, msg.OwnerAddr, msg.ClientOrderID)
+		if !found {
+			return ErrClientOrderIDNotFound(keeper.codespace, msg.ClientOrderID).Result()
+		}
+	}
+
+	order, found := keeper.GetOrder(ctx, orderID)
+	if !found {
+		return ErrOrderNotFound(keeper.codespace, orderID).Result()
+	}
+	if !order.Owner.Equals(msg.OwnerAddr) {
+		return sdk.ErrUnauthorized("only the owner can remove an order").Result()
+	}
+
+	removedOrder := keeper.RemoveOrder(ctx, orderID)
+
+	// refund the hidden part of iceberg orders as well
+	keeper.coinKeeper.AddCoins(ctx, removedOrder.Owner, sdk.Coins{removedOrder.TotalSellCoins()})
+
+	return sdk.Result{
+		Tags: MarketTag(removedOrder.Pair()).AppendTag(TagCancelledOrderID, idTagValue(orderID)),
+	}
+}
+
+// Handle MsgMakeTWAPOrder
+func handleMsgMakeTWAPOrder(ctx sdk.Context, keeper Keeper, msg MsgMakeTWAPOrder) sdk.Result {
+	twap := TWAPOrder{
+		Owner:       msg.OwnerAddr,
+		SellCoins:   msg.SellCoins,
+		Price:       msg.Price,
+		Market:      msg.Market,
+		SliceAmount: msg.SliceAmount,
+		Interval:    msg.Interval,
+	}
+
+	twap, err := keeper.AddNewTWAPOrder(ctx, twap)
+	if err != nil {
+		return err.Result()
+	}
+
+	return sdk.Result{
+		Data: keeper.cdc.MustMarshalBinaryBare(twap.TWAPID),
+	}
+}
+
+// Handle MsgCancelTWAPOrder
+func handleMsgCancelTWAPOrder(ctx sdk.Context, keeper Keeper, msg MsgCancelTWAPOrder) sdk.Result {
+	twap, found := keeper.GetTWAPOrder(ctx, msg.TWAPID)
+	if !found {
+		return ErrTWAPOrderNotFound(keeper.codespace, msg.TWAPID).Result()
+	}
+	if !twap.Owner.Equals(msg.OwnerAddr) {
+		return sdk.ErrUnauthorized("only the owner can cancel a TWAP order").Result()
+	}
+
+	_, err := keeper.CancelTWAPOrder(ctx, msg.TWAPID)
+	if err != nil {
+		return err.Result()
+	}
+
+	return sdk.Result{}
+}
+
+// Handle MsgUpdateParams
+func handleMsgUpdateParams(ctx sdk.Context, keeper Keeper, msg MsgUpdateParams) sdk.Result {
+	admin := keeper.Admin(ctx)
+	if admin.Empty() || !admin.Equals(msg.AdminAddr) {
+		return sdk.ErrUnauthorized("only the orderbook admin can update the params").Result()
+	}
+
+	if err := msg.Params.Validate(); err != nil {
+		return ErrInvalidParams(keeper.codespace, err.Error()).Result()
+	}
+
+	keeper.SetParams(ctx, msg.Params)
 
 	return sdk.Result{}
 }
+
+// Handle MsgSubmitOrderbookProposal
+func handleMsgSubmitOrderbookProposal(ctx sdk.Context, keeper Keeper, msg MsgSubmitOrderbookProposal) sdk.Result {
+	proposalID, err := keeper.SubmitProposal(ctx, msg.Proposer, msg.Title, msg.Description, msg.InitialDeposit, msg.Action)
+	if err != nil {
+		return err.Result()
+	}
+
+	return sdk.Result{
+		Data: keeper.cdc.MustMarshalBinaryBare(proposalID),
+		Tags: sdk.NewTags(TagProposalID, []byte(fmt.Sprintf("%d", proposalID))),
+	}
+}
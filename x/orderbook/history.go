@@ -0,0 +1,74 @@
+package orderbook
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+var orderHistoryPrefix = KeyFromComponents([]byte("orderHistory"))
+var orderHistoryQueuePrefix = KeyFromComponents([]byte("orderHistoryQueue"))
+
+// Number of blocks a closed order is kept in the order history before it's pruned
+const OrderHistoryRetention int64 = 100000
+
+// get key in store to get a closed Order
+func OrderHistoryKey(orderID int64) []byte {
+	return AppendKeyComponents(orderHistoryPrefix, Int64ToSortableBytes(orderID))
+}
+
+// get key in the queue of closed orders, sorted by the height they were closed at
+func OrderHistoryQueueKey(closedHeight int64, orderID int64) []byte {
+	return AppendKeyComponents(orderHistoryQueuePrefix, Int64ToSortableBytes(closedHeight), Int64ToSortableBytes(orderID))
+}
+
+// Gets a closed Order from the order history
+func (k Keeper) GetClosedOrder(ctx sdk.Context, orderID int64) (order Order, found bool) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(OrderHistoryKey(orderID))
+	if bz == nil {
+		return order, false
+	}
+	err := k.cdc.UnmarshalBinaryBare(bz, &order)
+	if err != nil {
+		return order, false
+	}
+	return order, true
+}
+
+// Adds a closed Order to the order history
+func (k Keeper) SetClosedOrder(ctx sdk.Context, order Order) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(OrderHistoryKey(order.OrderID), k.cdc.MustMarshalBinaryBare(order))
+	store.Set(OrderHistoryQueueKey(order.ClosedHeight, order.OrderID), k.cdc.MustMarshalBinaryBare(order.OrderID))
+}
+
+// Gets an Order whether it's still in the orderwall or in the order history
+func (k Keeper) GetOrderOrClosedOrder(ctx sdk.Context, orderID int64) (order Order, found bool) {
+	order, found = k.GetOrder(ctx, orderID)
+	if found {
+		return order, true
+	}
+	return k.GetClosedOrder(ctx, orderID)
+}
+
+// Removes the orders closed more than OrderHistoryRetention blocks ago from the order history
+func (k Keeper) PruneOrderHistory(ctx sdk.Context) {
+	pruneHeight := ctx.BlockHeight() - OrderHistoryRetention
+	if pruneHeight < 0 {
+		return
+	}
+
+	store := ctx.KVStore(k.storeKey)
+	iterator := store.Iterator(orderHistoryQueuePrefix, AppendKeyComponents(orderHistoryQueuePrefix, Int64ToSortableBytes(pruneHeight+1)))
+
+	var prunedKeys [][]byte
+	for ; iterator.Valid(); iterator.Next() {
+		var orderID int64
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &orderID)
+		prunedKeys = append(prunedKeys, iterator.Key(), OrderHistoryKey(orderID))
+	}
+	iterator.Close()
+
+	for _, key := range prunedKeys {
+		store.Delete(key)
+	}
+}
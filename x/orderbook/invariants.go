@@ -0,0 +1,149 @@
+package orderbook
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Invariant checks a property the orderbook state must always have, returning an error describing how it's broken
+type Invariant func(ctx sdk.Context, k Keeper) error
+
+// RegisteredInvariant is an Invariant with the name it's reported by
+type RegisteredInvariant struct {
+	Name  string
+	Check Invariant
+}
+
+var registeredInvariants = []RegisteredInvariant{
+	{"orderwall-entries", OrderwallEntriesInvariant},
+	{"walled-orders", WalledOrdersInvariant},
+	{"uncrossed-books", UncrossedBooksInvariant},
+	{"positive-resting-orders", PositiveRestingOrdersInvariant},
+}
+
+// Registers an Invariant to be checked by AssertInvariants
+func RegisterInvariant(name string, invariant Invariant) {
+	for _, registered := range registeredInvariants {
+		if registered.Name == name {
+			panic(fmt.Sprintf("invariant %s is already registered", name))
+		}
+	}
+	registeredInvariants = append(registeredInvariants, RegisteredInvariant{name, invariant})
+}
+
+// Returns the registered invariants, in the order they were registered
+func Invariants() []RegisteredInvariant {
+	return append([]RegisteredInvariant(nil), registeredInvariants...)
+}
+
+// Checks all the registered invariants, returning an error listing the broken ones
+func AssertInvariants(ctx sdk.Context, k Keeper) error {
+	var broken []string
+	for _, invariant := range registeredInvariants {
+		err := invariant.Check(ctx, k)
+		if err != nil {
+			broken = append(broken, fmt.Sprintf("%s: %v", invariant.Name, err))
+		}
+	}
+	if len(broken) > 0 {
+		return fmt.Errorf("broken orderbook invariants:\n%s", strings.Join(broken, "\n"))
+	}
+	return nil
+}
+
+// Returns the pairs of the orderwalls of both directions of every market
+func (k Keeper) orderwallPairs(ctx sdk.Context) (pairs []DenomPair) {
+	for _, market := range k.GetMarkets(ctx) {
+		pairs = append(pairs, market.Pair(), market.Pair().ReversePair())
+	}
+	return pairs
+}
+
+// OrderwallEntriesInvariant checks that every orderwall entry references an existing order of the orderwall's pair,
+// at the price and wallSequence of its key, and that no order is in an orderwall twice
+func OrderwallEntriesInvariant(ctx sdk.Context, k Keeper) error {
+	walled := make(map[int64]bool)
+	for _, pair := range k.orderwallPairs(ctx) {
+		err := k.checkOrderwallEntries(ctx, pair, walled)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (k Keeper) checkOrderwallEntries(ctx sdk.Context, pair DenomPair, walled map[int64]bool) error {
+	orderWall := k.OrderWallIterator(ctx, pair)
+	defer orderWall.Close()
+
+	for ; orderWall.Valid(); orderWall.Next() {
+		var orderID int64
+		k.cdc.MustUnmarshalBinaryBare(orderWall.Value(), &orderID)
+
+		order, found := k.GetOrder(ctx, orderID)
+		switch {
+		case !found:
+			return fmt.Errorf("orderwall %s references order %d, which doesn't exist", pair, orderID)
+		case order.Pair() != pair:
+			return fmt.Errorf("orderwall %s references order %d of %s", pair, orderID, order.Pair())
+		case !bytes.Equal(orderWall.Key(), OrderwallOrderKey(pair, order.Price, order.WallSequence)):
+			return fmt.Errorf("order %d is in orderwall %s under another price or wallSequence", orderID, pair)
+		case walled[orderID]:
+			return fmt.Errorf("order %d is in orderwall %s twice", orderID, pair)
+		}
+		walled[orderID] = true
+	}
+	return nil
+}
+
+// WalledOrdersInvariant checks that every order in the order store is in the orderwall of an active or halted market
+func WalledOrdersInvariant(ctx sdk.Context, k Keeper) (err error) {
+	store := ctx.KVStore(k.storeKey)
+	k.IterateOrders(ctx, func(order Order) bool {
+		if !store.Has(OrderwallOrderKey(order.Pair(), order.Price, order.WallSequence)) {
+			err = fmt.Errorf("order %d is missing from orderwall %s", order.OrderID, order.Pair())
+			return true
+		}
+		if _, found := k.GetMarket(ctx, order.Pair()); !found {
+			err = fmt.Errorf("order %d rests in %s, which isn't a market", order.OrderID, order.Pair())
+			return true
+		}
+		return false
+	})
+	return err
+}
+
+// UncrossedBooksInvariant checks that the best orders of the two sides of every market don't match each other,
+// as they would have been matched when the later one was made
+func UncrossedBooksInvariant(ctx sdk.Context, k Keeper) error {
+	for _, market := range k.GetMarkets(ctx) {
+		ask, foundAsk := k.PeekOrderwallOrder(ctx, market.Pair())
+		bid, foundBid := k.PeekOrderwallOrder(ctx, market.Pair().ReversePair())
+		if !foundAsk || !foundBid {
+			continue
+		}
+		// the reciprocal prices are rounded, so the book is only crossed if the prices cross in both units
+		if ask.Price.LTE(bid.Price.Reciprocal()) && bid.Price.LTE(ask.Price.Reciprocal()) {
+			return fmt.Errorf("book of %s is crossed: ask %d at %s, bid %d at %s", market.Pair(),
+				ask.OrderID, ask.Price.Ratio, bid.OrderID, bid.Price.Reciprocal().Ratio)
+		}
+	}
+	return nil
+}
+
+// PositiveRestingOrdersInvariant checks that every resting order has coins left to sell and an open status
+func PositiveRestingOrdersInvariant(ctx sdk.Context, k Keeper) (err error) {
+	k.IterateOrders(ctx, func(order Order) bool {
+		switch {
+		case !order.SellCoins.IsPositive():
+			err = fmt.Errorf("order %d rests with %s left", order.OrderID, order.SellCoins)
+		case order.IsClosed():
+			err = fmt.Errorf("order %d rests with status %s", order.OrderID, order.Status)
+		}
+		return err != nil
+	})
+	return err
+}
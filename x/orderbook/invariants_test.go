@@ -0,0 +1,79 @@
+package orderbook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// makes resting orders on both sides of the BTC/ETH book, and returns them
+func createRestingOrders(t *testing.T, ctx sdk.Context, keeper Keeper) (ask Order, bid Order) {
+	handler := NewHandler(keeper)
+	res := handler(ctx, makeOrderMsg(alice, 100, "BTC", "3", "ETH", STPNone))
+	require.True(t, res.IsOK(), res.Log)
+	res = handler(ctx, makeOrderMsg(bob, 100, "ETH", "0.5", "BTC", STPNone))
+	require.True(t, res.IsOK(), res.Log)
+	require.Nil(t, AssertInvariants(ctx, keeper))
+
+	ask, _ = keeper.GetOrder(ctx, 1)
+	bid, _ = keeper.GetOrder(ctx, 2)
+	return ask, bid
+}
+
+func TestInvariants(t *testing.T) {
+	tests := []struct {
+		name      string
+		invariant string
+		corrupt   func(ctx sdk.Context, keeper Keeper, ask Order, bid Order)
+	}{
+		{"order deleted from the store", "orderwall-entries", func(ctx sdk.Context, keeper Keeper, ask Order, bid Order) {
+			keeper.DeleteOrder(ctx, ask.OrderID)
+		}},
+		{"order moved to another price", "orderwall-entries", func(ctx sdk.Context, keeper Keeper, ask Order, bid Order) {
+			ask.Price.Ratio = sdk.NewDec(4)
+			keeper.SetOrder(ctx, ask)
+		}},
+		{"order deleted from the orderwall", "walled-orders", func(ctx sdk.Context, keeper Keeper, ask Order, bid Order) {
+			keeper.DeleteOrderwallOrder(ctx, bid)
+		}},
+		{"bid matching the ask", "uncrossed-books", func(ctx sdk.Context, keeper Keeper, ask Order, bid Order) {
+			keeper.DeleteOrderwallOrder(ctx, bid)
+			bid.Price.Ratio = sdk.NewDecWithPrec(25, 2)
+			keeper.SetOrder(ctx, bid)
+			keeper.InsertOrderwallOrder(ctx, bid)
+		}},
+		{"order with nothing left", "positive-resting-orders", func(ctx sdk.Context, keeper Keeper, ask Order, bid Order) {
+			ask.SellCoins = sdk.NewInt64Coin("BTC", 0)
+			keeper.SetOrder(ctx, ask)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, keeper := createTestInput(t)
+			ask, bid := createRestingOrders(t, ctx, keeper)
+
+			tt.corrupt(ctx, keeper, ask, bid)
+			err := AssertInvariants(ctx, keeper)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.invariant+":")
+		})
+	}
+}
+
+func TestDecreaseOrderBidAmount(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	ask, _ := createRestingOrders(t, ctx, keeper)
+
+	keeper.DecreaseOrderBidAmount(ctx, ask.OrderID, sdk.NewInt64Coin("BTC", 40))
+	order, found := keeper.GetOrder(ctx, ask.OrderID)
+	require.True(t, found)
+	require.Equal(t, sdk.NewInt64Coin("BTC", 40), order.SellCoins)
+
+	// an order decreased to nothing leaves the orderwall
+	keeper.DecreaseOrderBidAmount(ctx, ask.OrderID, sdk.NewInt64Coin("BTC", 0))
+	_, found = keeper.GetOrder(ctx, ask.OrderID)
+	require.False(t, found)
+	require.Nil(t, AssertInvariants(ctx, keeper))
+}
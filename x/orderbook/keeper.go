@@ -4,47 +4,118 @@ import (
 	"github.com/cosmos/cosmos-sdk/codec"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/bank"
+	"github.com/cosmos/cosmos-sdk/x/params"
 )
 
 // Keeper - handlers sets/gets of custom variables for your module
 type Keeper struct {
-	coinKeeper bank.Keeper
+	coinKeeper          bank.Keeper
+	feeCollectionKeeper FeeCollectionKeeper
+	govKeeper           GovKeeper
 
 	storeKey sdk.StoreKey // The (unexposed) key used to access the store from the Context.
 
 	cdc *codec.Codec // The wire codec for binary encoding/decoding.
 
+	paramSpace params.Subspace
+
 	// Reserved codespace
 	codespace sdk.CodespaceType
+
+	// in-memory copy of the top of every orderwall, nil if the Keeper doesn't cache
+	cache *orderbookCache
+
+	// height at which the store is migrated to the schema version of this code, 0 if no upgrade is scheduled
+	upgradeHeight int64
 }
 
-var lastOrderIDKey = []byte("lastOrderID")
-var ordersPrefix = []byte("orders")
+var lastOrderIDKey = KeyFromComponents([]byte("lastOrderID"))
+var ordersPrefix = KeyFromComponents([]byte("orders"))
+var clientOrderIDsPrefix = KeyFromComponents([]byte("clientOrderIDs"))
+var openOrderCountsPrefix = KeyFromComponents([]byte("openOrderCounts"))
+var ownerOrdersPrefix = KeyFromComponents([]byte("ownerOrders"))
+
+// Limits on the work a single incoming order can cause while being matched
+const (
+	// number of opposing orders an incoming order can be matched against in the default params
+	DefaultMaxFills int64 = 100
+	// gas consumed for every opposing order an incoming order is matched against
+	GasPerFill sdk.Gas = 1000
+	// gas consumed for every order peeked from an orderwall, about what reading it from the store would cost.
+	// It's the same whether the order is read from the store or from the orderbook cache
+	GasPerPeekedOrder sdk.Gas = 300
+)
 
-func NewKeeper(coinKeeper bank.Keeper, storeKey sdk.StoreKey, cdc *codec.Codec, codespace sdk.CodespaceType) Keeper {
+// FeeCollectionKeeper is where trading fees are collected, to be distributed to validators and delegators
+type FeeCollectionKeeper interface {
+	AddCollectedFees(ctx sdk.Context, coins sdk.Coins) sdk.Coins
+}
+
+func NewKeeper(coinKeeper bank.Keeper, feeCollectionKeeper FeeCollectionKeeper, govKeeper GovKeeper, storeKey sdk.StoreKey,
+	cdc *codec.Codec, paramSpace params.Subspace, codespace sdk.CodespaceType) Keeper {
 	return Keeper{
-		coinKeeper: coinKeeper,
-		storeKey:   storeKey,
-		cdc:        cdc,
-		codespace:  codespace,
+		coinKeeper:          coinKeeper,
+		feeCollectionKeeper: feeCollectionKeeper,
+		govKeeper:           govKeeper,
+		storeKey:            storeKey,
+		cdc:                 cdc,
+		paramSpace:          paramSpace.WithTypeTable(ParamTypeTable()),
+		codespace:           codespace,
 	}
 }
 
-// AddNewOrder - Adds a new order into the proper orderbook
-func (k Keeper) AddNewOrder(ctx sdk.Context, order Order) (consumed bool, err sdk.Error) {
-	if !ValidSortableDec(order.Price.Ratio) {
-		return false, ErrInvalidPriceRange(k.codespace, order.Price.Ratio)
+// AddNewOrder - Adds a new order into the proper orderbook.
+// Returns the order with what is left of it after running it against the opposing orderwall
+func (k Keeper) AddNewOrder(ctx sdk.Context, order Order) (remainingOrder Order, consumed bool, err sdk.Error) {
+	if !k.IsMarketActive(ctx, order.Pair()) {
+		return order, false, ErrMarketNotActive(k.codespace, order.Pair())
+	}
+	if !ValidSortableDec(order.Price.Ratio) || order.Price.Ratio.GT(k.MaxPrice(ctx)) {
+		return order, false, ErrInvalidPriceRange(k.codespace, order.Price.Ratio)
+	}
+	if k.GetOpenOrderCount(ctx, order.Owner) >= k.MaxOrdersPerAccount(ctx) {
+		return order, false, ErrTooManyOrders(k.codespace, k.MaxOrdersPerAccount(ctx))
+	}
+
+	order.OriginalCoins = order.TotalSellCoins()
+	order.FilledCoins = sdk.NewCoin(order.SellCoins.Denom, sdk.ZeroInt())
+	order.ReceivedCoins = sdk.NewCoin(order.BuyDenom, sdk.ZeroInt())
+	order.AveragePrice = sdk.ZeroDec()
+	order.Status = OrderStatusOpen
+	order.CreatedHeight = ctx.BlockHeight()
+	order.CreatedTime = ctx.BlockHeader().Time
+
+	// client order IDs are unique per owner, so that resubmitting an order can't create a duplicate
+	if order.ClientOrderID != "" {
+		if _, found := k.GetOrderIDByClientOrderID(ctx, order.Owner, order.ClientOrderID); found {
+			return order, false, ErrDuplicateClientOrderID(k.codespace, order.ClientOrderID)
+		}
+		k.SetClientOrderID(ctx, order.Owner, order.ClientOrderID, order.OrderID)
 	}
 
 	// First run order against opposing order wall
 	order, consumed = k.ExecuteOrderAgainstOrderWall(ctx, order)
 
-	// if the order hasn't been fully executed, add it to its own order wall
-	if !consumed {
-		k.SetOrder(ctx, order)
-		k.InsertOrderwallOrder(ctx, order)
+	// if the order has been fully executed (or cancelled by self-trade prevention) it goes straight to the order history
+	if consumed {
+		if order.Status != OrderStatusCancelled {
+			order.Status = OrderStatusFilled
+		}
+		order.ClosedHeight = ctx.BlockHeight()
+		k.SetClosedOrder(ctx, order)
+		return order, true, nil
 	}
-	return consumed, nil
+
+	// if the order hasn't been fully executed, add it to its own order wall
+	// only the display slice of an iceberg order goes into the wall
+	order = order.HideIcebergReserve()
+	order.WallSequence = k.GetNextWallSequence(ctx)
+	k.SetOrder(ctx, order)
+	k.InsertOrderwallOrder(ctx, order)
+	k.InsertExpirationQueue(ctx, order)
+	k.setOpenOrderCount(ctx, order.Owner, k.GetOpenOrderCount(ctx, order.Owner)+1)
+	k.setOwnerOrder(ctx, order.Owner, order.OrderID)
+	return order, false, nil
 }
 
 // Updates the amount of SellCoins left in an order
@@ -54,7 +125,8 @@ func (k Keeper) DecreaseOrderBidAmount(ctx sdk.Context, orderID int64, newAmount
 		return
 	}
 
-	if !newAmount.IsZero() {
+	// an order with nothing left to sell can't rest in the orderwall
+	if newAmount.IsZero() {
 		k.RemoveOrder(ctx, orderID)
 		return
 	}
@@ -63,79 +135,149 @@ func (k Keeper) DecreaseOrderBidAmount(ctx sdk.Context, orderID int64, newAmount
 	k.SetOrder(ctx, order)
 }
 
-// Removes an order from state and from its orderwall
+// Removes an order from state and from its orderwall, keeping it in the order history as cancelled
 func (k Keeper) RemoveOrder(ctx sdk.Context, orderID int64) Order {
 	order, found := k.GetOrder(ctx, orderID)
 	if !found {
 		return Order{}
 	}
-	k.DeleteOrderwallOrder(ctx, order)
-	k.DeleteOrder(ctx, orderID)
+	k.CloseOrder(ctx, order, OrderStatusCancelled)
 
 	return order
 }
 
+// Removes an order from its orderwall and moves it to the order history with a final status
+func (k Keeper) CloseOrder(ctx sdk.Context, order Order, status string) {
+	k.DeleteOrderwallOrder(ctx, order)
+	k.DeleteExpirationQueue(ctx, order)
+	k.DeleteOrder(ctx, order.OrderID)
+	k.setOpenOrderCount(ctx, order.Owner, k.GetOpenOrderCount(ctx, order.Owner)-1)
+	k.deleteOwnerOrder(ctx, order.Owner, order.OrderID)
+
+	order.Status = status
+	order.ClosedHeight = ctx.BlockHeight()
+	k.SetClosedOrder(ctx, order)
+}
+
 // Executes an order against an orderwall until either the order is fully consumed, there are no more order left in the wall,
-// or there is a spread (the prices don't overlap)
+// or there is a spread (the prices don't overlap).
+// An order cancelled by its self-trade prevention mode has its remaining coins refunded and is also returned as consumed.
+// At most MaxFills orders of the opposing wall are matched.  If the order would still match the next one after that,
+// its remaining coins are refunded and it is cancelled instead of being left crossing the opposing wall
 func (k Keeper) ExecuteOrderAgainstOrderWall(ctx sdk.Context, order Order) (remainingOrder Order, consumed bool) {
 	opposingPair := order.Pair().ReversePair()
+	maxFills := k.MaxFills(ctx)
+	fills := int64(0)
+	makerFeeRate := k.MakerFeeRate(ctx)
+	takerFeeRate := k.TakerFeeRate(ctx)
+
+	// The incoming order's price is the least it's willing to receive for what it sells.
+	// An order of the opposing wall that gives less than that doesn't cross it
+	crosses := func(wallOrder Order) bool {
+		return order.Price.LTE(wallOrder.Price.Reciprocal())
+	}
 
 	// while the order hasn't been fully consumed
+walk:
 	for order.SellCoins.IsPositive() {
 
-		// get the first order in the opposing wall
-		// if there are no more orders in the opposing wall, end by placing the remaining incoming order in its own wall
-		peekWallOrder, found := k.PeekOrderwallOrder(ctx, opposingPair)
-		if !found {
-			k.DecreaseOrderBidAmount(ctx, order.OrderID, order.SellCoins)
-			return order, false
+		// stop matching once the order has used up its fills
+		if fills >= maxFills {
+			if len(k.PeekOrderwallOrdersWhile(ctx, opposingPair, 1, crosses)) > 0 {
+				return k.cancelIncomingOrder(ctx, order), true
+			}
+			break
 		}
 
-		// get the asking price of peekedOrder
-		askPrice := peekWallOrder.Price.Reciprocal()
-
-		// If the asking price is greater than the incoming order is willing to pay, break out of the loop and end
-		if order.Price.LT(askPrice) {
+		// get the first orders in the opposing wall that cross the incoming order, as many as it has fills left,
+		// in a single walk that stops at the first order that doesn't cross.
+		// if no order crosses it, end by placing the remaining incoming order in its own wall
+		peekWallOrders := k.PeekOrderwallOrdersWhile(ctx, opposingPair, maxFills-fills, crosses)
+		if len(peekWallOrders) == 0 {
 			break
 		}
 
-		// get the amount the taker has to pay to execute the entire peekedOrder *at the maker's price*
-		bidAtAskingPrice, _ := MulCoinsPrice(order.SellCoins, askPrice)
+		for _, peekWallOrder := range peekWallOrders {
+			// get the asking price of peekedOrder, in the units of the incoming order's price
+			askPrice := peekWallOrder.Price.Reciprocal()
+
+			// every order of the opposing wall that the incoming order reaches costs a fill and gas
+			fills++
+			ctx.GasMeter().ConsumeGas(GasPerFill, "orderbook fill")
+
+			// don't let the incoming order match against a resting order of the same owner, unless asked to
+			if order.SelfTradePrevention != STPNone && order.Owner.Equals(peekWallOrder.Owner) {
+				var cancelled bool
+				order, cancelled = k.preventSelfTrade(ctx, order, peekWallOrder, askPrice)
+				if cancelled {
+					return order, true
+				}
+				continue
+			}
+
+			// get the amount the taker has to pay to execute the entire peekedOrder *at the maker's price*
+			bidAtAskingPrice, _ := MulCoinsPrice(order.SellCoins, askPrice)
+
+			// if the peeked order can't fulfill my entire order, execute as much as possible (the entire peeked order)
+			// and remove the peeked order
+			if bidAtAskingPrice.IsGTE(peekWallOrder.SellCoins) {
+				// the amount that the taker has to pay to complete the peekedOrder
+				executeAmount, _ := MulCoinsPrice(peekWallOrder.SellCoins, askPrice.Reciprocal())
+
+				// Remove executeAmount from the incoming order's sellCoins and send them to the peekOrder's maker,
+				// and send the full sellCoins of the peekedOrder to the incoming order's owner (the taker)
+				makerReceived := k.payFill(ctx, peekWallOrder.Owner, executeAmount, makerFeeRate)
+				takerReceived := k.payFill(ctx, order.Owner, peekWallOrder.SellCoins, takerFeeRate)
+				k.recordTrade(ctx, peekWallOrder, order, peekWallOrder.SellCoins, executeAmount, makerReceived, takerReceived)
+				order.SellCoins = order.SellCoins.Minus(executeAmount)
+				order = order.recordFill(executeAmount, takerReceived)
+				peekWallOrder = peekWallOrder.recordFill(peekWallOrder.SellCoins, makerReceived)
 
-		// if the peeked order can't fulfill my entire order, execute as much as possible (the entire peeked order)
-		// and remove the peeked order
-		if bidAtAskingPrice.IsGTE(peekWallOrder.SellCoins) {
-			// the amount that the taker has to pay to complete the peekedOrder
-			executeAmount, _ := MulCoinsPrice(peekWallOrder.SellCoins, askPrice.Reciprocal())
-
-			// Remove executeAmount from the incoming order's sellCoins and send them to the peekOrder's maker
-			k.coinKeeper.AddCoins(ctx, peekWallOrder.Owner, sdk.Coins{executeAmount})
-			order.SellCoins = order.SellCoins.Minus(executeAmount)
-
-			// Send the full sellCoins of the peekedOrder to the incoming order's owner (the taker)
-			// and remove the peeked order from state
-			k.coinKeeper.AddCoins(ctx, order.Owner, sdk.Coins{peekWallOrder.SellCoins})
-			k.RemoveOrder(ctx, peekWallOrder.OrderID)
-		} else {
-			// scenario that peekedOrder is larger than the incoming taker order
-
-			// amount that the peekedOrder trades to fully execute the incoming order
-			executeAmount, _ := MulCoinsPrice(order.SellCoins, askPrice)
-
-			// Remove executeAmount from the peekedOrder's sellCoins and send them to the taker (the incoming order's owner)
-			k.coinKeeper.AddCoins(ctx, order.Owner, sdk.Coins{executeAmount})
-			peekWallOrder.SellCoins = peekWallOrder.SellCoins.Minus(executeAmount)
-
-			// send all the coins in the taker's order to the maker,
-			// remove the taker's order as it's been completely fulfilled,
-			// and return with consumed as true, as the entire incoming order has been consumed
-			k.coinKeeper.AddCoins(ctx, peekWallOrder.Owner, sdk.Coins{order.SellCoins})
-			order.SellCoins = order.SellCoins.Minus(order.SellCoins)
-			k.RemoveOrder(ctx, order.OrderID)
-			return order, true
+				// remove the peeked order from state, unless it's an iceberg order with a slice left to show
+				peekWallOrder.SellCoins = peekWallOrder.SellCoins.Minus(peekWallOrder.SellCoins)
+				refilled := k.refillIcebergOrder(ctx, peekWallOrder)
+				if !refilled {
+					k.CloseOrder(ctx, peekWallOrder, OrderStatusFilled)
+				}
+
+				if !order.SellCoins.IsPositive() {
+					break walk
+				}
+
+				// the iceberg's next slice may rest ahead of the rest of the batch, so the wall is peeked again
+				if refilled {
+					continue walk
+				}
+			} else {
+				// scenario that peekedOrder is larger than the incoming taker order
+
+				// amount that the peekedOrder trades to fully execute the incoming order
+				executeAmount, _ := MulCoinsPrice(order.SellCoins, askPrice)
+
+				// Remove executeAmount from the peekedOrder's sellCoins and send them to the taker (the incoming order's owner),
+				// and send all the coins in the taker's order to the maker
+				takerReceived := k.payFill(ctx, order.Owner, executeAmount, takerFeeRate)
+				makerReceived := k.payFill(ctx, peekWallOrder.Owner, order.SellCoins, makerFeeRate)
+				k.recordTrade(ctx, peekWallOrder, order, executeAmount, order.SellCoins, makerReceived, takerReceived)
+				peekWallOrder.SellCoins = peekWallOrder.SellCoins.Minus(executeAmount)
+				peekWallOrder = peekWallOrder.recordFill(executeAmount, makerReceived)
+				k.SetOrder(ctx, peekWallOrder)
+				order = order.recordFill(order.SellCoins, takerReceived)
+
+				// remove the taker's order as it's been completely fulfilled,
+				// and return with consumed as true, as the entire incoming order has been consumed
+				order.SellCoins = order.SellCoins.Minus(order.SellCoins)
+				k.RemoveOrder(ctx, order.OrderID)
+				return order, true
+			}
 		}
 	}
 
+	// an order whose sellCoins were used up exactly by the last peeked order has been consumed
+	if !order.SellCoins.IsPositive() {
+		return order, true
+	}
+
 	// Breaks out of loop when the order hasn't been completely executed, but there are no more
 	// overlapping price orders
 
@@ -144,3 +286,87 @@ func (k Keeper) ExecuteOrderAgainstOrderWall(ctx sdk.Context, order Order) (rema
 	// return that the order has not been completely consumed
 	return order, false
 }
+
+// Pays the coins received in a fill to an owner, minus the trading fee at feeRate.
+// The fee goes to the fee collector, from which it is distributed to validators and delegators.
+// Returns the coins the owner received
+func (k Keeper) payFill(ctx sdk.Context, owner sdk.AccAddress, coins sdk.Coin, feeRate sdk.Dec) (received sdk.Coin) {
+	fee := sdk.NewCoin(coins.Denom, sdk.NewDecFromInt(coins.Amount).Mul(feeRate).TruncateInt())
+	received = coins.Minus(fee)
+	k.coinKeeper.AddCoins(ctx, owner, sdk.Coins{received})
+	if fee.IsPositive() {
+		k.feeCollectionKeeper.AddCollectedFees(ctx, sdk.Coins{fee})
+	}
+	return received
+}
+
+// Puts the next slice of an iceberg order whose visible coins were consumed at the back of its price level.
+// Returns false if the order had no hidden coins left
+func (k Keeper) refillIcebergOrder(ctx sdk.Context, order Order) bool {
+	refilled, ok := order.RefillIceberg()
+	if !ok {
+		return false
+	}
+	k.DeleteOrderwallOrder(ctx, order)
+	refilled.WallSequence = k.GetNextWallSequence(ctx)
+	k.SetOrder(ctx, refilled)
+	k.InsertOrderwallOrder(ctx, refilled)
+	return true
+}
+
+// Applies the self-trade prevention mode of an incoming order that would match a resting order of the same owner.
+// Cancelled orders have their remaining coins refunded to the owner.  Returns whether the incoming order was cancelled
+func (k Keeper) preventSelfTrade(ctx sdk.Context, order Order, wallOrder Order, askPrice Price) (remainingOrder Order, cancelled bool) {
+	switch order.SelfTradePrevention {
+	case STPCancelNewest:
+		return k.cancelIncomingOrder(ctx, order), true
+
+	case STPCancelOldest:
+		k.cancelWallOrder(ctx, wallOrder)
+		return order, false
+
+	case STPCancelBoth:
+		k.cancelWallOrder(ctx, wallOrder)
+		return k.cancelIncomingOrder(ctx, order), true
+
+	case STPDecrementAndCancel:
+		// size of the incoming order in units of the resting order's sellCoins
+		bidAtAskingPrice, _ := MulCoinsPrice(order.SellCoins, askPrice)
+
+		if bidAtAskingPrice.IsGTE(wallOrder.SellCoins) {
+			// the resting order is smaller, so decrement the incoming order by its size and cancel it
+			decrement, _ := MulCoinsPrice(wallOrder.SellCoins, askPrice.Reciprocal())
+			if decrement.IsGTE(order.SellCoins) {
+				decrement = order.SellCoins
+			}
+			k.coinKeeper.AddCoins(ctx, order.Owner, sdk.Coins{decrement})
+			order.SellCoins = order.SellCoins.Minus(decrement)
+			k.cancelWallOrder(ctx, wallOrder)
+
+			return order, !order.SellCoins.IsPositive()
+		}
+
+		// the incoming order is smaller, so decrement the resting order by its size and cancel it
+		// the resting order's OriginalCoins are left as is, so the decrement shows in its history
+		k.coinKeeper.AddCoins(ctx, wallOrder.Owner, sdk.Coins{bidAtAskingPrice})
+		wallOrder.SellCoins = wallOrder.SellCoins.Minus(bidAtAskingPrice)
+		k.SetOrder(ctx, wallOrder)
+		return k.cancelIncomingOrder(ctx, order), true
+	}
+
+	return order, false
+}
+
+// Refunds what's left of an incoming order that won't be placed in the orderwall
+func (k Keeper) cancelIncomingOrder(ctx sdk.Context, order Order) Order {
+	k.coinKeeper.AddCoins(ctx, order.Owner, sdk.Coins{order.TotalSellCoins()})
+	order.SellCoins = order.SellCoins.Minus(order.SellCoins)
+	order.Status = OrderStatusCancelled
+	return order
+}
+
+// Removes a resting order from the orderwall and refunds its owner
+func (k Keeper) cancelWallOrder(ctx sdk.Context, order Order) {
+	removedOrder := k.RemoveOrder(ctx, order.OrderID)
+	k.coinKeeper.AddCoins(ctx, removedOrder.Owner, sdk.Coins{removedOrder.TotalSellCoins()})
+}
@@ -0,0 +1,481 @@
+package orderbook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	dbm "github.com/tendermint/tendermint/libs/db"
+	"github.com/tendermint/tendermint/libs/log"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/store"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+	"github.com/cosmos/cosmos-sdk/x/params"
+)
+
+var (
+	alice = sdk.AccAddress([]byte("alice_______________"))
+	bob   = sdk.AccAddress([]byte("bob_________________"))
+)
+
+// creates a context and an orderbook Keeper backed by in-memory stores, with alice and bob funded
+// and a BTC/ETH market listed
+func createTestInput(t testing.TB) (sdk.Context, Keeper) {
+	keyAcc := sdk.NewKVStoreKey("acc")
+	keyOrderbook := sdk.NewKVStoreKey("orderbook")
+	keyFeeCollection := sdk.NewKVStoreKey("fee")
+	keyParams := sdk.NewKVStoreKey("params")
+	tkeyParams := sdk.NewTransientStoreKey("transient_params")
+
+	db := dbm.NewMemDB()
+	ms := store.NewCommitMultiStore(db)
+	ms.MountStoreWithDB(keyAcc, sdk.StoreTypeIAVL, db)
+	ms.MountStoreWithDB(keyOrderbook, sdk.StoreTypeIAVL, db)
+	ms.MountStoreWithDB(keyFeeCollection, sdk.StoreTypeIAVL, db)
+	ms.MountStoreWithDB(keyParams, sdk.StoreTypeIAVL, db)
+	ms.MountStoreWithDB(tkeyParams, sdk.StoreTypeTransient, db)
+	require.NoError(t, ms.LoadLatestVersion())
+
+	cdc := codec.New()
+	RegisterCodec(cdc)
+	auth.RegisterBaseAccount(cdc)
+	codec.RegisterCrypto(cdc)
+
+	ctx := sdk.NewContext(ms, abci.Header{ChainID: "test-chain"}, false, log.NewNopLogger())
+
+	accountKeeper := auth.NewAccountKeeper(cdc, keyAcc, auth.ProtoBaseAccount)
+	bankKeeper := bank.NewBaseKeeper(accountKeeper)
+	feeCollectionKeeper := auth.NewFeeCollectionKeeper(cdc, keyFeeCollection)
+	paramsKeeper := params.NewKeeper(cdc, keyParams, tkeyParams)
+	keeper := NewKeeper(bankKeeper, feeCollectionKeeper, newMockGovKeeper(), keyOrderbook, cdc,
+		paramsKeeper.Subspace(DefaultParamspace), DefaultCodespace)
+	InitGenesis(ctx, keeper, NewGenesisState(DefaultParams(), []Market{NewMarket("BTC", "ETH")}))
+
+	for _, addr := range []sdk.AccAddress{alice, bob} {
+		_, _, err := bankKeeper.AddCoins(ctx, addr, sdk.Coins{sdk.NewInt64Coin("BTC", 1000), sdk.NewInt64Coin("ETH", 1000)})
+		require.Nil(t, err)
+	}
+
+	return ctx, keeper
+}
+
+// changes only the MaxFills param
+func setMaxFills(ctx sdk.Context, keeper Keeper, maxFills int64) {
+	params := keeper.GetParams(ctx)
+	params.MaxFills = maxFills
+	keeper.SetParams(ctx, params)
+}
+
+// builds a MsgMakeOrder selling sellAmount of sellDenom at priceStr, in units of buyDenom/sellDenom
+func makeOrderMsg(owner sdk.AccAddress, sellAmount int64, sellDenom string, priceStr string, buyDenom string, stp SelfTradePrevention) MsgMakeOrder {
+	ratio, _ := sdk.NewDecFromStr(priceStr)
+	return NewMsgMakeOrder(
+		owner,
+		"",
+		sdk.NewInt64Coin(sellDenom, sellAmount),
+		NewPrice(ratio, buyDenom, sellDenom),
+		time.Time{},
+		sdk.ZeroInt(),
+		stp,
+	)
+}
+
+func TestSelfTradePrevention(t *testing.T) {
+	tests := []struct {
+		name string
+		// resting order selling BTC at 2 ETH/BTC, and its owner
+		maker       sdk.AccAddress
+		makerAmount int64
+		// incoming order selling 100 ETH at 0.5 BTC/ETH
+		stp SelfTradePrevention
+		// remaining SellCoins of the resting and incoming order, 0 if they are no longer in the orderwall
+		wantMakerLeft int64
+		wantTakerLeft int64
+		// alice's balances afterwards
+		wantBTC int64
+		wantETH int64
+	}{
+		{"none trades with itself", alice, 100, STPNone, 50, 0, 950, 1000},
+		{"cancel newest", alice, 100, STPCancelNewest, 100, 0, 900, 1000},
+		{"cancel oldest", alice, 100, STPCancelOldest, 0, 100, 1000, 900},
+		{"cancel both", alice, 100, STPCancelBoth, 0, 0, 1000, 1000},
+		{"decrement and cancel incoming", alice, 100, STPDecrementAndCancel, 50, 0, 950, 1000},
+		{"decrement and cancel resting", alice, 20, STPDecrementAndCancel, 0, 60, 1000, 940},
+		{"other owners still trade", bob, 100, STPCancelBoth, 50, 0, 1050, 900},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, keeper := createTestInput(t)
+			handler := NewHandler(keeper)
+
+			res := handler(ctx, makeOrderMsg(tt.maker, tt.makerAmount, "BTC", "2", "ETH", STPNone))
+			require.True(t, res.IsOK(), res.Log)
+
+			res = handler(ctx, makeOrderMsg(alice, 100, "ETH", "0.5", "BTC", tt.stp))
+			require.True(t, res.IsOK(), res.Log)
+
+			for orderID, wantLeft := range map[int64]int64{1: tt.wantMakerLeft, 2: tt.wantTakerLeft} {
+				order, found := keeper.GetOrder(ctx, orderID)
+				if wantLeft == 0 {
+					require.False(t, found, "order %d should not be in the orderwall", orderID)
+					continue
+				}
+				require.True(t, found, "order %d should be in the orderwall", orderID)
+				require.Equal(t, wantLeft, order.SellCoins.Amount.Int64())
+			}
+
+			coins := keeper.coinKeeper.GetCoins(ctx, alice)
+			require.Equal(t, tt.wantBTC, coins.AmountOf("BTC").Int64())
+			require.Equal(t, tt.wantETH, coins.AmountOf("ETH").Int64())
+		})
+	}
+}
+
+func TestClientOrderID(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	handler := NewHandler(keeper)
+
+	msg := makeOrderMsg(alice, 100, "BTC", "2", "ETH", STPNone)
+	msg.ClientOrderID = "bot-1"
+
+	res := handler(ctx, msg)
+	require.True(t, res.IsOK(), res.Log)
+
+	var result MakeOrderResult
+	require.NoError(t, keeper.cdc.UnmarshalJSON(res.Data, &result))
+	require.Equal(t, int64(1), result.OrderID)
+	require.Equal(t, "bot-1", result.ClientOrderID)
+	require.False(t, result.Consumed)
+
+	orderID, found := keeper.GetOrderIDByClientOrderID(ctx, alice, "bot-1")
+	require.True(t, found)
+	require.Equal(t, int64(1), orderID)
+
+	// resubmitting the same client order ID is rejected, but another owner can use it
+	res = handler(ctx, msg)
+	require.Equal(t, CodeDuplicateClientOrderID, res.Code)
+
+	msg.OwnerAddr = bob
+	res = handler(ctx, msg)
+	require.True(t, res.IsOK(), res.Log)
+
+	// bob's taker order fills against alice's order
+	bobTaker := makeOrderMsg(bob, 50, "ETH", "0.5", "BTC", STPNone)
+	res = handler(ctx, bobTaker)
+	require.True(t, res.IsOK(), res.Log)
+	require.NoError(t, keeper.cdc.UnmarshalJSON(res.Data, &result))
+	require.True(t, result.Consumed)
+	require.Equal(t, int64(50), result.FilledCoins.Amount.Int64())
+	require.Equal(t, int64(25), result.ReceivedCoins.Amount.Int64())
+
+	// only the owner can remove an order by its client order ID
+	res = handler(ctx, NewMsgRemoveOrder(bob, 1))
+	require.Equal(t, sdk.CodeUnauthorized, res.Code)
+
+	// removing an unknown client order ID names it rather than an order ID
+	res = handler(ctx, NewMsgRemoveOrderByClientOrderID(alice, "bot-2"))
+	require.Equal(t, CodeClientOrderIDNotFound, res.Code)
+	require.Contains(t, res.Log, "bot-2")
+
+	res = handler(ctx, NewMsgRemoveOrderByClientOrderID(alice, "bot-1"))
+	require.True(t, res.IsOK(), res.Log)
+	_, found = keeper.GetOrder(ctx, 1)
+	require.False(t, found)
+
+	// the client order ID stays taken after the order is removed
+	msg.OwnerAddr = alice
+	res = handler(ctx, msg)
+	require.Equal(t, CodeDuplicateClientOrderID, res.Code)
+}
+
+func TestOrderStatusAndHistory(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	handler := NewHandler(keeper)
+	now := time.Date(2018, 11, 1, 0, 0, 0, 0, time.UTC)
+	ctx = ctx.WithBlockHeader(abci.Header{ChainID: "test-chain", Height: 10, Time: now})
+
+	res := handler(ctx, makeOrderMsg(alice, 100, "BTC", "2", "ETH", STPNone))
+	require.True(t, res.IsOK(), res.Log)
+	res = handler(ctx, makeOrderMsg(bob, 50, "ETH", "0.5", "BTC", STPNone))
+	require.True(t, res.IsOK(), res.Log)
+
+	// alice's order is partially filled and still in the orderwall
+	order, found := keeper.GetOrder(ctx, 1)
+	require.True(t, found)
+	require.Equal(t, OrderStatusPartiallyFilled, order.Status)
+	require.Equal(t, int64(100), order.OriginalCoins.Amount.Int64())
+	require.Equal(t, int64(25), order.FilledCoins.Amount.Int64())
+	require.Equal(t, int64(50), order.ReceivedCoins.Amount.Int64())
+	require.True(t, order.AveragePrice.Equal(sdk.NewDec(2)))
+	require.Equal(t, int64(10), order.CreatedHeight)
+	require.Equal(t, now, order.CreatedTime)
+
+	// bob's order was filled right away and went straight to the order history
+	_, found = keeper.GetOrder(ctx, 2)
+	require.False(t, found)
+	order, found = keeper.GetClosedOrder(ctx, 2)
+	require.True(t, found)
+	require.Equal(t, OrderStatusFilled, order.Status)
+
+	res = handler(ctx, NewMsgRemoveOrder(alice, 1))
+	require.True(t, res.IsOK(), res.Log)
+	order, found = keeper.GetOrderOrClosedOrder(ctx, 1)
+	require.True(t, found)
+	require.Equal(t, OrderStatusCancelled, order.Status)
+	require.Equal(t, int64(25), order.FilledCoins.Amount.Int64())
+
+	// orders with an ExpirationTime are expired in the EndBlocker and refunded
+	msg := makeOrderMsg(alice, 100, "BTC", "2", "ETH", STPNone)
+	msg.ExpirationTime = now.Add(time.Hour)
+	res = handler(ctx, msg)
+	require.True(t, res.IsOK(), res.Log)
+
+	EndBlocker(ctx, keeper)
+	_, found = keeper.GetOrder(ctx, 3)
+	require.True(t, found)
+
+	ctx = ctx.WithBlockHeader(abci.Header{ChainID: "test-chain", Height: 11, Time: now.Add(time.Hour)})
+	EndBlocker(ctx, keeper)
+	order, found = keeper.GetOrderOrClosedOrder(ctx, 3)
+	require.True(t, found)
+	require.Equal(t, OrderStatusExpired, order.Status)
+	require.Equal(t, int64(975), keeper.coinKeeper.GetCoins(ctx, alice).AmountOf("BTC").Int64())
+
+	// closed orders are pruned once they're older than the retention limit
+	ctx = ctx.WithBlockHeader(abci.Header{ChainID: "test-chain", Height: 10 + OrderHistoryRetention, Time: now.Add(2 * time.Hour)})
+	EndBlocker(ctx, keeper)
+	_, found = keeper.GetClosedOrder(ctx, 1)
+	require.False(t, found)
+	_, found = keeper.GetClosedOrder(ctx, 3)
+	require.True(t, found)
+}
+
+func TestMaxFills(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	handler := NewHandler(keeper)
+	setMaxFills(ctx, keeper, 2)
+
+	for i := 0; i < 3; i++ {
+		res := handler(ctx, makeOrderMsg(bob, 10, "BTC", "2", "ETH", STPNone))
+		require.True(t, res.IsOK(), res.Log)
+	}
+
+	ctx = ctx.WithGasMeter(sdk.NewGasMeter(1000000))
+	res := handler(ctx, makeOrderMsg(alice, 100, "ETH", "0.5", "BTC", STPNone))
+	require.True(t, res.IsOK(), res.Log)
+	require.True(t, ctx.GasMeter().GasConsumed() >= 2*GasPerFill)
+
+	// alice's order was matched against two of bob's orders, and cancelled as it still crossed the third
+	order, found := keeper.GetClosedOrder(ctx, 4)
+	require.True(t, found)
+	require.Equal(t, OrderStatusCancelled, order.Status)
+	require.Equal(t, int64(40), order.FilledCoins.Amount.Int64())
+
+	coins := keeper.coinKeeper.GetCoins(ctx, alice)
+	require.Equal(t, int64(1020), coins.AmountOf("BTC").Int64())
+	require.Equal(t, int64(960), coins.AmountOf("ETH").Int64())
+
+	order, found = keeper.GetOrder(ctx, 3)
+	require.True(t, found)
+	require.Equal(t, OrderStatusOpen, order.Status)
+}
+
+func TestNonCrossingOrderOnlyReadsTopOfOrderwall(t *testing.T) {
+	gasUsed := func(wallSize int) sdk.Gas {
+		ctx, keeper := createTestInput(t)
+		handler := NewHandler(keeper)
+		for i := 0; i < wallSize; i++ {
+			res := handler(ctx, makeOrderMsg(bob, 10, "BTC", "2", "ETH", STPNone))
+			require.True(t, res.IsOK(), res.Log)
+		}
+
+		ctx = ctx.WithGasMeter(sdk.NewInfiniteGasMeter())
+		msg := makeOrderMsg(alice, 10, "ETH", "0.6", "BTC", STPNone)
+		_, consumed := keeper.ExecuteOrderAgainstOrderWall(ctx, Order{
+			OrderID:     keeper.GetNextOrderID(ctx),
+			Owner:       alice,
+			SellCoins:   msg.SellCoins,
+			BuyDenom:    "BTC",
+			Price:       msg.Price,
+			HiddenCoins: sdk.NewInt64Coin("ETH", 0),
+		})
+		require.False(t, consumed)
+		return ctx.GasMeter().GasConsumed()
+	}
+
+	// the walk of the orderwall stops at its first order, which doesn't cross
+	require.Equal(t, gasUsed(1), gasUsed(50))
+}
+
+func TestIcebergRefillKeepsPricePriority(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	handler := NewHandler(keeper)
+
+	// bob's iceberg order shows 10 of 30 BTC at 2 ETH/BTC, and his other order asks a worse 3 ETH/BTC
+	iceberg := makeOrderMsg(bob, 30, "BTC", "2", "ETH", STPNone)
+	iceberg.DisplayAmount = sdk.NewInt(10)
+	res := handler(ctx, iceberg)
+	require.True(t, res.IsOK(), res.Log)
+	res = handler(ctx, makeOrderMsg(bob, 10, "BTC", "3", "ETH", STPNone))
+	require.True(t, res.IsOK(), res.Log)
+
+	// alice's order crosses both, and takes every slice of the iceberg before the worse order
+	res = handler(ctx, makeOrderMsg(alice, 60, "ETH", "0.3", "BTC", STPNone))
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, int64(1030), keeper.coinKeeper.GetCoins(ctx, alice).AmountOf("BTC").Int64())
+	require.Equal(t, int64(940), keeper.coinKeeper.GetCoins(ctx, alice).AmountOf("ETH").Int64())
+
+	wall := keeper.PeekOrderwallOrders(ctx, NewDenomPair("BTC", "ETH"), 10)
+	require.Len(t, wall, 1)
+	require.Equal(t, int64(10), wall[0].SellCoins.Amount.Int64())
+	require.True(t, sdk.NewDec(3).Equal(wall[0].Price.Ratio))
+}
+
+func TestUpdateParams(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	handler := NewHandler(keeper)
+
+	params := DefaultParams()
+	params.MaxOrdersPerAccount = 2
+	params.Admin = alice
+
+	// without an admin the params can only be set through genesis or governance
+	res := handler(ctx, NewMsgUpdateParams(alice, params))
+	require.Equal(t, sdk.CodeUnauthorized, res.Code)
+
+	keeper.SetParams(ctx, params)
+
+	res = handler(ctx, NewMsgUpdateParams(bob, params))
+	require.Equal(t, sdk.CodeUnauthorized, res.Code)
+
+	invalid := params
+	invalid.MaxFills = 0
+	require.NotNil(t, NewMsgUpdateParams(alice, invalid).ValidateBasic())
+	res = handler(ctx, NewMsgUpdateParams(alice, invalid))
+	require.Equal(t, CodeInvalidParams, res.Code)
+
+	params.MaxPrice, _ = sdk.NewDecFromStr("3")
+	params.DefaultExpiry = time.Hour
+	res = handler(ctx, NewMsgUpdateParams(alice, params))
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, params.MaxPrice, keeper.GetParams(ctx).MaxPrice)
+	require.Equal(t, keeper.cdc.MustMarshalJSON(params), keeper.cdc.MustMarshalJSON(ExportGenesis(ctx, keeper).Params))
+
+	// orders above the max price are rejected
+	res = handler(ctx, makeOrderMsg(bob, 10, "BTC", "4", "ETH", STPNone))
+	require.Equal(t, CodeInvalidPriceRange, res.Code)
+
+	// orders without an expiration time get the default expiry
+	res = handler(ctx, makeOrderMsg(bob, 10, "BTC", "2", "ETH", STPNone))
+	require.True(t, res.IsOK(), res.Log)
+	order, found := keeper.GetOrder(ctx, keeper.GetLastOrderID(ctx))
+	require.True(t, found)
+	require.True(t, ctx.BlockHeader().Time.Add(time.Hour).Equal(order.ExpirationTime))
+
+	// bob can only have two orders resting in the orderwalls
+	res = handler(ctx, makeOrderMsg(bob, 10, "BTC", "2", "ETH", STPNone))
+	require.True(t, res.IsOK(), res.Log)
+	var result MakeOrderResult
+	keeper.cdc.MustUnmarshalJSON(res.Data, &result)
+	res = handler(ctx, makeOrderMsg(bob, 10, "BTC", "2", "ETH", STPNone))
+	require.Equal(t, CodeTooManyOrders, res.Code)
+
+	// until one of them leaves the orderwall
+	res = handler(ctx, NewMsgRemoveOrder(bob, result.OrderID))
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, int64(1), keeper.GetOpenOrderCount(ctx, bob))
+	res = handler(ctx, makeOrderMsg(bob, 10, "BTC", "2", "ETH", STPNone))
+	require.True(t, res.IsOK(), res.Log)
+}
+
+func TestTradingFees(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	handler := NewHandler(keeper)
+
+	params := keeper.GetParams(ctx)
+	params.MakerFeeRate = sdk.NewDecWithPrec(1, 2)
+	params.TakerFeeRate = sdk.NewDecWithPrec(2, 2)
+	keeper.SetParams(ctx, params)
+
+	// bob rests 100 BTC at 2 ETH/BTC, alice takes half of it for 100 ETH
+	res := handler(ctx, makeOrderMsg(bob, 100, "BTC", "2", "ETH", STPNone))
+	require.True(t, res.IsOK(), res.Log)
+	res = handler(ctx, makeOrderMsg(alice, 100, "ETH", "0.5", "BTC", STPNone))
+	require.True(t, res.IsOK(), res.Log)
+
+	var result MakeOrderResult
+	require.NoError(t, keeper.cdc.UnmarshalJSON(res.Data, &result))
+	require.Equal(t, int64(49), result.ReceivedCoins.Amount.Int64())
+
+	// the maker pays 1% of the 100 ETH it received and the taker 2% of the 50 BTC
+	coins := keeper.coinKeeper.GetCoins(ctx, bob)
+	require.Equal(t, int64(1099), coins.AmountOf("ETH").Int64())
+	coins = keeper.coinKeeper.GetCoins(ctx, alice)
+	require.Equal(t, int64(1049), coins.AmountOf("BTC").Int64())
+
+	fees := keeper.feeCollectionKeeper.(auth.FeeCollectionKeeper).GetCollectedFees(ctx)
+	require.Equal(t, int64(1), fees.AmountOf("BTC").Int64())
+	require.Equal(t, int64(1), fees.AmountOf("ETH").Int64())
+}
+
+func TestTradesDepthAndOwnerOrders(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	handler := NewHandler(keeper)
+	pair := NewDenomPair("BTC", "ETH")
+
+	// bob rests two orders at 2 ETH/BTC and alice takes half of the first one
+	res := handler(ctx, makeOrderMsg(bob, 100, "BTC", "2", "ETH", STPNone))
+	require.True(t, res.IsOK(), res.Log)
+	res = handler(ctx, makeOrderMsg(bob, 30, "BTC", "2", "ETH", STPNone))
+	require.True(t, res.IsOK(), res.Log)
+	res = handler(ctx, makeOrderMsg(alice, 100, "ETH", "0.5", "BTC", STPNone))
+	require.True(t, res.IsOK(), res.Log)
+	require.Contains(t, res.Tags, sdk.MakeTag(TagMarket, []byte("BTC|ETH")))
+	require.Contains(t, res.Tags, sdk.MakeTag(TagTradeID, []byte("1")))
+
+	// the trade is found from either direction of the pair
+	trades := keeper.GetRecentTrades(ctx, pair.ReversePair(), 10)
+	require.Len(t, trades, 1)
+	require.Equal(t, bob, trades[0].Maker)
+	require.Equal(t, alice, trades[0].Taker)
+	require.Equal(t, sdk.NewInt64Coin("BTC", 50), trades[0].MakerSold)
+	require.Equal(t, sdk.NewInt64Coin("ETH", 100), trades[0].TakerSold)
+
+	// alice rests a bid below the asks
+	res = handler(ctx, makeOrderMsg(alice, 20, "ETH", "0.8", "BTC", STPNone))
+	require.True(t, res.IsOK(), res.Log)
+
+	depth := keeper.GetDepth(ctx, pair, DefaultDepthLevels)
+	require.Len(t, depth.Asks, 1)
+	require.True(t, depth.Asks[0].Price.Equal(sdk.NewDec(2)))
+	require.Equal(t, int64(80), depth.Asks[0].Amount.Int64())
+	require.Len(t, depth.Bids, 1)
+	require.True(t, depth.Bids[0].Price.Equal(sdk.NewDecWithPrec(125, 2)))
+	require.Equal(t, int64(16), depth.Bids[0].Amount.Int64())
+
+	// only resting orders are indexed by owner
+	require.Len(t, keeper.GetOwnerOrders(ctx, bob), 2)
+	require.Len(t, keeper.GetOwnerOrders(ctx, alice), 1)
+	keeper.RemoveOrder(ctx, keeper.GetOwnerOrders(ctx, bob)[0].OrderID)
+	require.Len(t, keeper.GetOwnerOrders(ctx, bob), 1)
+}
+
+func TestQuerierOnlyServesLatestHeight(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	ctx = ctx.WithBlockHeight(10)
+	querier := NewQuerier(keeper)
+
+	for _, height := range []int64{0, 10} {
+		_, err := querier(ctx, []string{QueryMarkets}, abci.RequestQuery{Height: height})
+		require.Nil(t, err)
+	}
+
+	// an earlier height would otherwise be answered from the latest state
+	_, err := querier(ctx, []string{QueryMarkets}, abci.RequestQuery{Height: 9})
+	require.NotNil(t, err)
+}
@@ -0,0 +1,152 @@
+package orderbook
+
+import (
+	"bytes"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Keys of the orderbook store are made of components, each written as its length in a byte followed by its bytes,
+// starting with the name of what the key is for.  Whatever bytes the components contain, a key splits back into
+// the same components, and the keys under some components never include keys with other components, e.g. the
+// orderwall of one pair never reaches into the orderwall of another.
+// Components of a fixed length, like IDs, keep their sort order.  Shorter variable length components sort first,
+// which keeps the sort order of prices too, as the prices written with more bytes than others are the larger ones
+
+// Longest component a key can have
+const MaxKeyComponentLength = 255
+
+// Returns a key made of components
+func KeyFromComponents(components ...[]byte) []byte {
+	return AppendKeyComponents(nil, components...)
+}
+
+// Returns a new key made of a key followed by components
+func AppendKeyComponents(key []byte, components ...[]byte) []byte {
+	size := len(key)
+	for _, component := range components {
+		size += 1 + len(component)
+	}
+
+	bz := make([]byte, len(key), size)
+	copy(bz, key)
+	for _, component := range components {
+		if len(component) > MaxKeyComponentLength {
+			panic(fmt.Sprintf("key component of %d bytes is longer than %d bytes", len(component), MaxKeyComponentLength))
+		}
+		bz = append(bz, byte(len(component)))
+		bz = append(bz, component...)
+	}
+	return bz
+}
+
+// Splits a key into its components
+func SplitKeyComponents(key []byte) (components [][]byte, err error) {
+	for len(key) > 0 {
+		length := int(key[0])
+		if len(key) < 1+length {
+			return nil, fmt.Errorf("key component of %d bytes is cut off after %d bytes", length, len(key)-1)
+		}
+		components = append(components, key[1:1+length])
+		key = key[1+length:]
+	}
+	return components, nil
+}
+
+// Splits the components of a key after prefix, which must have count of them
+func splitKeyAfterPrefix(key []byte, prefix []byte, count int) ([][]byte, error) {
+	if !bytes.HasPrefix(key, prefix) {
+		return nil, fmt.Errorf("key %X doesn't start with %X", key, prefix)
+	}
+	components, err := SplitKeyComponents(key[len(prefix):])
+	if err != nil {
+		return nil, err
+	}
+	if len(components) != count {
+		return nil, fmt.Errorf("key %X has %d components after its prefix instead of %d", key, len(components), count)
+	}
+	return components, nil
+}
+
+// Parses a component made by Int64ToSortableBytes
+func parseInt64Component(component []byte) (int64, error) {
+	if len(component) != 8 {
+		return 0, fmt.Errorf("integer key component has %d bytes instead of 8", len(component))
+	}
+	return SortableBytesToInt64(component), nil
+}
+
+// Returns the components of a DenomPair in a key
+func pairComponents(pair DenomPair) [][]byte {
+	return [][]byte{[]byte(pair.SellDenom), []byte(pair.BuyDenom)}
+}
+
+// ------------------------------------------------------------
+
+// get key in store to get an Order
+func OrderKey(orderID int64) []byte {
+	return AppendKeyComponents(ordersPrefix, Int64ToSortableBytes(orderID))
+}
+
+// Parses the key of an Order back into its orderID
+func ParseOrderKey(key []byte) (orderID int64, err error) {
+	components, err := splitKeyAfterPrefix(key, ordersPrefix, 1)
+	if err != nil {
+		return 0, err
+	}
+	return parseInt64Component(components[0])
+}
+
+// OrderwallKey is where an order rests in an orderwall: its pair, then its price, then its place at that price
+type OrderwallKey struct {
+	Pair         DenomPair
+	Price        sdk.Dec
+	WallSequence int64
+}
+
+// Returns the OrderwallKey of an order
+func NewOrderwallKey(order Order) OrderwallKey {
+	return OrderwallKey{
+		Pair:         order.Pair(),
+		Price:        order.Price.Ratio,
+		WallSequence: order.WallSequence,
+	}
+}
+
+// Returns the key in store of an order in an orderwall
+func (key OrderwallKey) Bytes() []byte {
+	return AppendKeyComponents(OrderwallPrefix(key.Pair), SortableSDKDecBytes(key.Price), Int64ToSortableBytes(key.WallSequence))
+}
+
+// Parses the key in store of an order in an orderwall
+func ParseOrderwallKey(key []byte) (orderwallKey OrderwallKey, err error) {
+	components, err := splitKeyAfterPrefix(key, orderwallPrefix, 4)
+	if err != nil {
+		return orderwallKey, err
+	}
+	price, err := ParseSortableSDKDecBytes(components[2])
+	if err != nil {
+		return orderwallKey, err
+	}
+	wallSequence, err := parseInt64Component(components[3])
+	if err != nil {
+		return orderwallKey, err
+	}
+	return OrderwallKey{
+		Pair:         NewDenomPair(string(components[0]), string(components[1])),
+		Price:        price,
+		WallSequence: wallSequence,
+	}, nil
+}
+
+// returns a prefix for storing all orders in the orderwall of a specific DenomPair
+func OrderwallPrefix(pair DenomPair) []byte {
+	return AppendKeyComponents(orderwallPrefix, pairComponents(pair)...)
+}
+
+// Returns the key for getting an orderID in an orderWall.
+// Orders at the same price are sorted by their wallSequence
+func OrderwallOrderKey(pair DenomPair, price Price, wallSequence int64) []byte {
+	return OrderwallKey{Pair: pair, Price: price.Ratio, WallSequence: wallSequence}.Bytes()
+}
@@ -0,0 +1,179 @@
+package orderbook
+
+import (
+	"bytes"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// bytes that used to separate the parts of keys and pairs, and lengths that components start with
+var adversarialBytes = []byte{'/', '|', 'A', 'B', 0x00, 0x01, 0x03, 0x08, 0x14, 0xFF}
+
+// returns a random component of up to maxLength bytes, mostly made of adversarialBytes
+func randomComponent(r *rand.Rand, maxLength int) []byte {
+	component := make([]byte, r.Intn(maxLength+1))
+	for i := range component {
+		if r.Intn(4) == 0 {
+			component[i] = byte(r.Intn(256))
+		} else {
+			component[i] = adversarialBytes[r.Intn(len(adversarialBytes))]
+		}
+	}
+	return component
+}
+
+// returns a random pair of denoms made of adversarialBytes
+func randomPair(r *rand.Rand) DenomPair {
+	return NewDenomPair(string(randomComponent(r, 6)), string(randomComponent(r, 6)))
+}
+
+// returns a random OrderwallKey of pair, some with prices up to the largest that can be written in a key
+func randomOrderwallKey(r *rand.Rand, pair DenomPair) OrderwallKey {
+	price := sdk.NewDecWithPrec(r.Int63n(1000000000000000000)+1, sdk.Precision)
+	if r.Intn(3) == 0 {
+		price = sdk.NewDec(r.Int63n(maxDec.TruncateInt64()) + 1)
+	}
+	return OrderwallKey{
+		Pair:         pair,
+		Price:        price,
+		WallSequence: r.Int63(),
+	}
+}
+
+func TestKeyComponentsRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		var components [][]byte
+		for j := r.Intn(6); j > 0; j-- {
+			components = append(components, randomComponent(r, 40))
+		}
+
+		key := KeyFromComponents(components...)
+		split, err := SplitKeyComponents(key)
+		require.NoError(t, err)
+		require.Equal(t, components, split)
+
+		// a key that's cut off in its last component doesn't split
+		if len(components) > 0 && len(components[len(components)-1]) > 0 {
+			_, err = SplitKeyComponents(key[:len(key)-1])
+			require.Error(t, err)
+		}
+	}
+
+	// appending to a key doesn't change it, even when it has room to grow
+	prefix := make([]byte, 1, 10)
+	first := AppendKeyComponents(prefix, []byte("a"))
+	AppendKeyComponents(prefix, []byte("b"))
+	require.Equal(t, []byte{0x00, 0x01, 'a'}, first)
+
+	require.Panics(t, func() { KeyFromComponents(make([]byte, MaxKeyComponentLength+1)) })
+}
+
+func TestOrderKeysRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 1000; i++ {
+		orderID := r.Int63()
+		parsedID, err := ParseOrderKey(OrderKey(orderID))
+		require.NoError(t, err)
+		require.Equal(t, orderID, parsedID)
+
+		key := randomOrderwallKey(r, randomPair(r))
+		parsed, err := ParseOrderwallKey(key.Bytes())
+		require.NoError(t, err)
+		require.Equal(t, key.Pair, parsed.Pair)
+		require.True(t, key.Price.Equal(parsed.Price), "%v != %v", key.Price, parsed.Price)
+		require.Equal(t, key.WallSequence, parsed.WallSequence)
+
+		// neither kind of key parses as the other
+		_, err = ParseOrderKey(key.Bytes())
+		require.Error(t, err)
+		_, err = ParseOrderwallKey(OrderKey(orderID))
+		require.Error(t, err)
+	}
+}
+
+func TestOrderwallKeyAtMaxPrice(t *testing.T) {
+	for _, price := range []sdk.Dec{DefaultParams().MaxPrice, maxDec, sdk.NewDec(1000000000)} {
+		key := OrderwallKey{Pair: NewDenomPair("BTC", "ETH"), Price: price, WallSequence: 1}
+		parsed, err := ParseOrderwallKey(key.Bytes())
+		require.NoError(t, err)
+		require.True(t, price.Equal(parsed.Price), "%v != %v", price, parsed.Price)
+
+		// it sorts after a price just below it
+		below := key
+		below.Price = price.Sub(sdk.NewDecWithPrec(1, sdk.Precision))
+		require.True(t, bytes.Compare(below.Bytes(), key.Bytes()) < 0, "%v sorts after %v", below.Price, price)
+	}
+}
+
+func TestOrderwallKeysSortByPriceThenSequence(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	pair := NewDenomPair("A|B", "/C")
+	var keys []OrderwallKey
+	for i := 0; i < 500; i++ {
+		key := randomOrderwallKey(r, pair)
+		// make orders share prices
+		if i > 0 && r.Intn(3) == 0 {
+			key.Price = keys[r.Intn(len(keys))].Price
+		}
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i].Bytes(), keys[j].Bytes()) < 0 })
+	for i := 1; i < len(keys); i++ {
+		prev, key := keys[i-1], keys[i]
+		require.True(t, prev.Price.LT(key.Price) || (prev.Price.Equal(key.Price) && prev.WallSequence <= key.WallSequence),
+			"%v@%d sorts before %v@%d", prev.Price, prev.WallSequence, key.Price, key.WallSequence)
+	}
+}
+
+func TestOrderWallIteratorStaysInItsPair(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	store := ctx.KVStore(keeper.storeKey)
+	r := rand.New(rand.NewSource(4))
+
+	// pairs whose keys used to overlap, then random ones
+	pairs := []DenomPair{
+		NewDenomPair("A", "B|C"), NewDenomPair("A|B", "C"),
+		NewDenomPair("A", "B"), NewDenomPair("A", "B/"), NewDenomPair("A/", "B"), NewDenomPair("A", "B/0"),
+		NewDenomPair("", "A"), NewDenomPair("A", ""), NewDenomPair("\x01A", "B"), NewDenomPair("\x01", "AB"),
+	}
+	for i := 0; i < 40; i++ {
+		pairs = append(pairs, randomPair(r))
+	}
+
+	counts := make(map[string]int)
+	for _, pair := range pairs {
+		for i := r.Intn(5); i >= 0; i-- {
+			store.Set(randomOrderwallKey(r, pair).Bytes(), keeper.cdc.MustMarshalBinaryBare(int64(i)))
+		}
+		counts[string(OrderwallPrefix(pair))] = 0
+	}
+
+	// count the keys of every pair apart from the keys of the others
+	iterator := store.Iterator(orderwallPrefix, sdk.PrefixEndBytes(orderwallPrefix))
+	for ; iterator.Valid(); iterator.Next() {
+		key, err := ParseOrderwallKey(iterator.Key())
+		require.NoError(t, err)
+		counts[string(OrderwallPrefix(key.Pair))]++
+	}
+	iterator.Close()
+
+	for _, pair := range pairs {
+		found := 0
+		iterator := keeper.OrderWallIterator(ctx, pair)
+		for ; iterator.Valid(); iterator.Next() {
+			key, err := ParseOrderwallKey(iterator.Key())
+			require.NoError(t, err)
+			require.Equal(t, pair, key.Pair, "orderwall of %q reaches into %q", pair, key.Pair)
+			found++
+		}
+		iterator.Close()
+		require.Equal(t, counts[string(OrderwallPrefix(pair))], found)
+	}
+}
@@ -0,0 +1,154 @@
+package orderbook
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+var marketsPrefix = KeyFromComponents([]byte("markets"))
+
+// Statuses of a Market
+const (
+	MarketStatusActive = "active"
+	MarketStatusHalted = "halted"
+)
+
+// Market is a listed pair of denoms that can be traded against each other, in both directions.
+// Orders can only be made in active markets.  A halted market keeps its resting orders, which can still be removed
+type Market struct {
+	BaseDenom  string `json:"base_denom"`
+	QuoteDenom string `json:"quote_denom"`
+	Status     string `json:"status"`
+}
+
+func NewMarket(baseDenom, quoteDenom string) Market {
+	return Market{
+		BaseDenom:  baseDenom,
+		QuoteDenom: quoteDenom,
+		Status:     MarketStatusActive,
+	}
+}
+
+// Returns the DenomPair of orders selling BaseDenom for QuoteDenom
+func (m Market) Pair() DenomPair {
+	return NewDenomPair(m.BaseDenom, m.QuoteDenom)
+}
+
+func (m Market) String() string {
+	return fmt.Sprintf("%s/%s (%s)", m.BaseDenom, m.QuoteDenom, m.Status)
+}
+
+// Checks that a market is between two different, valid denoms
+func (m Market) Validate() error {
+	if m.BaseDenom == "" || m.QuoteDenom == "" || m.BaseDenom == m.QuoteDenom {
+		return fmt.Errorf("market %s/%s must be between two different denoms", m.BaseDenom, m.QuoteDenom)
+	}
+	if _, err := sdk.ParseCoin("1" + m.BaseDenom); err != nil {
+		return fmt.Errorf("invalid denom %s", m.BaseDenom)
+	}
+	if _, err := sdk.ParseCoin("1" + m.QuoteDenom); err != nil {
+		return fmt.Errorf("invalid denom %s", m.QuoteDenom)
+	}
+	if m.Status != MarketStatusActive && m.Status != MarketStatusHalted {
+		return fmt.Errorf("unknown market status %s", m.Status)
+	}
+	return nil
+}
+
+// get key in store to get the Market of a DenomPair.
+// Both directions of a pair share the same key
+func MarketKey(pair DenomPair) []byte {
+	return AppendKeyComponents(marketsPrefix, pairComponents(marketPair(pair))...)
+}
+
+// Returns the direction of a pair that identifies its market, with the denoms in sorted order
+func marketPair(pair DenomPair) DenomPair {
+	if pair.SellDenom > pair.BuyDenom {
+		return pair.ReversePair()
+	}
+	return pair
+}
+
+// Gets the Market orders of a DenomPair are made in
+func (k Keeper) GetMarket(ctx sdk.Context, pair DenomPair) (market Market, found bool) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(MarketKey(pair))
+	if bz == nil {
+		return market, false
+	}
+	k.cdc.MustUnmarshalBinaryBare(bz, &market)
+	return market, true
+}
+
+// Sets a Market in the Store
+func (k Keeper) SetMarket(ctx sdk.Context, market Market) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(MarketKey(market.Pair()), k.cdc.MustMarshalBinaryBare(market))
+}
+
+// Gets all listed Markets
+func (k Keeper) GetMarkets(ctx sdk.Context) (markets []Market) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := store.Iterator(marketsPrefix, sdk.PrefixEndBytes(marketsPrefix))
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var market Market
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &market)
+		markets = append(markets, market)
+	}
+	return markets
+}
+
+// Returns whether orders can be made for a DenomPair
+func (k Keeper) IsMarketActive(ctx sdk.Context, pair DenomPair) bool {
+	market, found := k.GetMarket(ctx, pair)
+	return found && market.Status == MarketStatusActive
+}
+
+// Lists a new market, or resumes trading in a halted one
+func (k Keeper) ListMarket(ctx sdk.Context, market Market) {
+	market.Status = MarketStatusActive
+	k.SetMarket(ctx, market)
+}
+
+// Stops new orders from being made in a market
+func (k Keeper) HaltMarket(ctx sdk.Context, pair DenomPair) sdk.Error {
+	market, found := k.GetMarket(ctx, pair)
+	if !found {
+		return ErrMarketNotFound(k.codespace, pair)
+	}
+	market.Status = MarketStatusHalted
+	k.SetMarket(ctx, market)
+	return nil
+}
+
+// Removes a market, cancelling and refunding all orders resting in both of its orderwalls
+// and all active TWAPOrders making slices in it
+func (k Keeper) DelistMarket(ctx sdk.Context, pair DenomPair) sdk.Error {
+	if _, found := k.GetMarket(ctx, pair); !found {
+		return ErrMarketNotFound(k.codespace, pair)
+	}
+
+	for _, twap := range k.GetActiveTWAPOrders(ctx, pair) {
+		if _, err := k.CancelTWAPOrder(ctx, twap.TWAPID); err != nil {
+			return err
+		}
+	}
+
+	for _, wallPair := range []DenomPair{pair, pair.ReversePair()} {
+		for {
+			orders := k.PeekOrderwallOrders(ctx, wallPair, 100)
+			if len(orders) == 0 {
+				break
+			}
+			for _, order := range orders {
+				k.cancelWallOrder(ctx, order)
+			}
+		}
+	}
+
+	ctx.KVStore(k.storeKey).Delete(MarketKey(pair))
+	return nil
+}
@@ -0,0 +1,187 @@
+package orderbook
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Keys of schema version 1 are the name of what the key is for and its parts, all joined by a "/".
+// As IDs, prices and denoms can contain a "/" themselves, they are taken apart from their known lengths instead,
+// or rebuilt from the value they're the key of
+const legacyKeySeperator = '/'
+
+// Rewrites every key of the orderbook store from the "/" joined keys of schema version 1 to length-prefixed components.
+// The versions of the orderwalls are dropped, as they only tell cached orderwalls apart and the cache is dropped after migrating
+func migrateLengthPrefixedKeys(ctx sdk.Context, k Keeper) error {
+	store := ctx.KVStore(k.storeKey)
+
+	legacy := make(map[string][]byte)
+	var legacyKeys []string
+	iterator := store.Iterator(nil, nil)
+	for ; iterator.Valid(); iterator.Next() {
+		if bytes.Equal(iterator.Key(), schemaVersionKey) {
+			continue
+		}
+		legacyKeys = append(legacyKeys, string(iterator.Key()))
+		legacy[string(iterator.Key())] = append([]byte{}, iterator.Value()...)
+	}
+	iterator.Close()
+
+	// two keys migrating to the same key would lose one of their values, so the migration fails instead
+	migrated := make(map[string][]byte)
+	migratedFrom := make(map[string]string)
+	for _, key := range legacyKeys {
+		newKey, value, err := k.migrateLegacyKey(legacy, []byte(key))
+		if err != nil {
+			return err
+		}
+		if newKey == nil {
+			continue
+		}
+		if other, found := migratedFrom[string(newKey)]; found {
+			return fmt.Errorf("keys %X and %X both migrate to key %X", other, key, newKey)
+		}
+		migrated[string(newKey)] = value
+		migratedFrom[string(newKey)] = key
+	}
+
+	var newKeys []string
+	for key := range migrated {
+		newKeys = append(newKeys, key)
+	}
+	sort.Strings(newKeys)
+
+	for _, key := range legacyKeys {
+		store.Delete([]byte(key))
+	}
+	for _, key := range newKeys {
+		store.Set([]byte(key), migrated[key])
+	}
+	return nil
+}
+
+// Returns the key of schema version 2 of a key of schema version 1 and the value to set it to, or a nil key if it's dropped
+func (k Keeper) migrateLegacyKey(legacy map[string][]byte, key []byte) (newKey []byte, value []byte, err error) {
+	value = legacy[string(key)]
+	i := bytes.IndexByte(key, legacyKeySeperator)
+	if i < 0 {
+		switch string(key) {
+		case "lastOrderID":
+			return lastOrderIDKey, value, nil
+		case "lastWallSequence":
+			return lastWallSequenceKey, value, nil
+		case "lastTradeID":
+			return lastTradeIDKey, value, nil
+		case "lastTWAPID":
+			return lastTWAPIDKey, value, nil
+		}
+		return nil, nil, fmt.Errorf("unknown key %X", key)
+	}
+	name, rest := string(key[:i]), key[i+1:]
+
+	switch name {
+	case "orders", "orderHistory", "twapOrders", "proposalActions":
+		if len(rest) != 8 {
+			return nil, nil, fmt.Errorf("key %X doesn't end in an ID", key)
+		}
+		prefixes := map[string][]byte{
+			"orders":          ordersPrefix,
+			"orderHistory":    orderHistoryPrefix,
+			"twapOrders":      twapOrdersPrefix,
+			"proposalActions": proposalActionsPrefix,
+		}
+		return AppendKeyComponents(prefixes[name], rest), value, nil
+
+	case "orderHistoryQueue", "twapQueue", "tradeQueue":
+		height, id, err := splitLegacyKeyTail(key, rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(height) != 8 {
+			return nil, nil, fmt.Errorf("key %X doesn't start with a height", key)
+		}
+		switch name {
+		case "orderHistoryQueue":
+			return AppendKeyComponents(orderHistoryQueuePrefix, height, id), value, nil
+		case "twapQueue":
+			return AppendKeyComponents(twapQueuePrefix, height, id), value, nil
+		}
+		// the queue of trades holds the keys of the trades
+		var trade Trade
+		err = k.cdc.UnmarshalBinaryBare(legacy[string(value)], &trade)
+		if err != nil {
+			return nil, nil, fmt.Errorf("key %X is queued for trade %X that can't be read: %v", key, value, err)
+		}
+		return AppendKeyComponents(tradeQueuePrefix, height, id), TradeKey(trade.Pair(), trade.TradeID), nil
+
+	case "expirationQueue":
+		expirationTime, orderID, err := splitLegacyKeyTail(key, rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		return AppendKeyComponents(expirationQueuePrefix, expirationTime, orderID), value, nil
+
+	case "ownerOrders":
+		owner, orderID, err := splitLegacyKeyTail(key, rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		return AppendKeyComponents(ownerOrdersPrefix, owner, orderID), value, nil
+
+	case "openOrderCounts":
+		return OpenOrderCountKey(rest), value, nil
+
+	case "clientOrderIDs":
+		// valid client order IDs never contain a "/", unlike owners
+		j := bytes.LastIndexByte(rest, legacyKeySeperator)
+		if j < 0 {
+			return nil, nil, fmt.Errorf("key %X doesn't have a client order ID", key)
+		}
+		return ClientOrderIDKey(rest[:j], string(rest[j+1:])), value, nil
+
+	case "orderwalls":
+		var orderID int64
+		err = k.cdc.UnmarshalBinaryBare(value, &orderID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("key %X doesn't hold an orderID: %v", key, err)
+		}
+		var order Order
+		err = k.cdc.UnmarshalBinaryBare(legacy["orders/"+string(Int64ToSortableBytes(orderID))], &order)
+		if err != nil {
+			return nil, nil, fmt.Errorf("key %X holds order %d that can't be read: %v", key, orderID, err)
+		}
+		return NewOrderwallKey(order).Bytes(), value, nil
+
+	case "orderwallVersions":
+		return nil, nil, nil
+
+	case "markets":
+		var market Market
+		err = k.cdc.UnmarshalBinaryBare(value, &market)
+		if err != nil {
+			return nil, nil, fmt.Errorf("key %X doesn't hold a market: %v", key, err)
+		}
+		return MarketKey(market.Pair()), value, nil
+
+	case "trades":
+		var trade Trade
+		err = k.cdc.UnmarshalBinaryBare(value, &trade)
+		if err != nil {
+			return nil, nil, fmt.Errorf("key %X doesn't hold a trade: %v", key, err)
+		}
+		return TradeKey(trade.Pair(), trade.TradeID), value, nil
+	}
+	return nil, nil, fmt.Errorf("unknown key %X", key)
+}
+
+// Splits the ID at the end of a key of schema version 1 from the part of the key before it
+func splitLegacyKeyTail(key []byte, rest []byte) (head []byte, id []byte, err error) {
+	i := len(rest) - 9
+	if i < 0 || rest[i] != legacyKeySeperator {
+		return nil, nil, fmt.Errorf("key %X doesn't end in an ID", key)
+	}
+	return rest[:i], rest[i+1:], nil
+}
@@ -0,0 +1,103 @@
+package orderbook
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// The schema version is kept at the same key in every schema version, so that it can always be read
+var schemaVersionKey = []byte("schemaVersion")
+
+// Schema version of an orderbook store written before schema versions were kept in the store
+const InitialSchemaVersion int64 = 1
+
+// Migration rewrites the orderbook store from the schema version before Version to Version.
+// Migrations are written against the raw store, as the Keeper only reads and writes the latest layout
+type Migration struct {
+	Version int64
+	Name    string
+	Migrate func(ctx sdk.Context, k Keeper) error
+}
+
+// migrations of the orderbook store, in the order they're run
+var migrations = []Migration{
+	{2, "length-prefixed-keys", migrateLengthPrefixedKeys},
+}
+
+// Returns the migrations of the orderbook store, in the order they're run
+func Migrations() []Migration {
+	return append([]Migration(nil), migrations...)
+}
+
+// Returns the schema version of the orderbook store this code reads and writes
+func SchemaVersion() int64 {
+	return latestSchemaVersion(migrations)
+}
+
+// Returns the schema version a store ends up at once all the migrations have run
+func latestSchemaVersion(migrations []Migration) int64 {
+	if len(migrations) == 0 {
+		return InitialSchemaVersion
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// Returns a copy of the Keeper that migrates the store to the schema version of this code at height
+func (k Keeper) WithUpgradeHeight(height int64) Keeper {
+	k.upgradeHeight = height
+	return k
+}
+
+// Gets the schema version of the orderbook store
+func (k Keeper) GetSchemaVersion(ctx sdk.Context) (version int64) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(schemaVersionKey)
+	if bz == nil {
+		return InitialSchemaVersion
+	}
+	k.cdc.MustUnmarshalBinaryBare(bz, &version)
+	return version
+}
+
+// Sets the schema version of the orderbook store
+func (k Keeper) SetSchemaVersion(ctx sdk.Context, version int64) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(schemaVersionKey, k.cdc.MustMarshalBinaryBare(version))
+}
+
+// Runs the migrations the orderbook store hasn't been through yet, in order
+func (k Keeper) MigrateStore(ctx sdk.Context) (sdk.Tags, error) {
+	return k.runMigrations(ctx, migrations)
+}
+
+// Runs the migrations with a later version than the store's, in order.  The store is only written if they all succeed.
+// The cached orderwalls are dropped afterwards, as their keys may have changed
+func (k Keeper) runMigrations(ctx sdk.Context, migrations []Migration) (tags sdk.Tags, err error) {
+	version := k.GetSchemaVersion(ctx)
+	if latest := latestSchemaVersion(migrations); version > latest {
+		return nil, fmt.Errorf("orderbook store is at schema version %d, later than the latest known version %d", version, latest)
+	}
+
+	cacheCtx, write := ctx.CacheContext()
+	for _, migration := range migrations {
+		if migration.Version <= version {
+			continue
+		}
+		if migration.Version != version+1 {
+			return nil, fmt.Errorf("migration %s to schema version %d doesn't follow version %d", migration.Name, migration.Version, version)
+		}
+
+		err = migration.Migrate(cacheCtx, k)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s to schema version %d failed: %v", migration.Name, migration.Version, err)
+		}
+		version = migration.Version
+		k.SetSchemaVersion(cacheCtx, version)
+		tags = tags.AppendTag(TagSchemaVersion, idTagValue(version))
+	}
+	write()
+
+	k.dropOrderbookCache()
+	return tags, nil
+}
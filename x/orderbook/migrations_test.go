@@ -0,0 +1,352 @@
+package orderbook
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+var testMigrationPrefix = []byte("migrated/")
+
+// moves every key of the orderbook store under from, except the schema version, to be under to instead
+func moveKeys(ctx sdk.Context, k Keeper, from []byte, to []byte) error {
+	store := ctx.KVStore(k.storeKey)
+	var keys, values [][]byte
+	iterator := sdk.KVStorePrefixIterator(store, from)
+	for ; iterator.Valid(); iterator.Next() {
+		if bytes.Equal(iterator.Key(), schemaVersionKey) {
+			continue
+		}
+		keys = append(keys, append([]byte{}, iterator.Key()...))
+		values = append(values, append([]byte{}, iterator.Value()...))
+	}
+	iterator.Close()
+
+	for i, key := range keys {
+		store.Delete(key)
+		store.Set(append(append([]byte{}, to...), key[len(from):]...), values[i])
+	}
+	return nil
+}
+
+// returns the registered migrations followed by two that move the whole store under a prefix and back,
+// so that the layout the Keeper reads is only there again at the end
+func testMigrations() []Migration {
+	version := SchemaVersion()
+	return append(Migrations(),
+		Migration{version + 1, "move-under-prefix", func(ctx sdk.Context, k Keeper) error { return moveKeys(ctx, k, nil, testMigrationPrefix) }},
+		Migration{version + 2, "move-back", func(ctx sdk.Context, k Keeper) error { return moveKeys(ctx, k, testMigrationPrefix, nil) }},
+	)
+}
+
+// makes resting, partially filled, iceberg, expiring and closed orders, a trade and a TWAPOrder.
+// Returns the query paths that show them
+func populateOrderbook(t *testing.T, ctx sdk.Context, keeper Keeper) (paths []string) {
+	handler := NewHandler(keeper)
+	ratio := func(str string) sdk.Dec {
+		dec, _ := sdk.NewDecFromStr(str)
+		return dec
+	}
+
+	msgs := []sdk.Msg{
+		NewMsgMakeOrder(alice, "a1", sdk.NewInt64Coin("BTC", 100), NewPrice(ratio("2"), "ETH", "BTC"), time.Time{}, sdk.ZeroInt(), STPNone),
+		NewMsgMakeOrder(alice, "", sdk.NewInt64Coin("BTC", 50), NewPrice(ratio("3"), "ETH", "BTC"), time.Time{}, sdk.NewInt(10), STPNone),
+		makeOrderMsg(bob, 100, "ETH", "0.5", "BTC", STPNone),
+		makeOrderMsg(bob, 40, "ETH", "0.8", "BTC", STPNone),
+		makeOrderMsg(bob, 10, "ETH", "0.8", "BTC", STPNone),
+		NewMsgRemoveOrder(bob, 5),
+		NewMsgMakeOrder(alice, "", sdk.NewInt64Coin("BTC", 10), NewPrice(ratio("4"), "ETH", "BTC"),
+			ctx.BlockHeader().Time.Add(time.Hour), sdk.ZeroInt(), STPNone),
+		NewMsgMakeTWAPOrder(bob, sdk.NewInt64Coin("ETH", 30), NewPrice(ratio("0.1"), "BTC", "ETH"), true, sdk.NewInt(10), 1),
+	}
+	for _, msg := range msgs {
+		res := handler(ctx, msg)
+		require.True(t, res.IsOK(), res.Log)
+	}
+
+	for orderID := int64(1); orderID <= 6; orderID++ {
+		paths = append(paths, fmt.Sprintf("%s/%d", QueryOrder, orderID))
+	}
+	return append(paths,
+		QueryOrderwall+"/BTC|ETH", QueryOrderwall+"/ETH|BTC", QueryDepth+"/BTC|ETH",
+		QueryTrades+"/BTC|ETH", QueryTrade+"/BTC|ETH/1", QueryTWAPOrder+"/1",
+		QueryOwnerOrders+"/"+alice.String(), QueryOwnerOrders+"/"+bob.String(), QueryClientOrder+"/"+alice.String()+"/a1",
+		QueryMarkets, QueryParams,
+	)
+}
+
+// returns the result of every query path, or the error it failed with
+func queryResults(ctx sdk.Context, keeper Keeper, paths []string) map[string]string {
+	querier := NewQuerier(keeper)
+	results := make(map[string]string)
+	for _, path := range paths {
+		res, err := querier(ctx, strings.Split(path, "/"), abci.RequestQuery{})
+		if err != nil {
+			results[path] = err.Error()
+			continue
+		}
+		results[path] = string(res)
+	}
+	return results
+}
+
+func TestMigrateStore(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	keeper = keeper.WithOrderbookCache(DefaultCacheDepth)
+	paths := populateOrderbook(t, ctx, keeper)
+	before := queryResults(ctx, keeper, paths)
+	version := SchemaVersion()
+	require.Equal(t, version, keeper.GetSchemaVersion(ctx))
+	all := testMigrations()
+
+	// the first new migration moves the orders out of where the Keeper looks for them
+	tags, err := keeper.runMigrations(ctx, all[:len(all)-1])
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewTags(TagSchemaVersion, idTagValue(version+1)), tags)
+	_, found := keeper.GetOrder(ctx, 1)
+	require.False(t, found)
+
+	// only the second one is left to run, after which every query gives the same result as before
+	tags, err = keeper.runMigrations(ctx, all)
+	require.NoError(t, err)
+	require.Equal(t, sdk.NewTags(TagSchemaVersion, idTagValue(version+2)), tags)
+	require.Equal(t, version+2, keeper.GetSchemaVersion(ctx))
+	require.Equal(t, before, queryResults(ctx, keeper, paths))
+	require.Nil(t, AssertInvariants(ctx, keeper))
+
+	// a migrated store is left alone
+	tags, err = keeper.runMigrations(ctx, all)
+	require.NoError(t, err)
+	require.Empty(t, tags)
+
+	// the migrated orderbook keeps trading
+	res := NewHandler(keeper)(ctx, makeOrderMsg(bob, 20, "ETH", "0.5", "BTC", STPNone))
+	require.True(t, res.IsOK(), res.Log)
+	require.Nil(t, AssertInvariants(ctx, keeper))
+}
+
+func TestMigrateStoreFailure(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	paths := populateOrderbook(t, ctx, keeper)
+	before := queryResults(ctx, keeper, paths)
+	version := SchemaVersion()
+	all := testMigrations()
+	n := len(all)
+
+	// a failing migration discards the ones that ran before it
+	failing := append(all[:n-1:n-1], Migration{version + 2, "fail", func(ctx sdk.Context, k Keeper) error {
+		return errors.New("failed")
+	}})
+	_, err := keeper.runMigrations(ctx, failing)
+	require.Error(t, err)
+	require.Equal(t, version, keeper.GetSchemaVersion(ctx))
+	require.Equal(t, before, queryResults(ctx, keeper, paths))
+
+	// versions can't be skipped
+	_, err = keeper.runMigrations(ctx, all[n-1:])
+	require.Error(t, err)
+
+	// nor can a store be migrated back
+	keeper.SetSchemaVersion(ctx, version+3)
+	_, err = keeper.runMigrations(ctx, all)
+	require.Error(t, err)
+}
+
+func TestBeginBlockerMigratesAtUpgradeHeight(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	paths := populateOrderbook(t, ctx, keeper)
+	before := queryResults(ctx, keeper, paths)
+
+	defer func(registered []Migration) { migrations = registered }(migrations)
+	migrations = testMigrations()
+	keeper = keeper.WithUpgradeHeight(10)
+
+	// the store can't be read before the upgrade height
+	require.Panics(t, func() { BeginBlocker(ctx.WithBlockHeight(9), keeper) })
+
+	tags := BeginBlocker(ctx.WithBlockHeight(10), keeper)
+	require.Len(t, tags, 2)
+	require.Equal(t, SchemaVersion(), keeper.GetSchemaVersion(ctx))
+	require.Equal(t, before, queryResults(ctx, keeper, paths))
+
+	require.Empty(t, BeginBlocker(ctx.WithBlockHeight(11), keeper))
+
+	// without an upgrade height, a store that isn't migrated stops the node
+	ctx, keeper = createTestInput(t)
+	keeper.SetSchemaVersion(ctx, InitialSchemaVersion)
+	require.Panics(t, func() { BeginBlocker(ctx.WithBlockHeight(11), keeper) })
+}
+
+// returns the "/" joined key of schema version 1 of a key with length-prefixed components
+func legacyKey(t *testing.T, key []byte) []byte {
+	components, err := SplitKeyComponents(key)
+	require.NoError(t, err)
+	name, parts := components[0], components[1:]
+	switch string(name) {
+	case "orderwalls", "orderwallVersions", "markets", "trades":
+		pair := NewDenomPair(string(parts[0]), string(parts[1]))
+		parts = append([][]byte{[]byte(pair.String())}, parts[2:]...)
+	}
+	return bytes.Join(append([][]byte{name}, parts...), []byte{legacyKeySeperator})
+}
+
+// rewrites the orderbook store back to the keys of schema version 1
+func downgradeToLegacyKeys(t *testing.T, ctx sdk.Context, k Keeper) {
+	store := ctx.KVStore(k.storeKey)
+	var keys, values [][]byte
+	iterator := store.Iterator(nil, nil)
+	for ; iterator.Valid(); iterator.Next() {
+		if bytes.Equal(iterator.Key(), schemaVersionKey) {
+			continue
+		}
+		keys = append(keys, append([]byte{}, iterator.Key()...))
+		values = append(values, append([]byte{}, iterator.Value()...))
+	}
+	iterator.Close()
+
+	for i, key := range keys {
+		store.Delete(key)
+		value := values[i]
+		if bytes.HasPrefix(key, tradeQueuePrefix) {
+			value = legacyKey(t, value)
+		}
+		store.Set(legacyKey(t, key), value)
+	}
+	k.SetSchemaVersion(ctx, InitialSchemaVersion)
+}
+
+func TestMigrateLengthPrefixedKeys(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	keeper = keeper.WithOrderbookCache(DefaultCacheDepth)
+	paths := populateOrderbook(t, ctx, keeper)
+	before := queryResults(ctx, keeper, paths)
+
+	downgradeToLegacyKeys(t, ctx, keeper)
+	_, found := keeper.GetOrder(ctx, 1)
+	require.False(t, found)
+
+	tags, err := keeper.MigrateStore(ctx)
+	require.NoError(t, err)
+	require.Equal(t, idTagValue(2), tags[0].Value)
+	require.Equal(t, SchemaVersion(), keeper.GetSchemaVersion(ctx))
+	require.Equal(t, before, queryResults(ctx, keeper, paths))
+	require.Nil(t, AssertInvariants(ctx, keeper))
+
+	// nothing is left at a key of schema version 1, which starts with a name instead of its length
+	iterator := ctx.KVStore(keeper.storeKey).Iterator(nil, nil)
+	for ; iterator.Valid(); iterator.Next() {
+		if bytes.Equal(iterator.Key(), schemaVersionKey) {
+			continue
+		}
+		_, err := SplitKeyComponents(iterator.Key())
+		require.NoError(t, err)
+		require.True(t, iterator.Key()[0] < 'a', "%q", iterator.Key())
+	}
+	iterator.Close()
+
+	// the migrated orderbook keeps trading, and prunes the trade it migrated
+	res := NewHandler(keeper)(ctx, makeOrderMsg(bob, 20, "ETH", "0.5", "BTC", STPNone))
+	require.True(t, res.IsOK(), res.Log)
+	require.Nil(t, AssertInvariants(ctx, keeper))
+	keeper.PruneTrades(ctx.WithBlockHeight(ctx.BlockHeight() + OrderHistoryRetention))
+	require.Empty(t, keeper.GetRecentTrades(ctx, NewDenomPair("BTC", "ETH"), 10))
+
+	// a store with a key no migration knows isn't migrated
+	ctx, keeper = createTestInput(t)
+	downgradeToLegacyKeys(t, ctx, keeper)
+	ctx.KVStore(keeper.storeKey).Set([]byte("unknown/1"), []byte{1})
+	_, err = keeper.MigrateStore(ctx)
+	require.Error(t, err)
+	require.Equal(t, InitialSchemaVersion, keeper.GetSchemaVersion(ctx))
+}
+
+// returns the keys the baseline code wrote an order under: the order itself and its place in the orderwall,
+// as "orders/<orderID>" and "orderwalls/<pair>/<price padded to 20 characters>/<orderID>"
+func baselineOrderKeys(order Order) (orderKey []byte, orderwallKey []byte) {
+	id := Int64ToSortableBytes(order.OrderID)
+	orderKey = bytes.Join([][]byte{[]byte("orders"), id}, []byte{legacyKeySeperator})
+	orderwallKey = bytes.Join([][]byte{
+		[]byte("orderwalls"), []byte(order.Pair().String()), []byte(fmt.Sprintf("%020s", order.Price.Ratio)), id,
+	}, []byte{legacyKeySeperator})
+	return orderKey, orderwallKey
+}
+
+func TestMigrateBaselineKeys(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	handler := NewHandler(keeper)
+
+	// order 47 has an ID ending in a "/", which the baseline keys can't tell from a separator
+	keeper.SetLastOrderID(ctx, 45)
+	msgs := []MsgMakeOrder{
+		makeOrderMsg(bob, 10, "BTC", "3", "ETH", STPNone),
+		makeOrderMsg(bob, 10, "BTC", "2", "ETH", STPNone),
+		makeOrderMsg(alice, 10, "ETH", "0.7", "BTC", STPNone),
+		makeOrderMsg(alice, 10, "ETH", "0.6", "BTC", STPNone),
+	}
+	var orders []Order
+	for _, msg := range msgs {
+		res := handler(ctx, msg)
+		require.True(t, res.IsOK(), res.Log)
+		order, found := keeper.GetOrder(ctx, keeper.GetLastOrderID(ctx))
+		require.True(t, found)
+		orders = append(orders, order)
+	}
+	require.Equal(t, int64(47), orders[1].OrderID)
+
+	// the store is rewritten to hold only what the baseline code kept, under the keys it used
+	store := ctx.KVStore(keeper.storeKey)
+	var keys [][]byte
+	iterator := store.Iterator(nil, nil)
+	for ; iterator.Valid(); iterator.Next() {
+		keys = append(keys, append([]byte{}, iterator.Key()...))
+	}
+	iterator.Close()
+	for _, key := range keys {
+		store.Delete(key)
+	}
+	store.Set([]byte("lastOrderID"), keeper.cdc.MustMarshalBinaryBare(int64(49)))
+	for _, order := range orders {
+		orderKey, orderwallKey := baselineOrderKeys(order)
+		store.Set(orderKey, keeper.cdc.MustMarshalBinaryBare(order))
+		store.Set(orderwallKey, keeper.cdc.MustMarshalBinaryBare(order.OrderID))
+	}
+
+	_, err := keeper.MigrateStore(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(49), keeper.GetLastOrderID(ctx))
+	for _, order := range orders {
+		migrated, found := keeper.GetOrder(ctx, order.OrderID)
+		require.True(t, found)
+		require.Equal(t, order, migrated)
+	}
+
+	// the orderwalls are in price order again
+	wall := keeper.PeekOrderwallOrders(ctx, NewDenomPair("BTC", "ETH"), 10)
+	require.Equal(t, []Order{orders[1], orders[0]}, wall)
+	wall = keeper.PeekOrderwallOrders(ctx, NewDenomPair("ETH", "BTC"), 10)
+	require.Equal(t, []Order{orders[3], orders[2]}, wall)
+
+	// an order listed twice in an orderwall would migrate to a single key, so the migration fails
+	ctx, keeper = createTestInput(t)
+	store = ctx.KVStore(keeper.storeKey)
+	order := orders[0]
+	orderKey, orderwallKey := baselineOrderKeys(order)
+	store.Set(orderKey, keeper.cdc.MustMarshalBinaryBare(order))
+	store.Set(orderwallKey, keeper.cdc.MustMarshalBinaryBare(order.OrderID))
+	order.Price = NewPrice(sdk.NewDec(4), "ETH", "BTC")
+	_, orderwallKey = baselineOrderKeys(order)
+	store.Set(orderwallKey, keeper.cdc.MustMarshalBinaryBare(order.OrderID))
+	keeper.SetSchemaVersion(ctx, InitialSchemaVersion)
+	_, err = keeper.MigrateStore(ctx)
+	require.Error(t, err)
+	require.Equal(t, InitialSchemaVersion, keeper.GetSchemaVersion(ctx))
+}
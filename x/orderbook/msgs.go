@@ -10,19 +10,27 @@ import (
 
 // Msg for creating a new order
 // Price must be in units of BuyDenom/SellDenom
+// A positive DisplayAmount makes it an iceberg order, which only shows that much of SellCoins at a time.
+// ClientOrderID is optional, and must be unique among the orders of OwnerAddr
 type MsgMakeOrder struct {
-	OwnerAddr      sdk.AccAddress
-	SellCoins      sdk.Coin
-	Price          Price
-	ExpirationTime time.Time
+	OwnerAddr           sdk.AccAddress
+	ClientOrderID       string
+	SellCoins           sdk.Coin
+	Price               Price
+	ExpirationTime      time.Time
+	DisplayAmount       sdk.Int
+	SelfTradePrevention SelfTradePrevention
 }
 
-func NewMsgMakeOrder(ownerAddr sdk.AccAddress, sellCoins sdk.Coin, price Price, expirationTime time.Time) MsgMakeOrder {
+func NewMsgMakeOrder(ownerAddr sdk.AccAddress, clientOrderID string, sellCoins sdk.Coin, price Price, expirationTime time.Time, displayAmount sdk.Int, stp SelfTradePrevention) MsgMakeOrder {
 	return MsgMakeOrder{
-		OwnerAddr:      ownerAddr,
-		SellCoins:      sellCoins,
-		Price:          price,
-		ExpirationTime: expirationTime,
+		OwnerAddr:           ownerAddr,
+		ClientOrderID:       clientOrderID,
+		SellCoins:           sellCoins,
+		Price:               price,
+		ExpirationTime:      expirationTime,
+		DisplayAmount:       displayAmount,
+		SelfTradePrevention: stp,
 	}
 }
 
@@ -40,11 +48,12 @@ func (msg MsgMakeOrder) ValidateBasic() sdk.Error {
 		return sdk.ErrInvalidCoins(msg.SellCoins.String())
 	}
 
-	if len(msg.Price.NumeratorDenom) == 0 {
+	// the denoms of the price end up in the keys of the orderwalls
+	if !ValidDenom(msg.Price.NumeratorDenom) {
 		return sdk.ErrInvalidCoins(msg.Price.NumeratorDenom)
 	}
 
-	if len(msg.Price.DenomenatorDenom) == 0 {
+	if !ValidDenom(msg.Price.DenomenatorDenom) {
 		return sdk.ErrInvalidCoins(msg.Price.DenomenatorDenom)
 	}
 
@@ -57,6 +66,20 @@ func (msg MsgMakeOrder) ValidateBasic() sdk.Error {
 		return ErrInvalidPriceRange(DefaultCodespace, msg.Price.Ratio)
 	}
 
+	if msg.DisplayAmount != (sdk.Int{}) {
+		if msg.DisplayAmount.Sign() == -1 || msg.DisplayAmount.GT(msg.SellCoins.Amount) {
+			return ErrInvalidDisplayAmount(DefaultCodespace, msg.DisplayAmount)
+		}
+	}
+
+	if !msg.SelfTradePrevention.IsValid() {
+		return ErrInvalidSelfTradePrevention(DefaultCodespace, msg.SelfTradePrevention)
+	}
+
+	if msg.ClientOrderID != "" && !ValidClientOrderID(msg.ClientOrderID) {
+		return ErrInvalidClientOrderID(DefaultCodespace, msg.ClientOrderID)
+	}
+
 	return nil
 }
 
@@ -74,9 +97,11 @@ func (msg MsgMakeOrder) GetSigners() []sdk.AccAddress {
 	return []sdk.AccAddress{msg.OwnerAddr}
 }
 
+// Msg for removing an order, by either its OrderID or the ClientOrderID its owner gave it
 type MsgRemoveOrder struct {
-	OwnerAddr sdk.AccAddress
-	OrderID   int64
+	OwnerAddr     sdk.AccAddress
+	OrderID       int64
+	ClientOrderID string
 }
 
 func NewMsgRemoveOrder(ownerAddr sdk.AccAddress, orderID int64) MsgRemoveOrder {
@@ -86,6 +111,13 @@ func NewMsgRemoveOrder(ownerAddr sdk.AccAddress, orderID int64) MsgRemoveOrder {
 	}
 }
 
+func NewMsgRemoveOrderByClientOrderID(ownerAddr sdk.AccAddress, clientOrderID string) MsgRemoveOrder {
+	return MsgRemoveOrder{
+		OwnerAddr:     ownerAddr,
+		ClientOrderID: clientOrderID,
+	}
+}
+
 // Implements Msg.
 func (msg MsgRemoveOrder) Route() string { return "orderbook" }
 func (msg MsgRemoveOrder) Type() string  { return "remove_order" }
@@ -100,6 +132,15 @@ func (msg MsgRemoveOrder) ValidateBasic() sdk.Error {
 		return sdk.ErrInternal(fmt.Sprintf("%d", msg.OrderID))
 	}
 
+	// exactly one of OrderID and ClientOrderID has to be given
+	if (msg.OrderID == 0) == (msg.ClientOrderID == "") {
+		return ErrInvalidOrderID(DefaultCodespace)
+	}
+
+	if msg.ClientOrderID != "" && !ValidClientOrderID(msg.ClientOrderID) {
+		return ErrInvalidClientOrderID(DefaultCodespace, msg.ClientOrderID)
+	}
+
 	return nil
 }
 
@@ -116,3 +157,218 @@ func (msg MsgRemoveOrder) GetSignBytes() []byte {
 func (msg MsgRemoveOrder) GetSigners() []sdk.AccAddress {
 	return []sdk.AccAddress{msg.OwnerAddr}
 }
+
+// Msg for creating a TWAPOrder, which sells SellCoins in slices of SliceAmount every Interval blocks.
+// Price must be in units of BuyDenom/SellDenom.  If Market is set, slices are executed at any price
+type MsgMakeTWAPOrder struct {
+	OwnerAddr   sdk.AccAddress
+	SellCoins   sdk.Coin
+	Price       Price
+	Market      bool
+	SliceAmount sdk.Int
+	Interval    int64
+}
+
+func NewMsgMakeTWAPOrder(ownerAddr sdk.AccAddress, sellCoins sdk.Coin, price Price, market bool, sliceAmount sdk.Int, interval int64) MsgMakeTWAPOrder {
+	return MsgMakeTWAPOrder{
+		OwnerAddr:   ownerAddr,
+		SellCoins:   sellCoins,
+		Price:       price,
+		Market:      market,
+		SliceAmount: sliceAmount,
+		Interval:    interval,
+	}
+}
+
+// Implements Msg.
+func (msg MsgMakeTWAPOrder) Route() string { return "orderbook" }
+func (msg MsgMakeTWAPOrder) Type() string  { return "make_twap_order" }
+
+// Implements Msg.
+func (msg MsgMakeTWAPOrder) ValidateBasic() sdk.Error {
+	if msg.OwnerAddr.Empty() {
+		return sdk.ErrInvalidAddress(msg.OwnerAddr.String())
+	}
+
+	if !msg.SellCoins.IsPositive() {
+		return sdk.ErrInvalidCoins(msg.SellCoins.String())
+	}
+
+	if len(msg.Price.NumeratorDenom) == 0 {
+		return sdk.ErrInvalidCoins(msg.Price.NumeratorDenom)
+	}
+
+	// Price must be in units of BuyDenom/SellDenom
+	if msg.SellCoins.Denom != msg.Price.DenomenatorDenom {
+		return ErrInvalidPriceFormat(DefaultCodespace, msg.Price)
+	}
+
+	if !msg.Market && !ValidSortableDec(msg.Price.Ratio) {
+		return ErrInvalidPriceRange(DefaultCodespace, msg.Price.Ratio)
+	}
+
+	if msg.SliceAmount == (sdk.Int{}) || msg.SliceAmount.Sign() != 1 || msg.Interval <= 0 {
+		return ErrInvalidTWAPSchedule(DefaultCodespace)
+	}
+
+	return nil
+}
+
+// Implements Msg.
+func (msg MsgMakeTWAPOrder) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return sdk.MustSortJSON(b)
+}
+
+// Implements Msg.
+func (msg MsgMakeTWAPOrder) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.OwnerAddr}
+}
+
+type MsgCancelTWAPOrder struct {
+	OwnerAddr sdk.AccAddress
+	TWAPID    int64
+}
+
+func NewMsgCancelTWAPOrder(ownerAddr sdk.AccAddress, twapID int64) MsgCancelTWAPOrder {
+	return MsgCancelTWAPOrder{
+		OwnerAddr: ownerAddr,
+		TWAPID:    twapID,
+	}
+}
+
+// Implements Msg.
+func (msg MsgCancelTWAPOrder) Route() string { return "orderbook" }
+func (msg MsgCancelTWAPOrder) Type() string  { return "cancel_twap_order" }
+
+// Implements Msg.
+func (msg MsgCancelTWAPOrder) ValidateBasic() sdk.Error {
+	if msg.OwnerAddr.Empty() {
+		return sdk.ErrInvalidAddress(msg.OwnerAddr.String())
+	}
+
+	if msg.TWAPID <= 0 {
+		return sdk.ErrInternal(fmt.Sprintf("%d", msg.TWAPID))
+	}
+
+	return nil
+}
+
+// Implements Msg.
+func (msg MsgCancelTWAPOrder) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return sdk.MustSortJSON(b)
+}
+
+// Implements Msg.
+func (msg MsgCancelTWAPOrder) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.OwnerAddr}
+}
+
+// Msg for replacing the orderbook params, which only the admin account set in the params can send
+type MsgUpdateParams struct {
+	AdminAddr sdk.AccAddress
+	Params    Params
+}
+
+func NewMsgUpdateParams(adminAddr sdk.AccAddress, params Params) MsgUpdateParams {
+	return MsgUpdateParams{
+		AdminAddr: adminAddr,
+		Params:    params,
+	}
+}
+
+// Implements Msg.
+func (msg MsgUpdateParams) Route() string { return "orderbook" }
+func (msg MsgUpdateParams) Type() string  { return "update_params" }
+
+// Implements Msg.
+func (msg MsgUpdateParams) ValidateBasic() sdk.Error {
+	if msg.AdminAddr.Empty() {
+		return sdk.ErrInvalidAddress(msg.AdminAddr.String())
+	}
+
+	if err := msg.Params.Validate(); err != nil {
+		return ErrInvalidParams(DefaultCodespace, err.Error())
+	}
+
+	return nil
+}
+
+// Implements Msg.
+func (msg MsgUpdateParams) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return sdk.MustSortJSON(b)
+}
+
+// Implements Msg.
+func (msg MsgUpdateParams) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.AdminAddr}
+}
+
+// Msg for submitting a governance proposal whose action the orderbook applies if it passes
+type MsgSubmitOrderbookProposal struct {
+	Proposer       sdk.AccAddress
+	Title          string
+	Description    string
+	InitialDeposit sdk.Coins
+	Action         ProposalAction
+}
+
+func NewMsgSubmitOrderbookProposal(proposer sdk.AccAddress, title string, description string, initialDeposit sdk.Coins, action ProposalAction) MsgSubmitOrderbookProposal {
+	return MsgSubmitOrderbookProposal{
+		Proposer:       proposer,
+		Title:          title,
+		Description:    description,
+		InitialDeposit: initialDeposit,
+		Action:         action,
+	}
+}
+
+// Implements Msg.
+func (msg MsgSubmitOrderbookProposal) Route() string { return "orderbook" }
+func (msg MsgSubmitOrderbookProposal) Type() string  { return "submit_orderbook_proposal" }
+
+// Implements Msg.
+func (msg MsgSubmitOrderbookProposal) ValidateBasic() sdk.Error {
+	if msg.Proposer.Empty() {
+		return sdk.ErrInvalidAddress(msg.Proposer.String())
+	}
+
+	if len(msg.Title) == 0 || len(msg.Description) == 0 {
+		return ErrInvalidProposal(DefaultCodespace, "title and description can't be empty")
+	}
+
+	if !msg.InitialDeposit.IsValid() || !msg.InitialDeposit.IsNotNegative() {
+		return sdk.ErrInvalidCoins(msg.InitialDeposit.String())
+	}
+
+	if err := msg.Action.Validate(); err != nil {
+		return ErrInvalidProposal(DefaultCodespace, err.Error())
+	}
+
+	return nil
+}
+
+// Implements Msg.
+func (msg MsgSubmitOrderbookProposal) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return sdk.MustSortJSON(b)
+}
+
+// Implements Msg.
+func (msg MsgSubmitOrderbookProposal) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Proposer}
+}
@@ -4,11 +4,6 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
-// get key in store to get an Order
-func OrderKey(orderID int64) []byte {
-	return AppendWithSeperator(ordersPrefix, Int64ToSortableBytes(orderID))
-}
-
 // Gets an Order from the Store
 func (k Keeper) GetOrder(ctx sdk.Context, orderID int64) (order Order, found bool) {
 	store := ctx.KVStore(k.storeKey)
@@ -27,12 +22,117 @@ func (k Keeper) GetOrder(ctx sdk.Context, orderID int64) (order Order, found boo
 func (k Keeper) SetOrder(ctx sdk.Context, order Order) {
 	store := ctx.KVStore(k.storeKey)
 	store.Set(OrderKey(order.OrderID), k.cdc.MustMarshalBinaryBare(order))
+	k.updateCachedOrderwall(ctx, order.Pair(), func(wall *cachedOrderwall) { wall.update(order) })
 }
 
-// Sets an Order int the Store
+// Deletes an Order from the Store
 func (k Keeper) DeleteOrder(ctx sdk.Context, orderID int64) {
 	store := ctx.KVStore(k.storeKey)
-	store.Set(OrderKey(orderID), nil)
+	store.Delete(OrderKey(orderID))
+}
+
+// Iterates over all the resting orders by orderID, until handler returns true
+func (k Keeper) IterateOrders(ctx sdk.Context, handler func(order Order) (stop bool)) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := sdk.KVStorePrefixIterator(store, ordersPrefix)
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var order Order
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &order)
+		if handler(order) {
+			return
+		}
+	}
+}
+
+// get key in store to get the orderID an owner assigned a client order ID to
+func ClientOrderIDKey(owner sdk.AccAddress, clientOrderID string) []byte {
+	return AppendKeyComponents(clientOrderIDsPrefix, owner, []byte(clientOrderID))
+}
+
+// Gets the orderID of the order an owner made with a client order ID
+func (k Keeper) GetOrderIDByClientOrderID(ctx sdk.Context, owner sdk.AccAddress, clientOrderID string) (orderID int64, found bool) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(ClientOrderIDKey(owner, clientOrderID))
+	if bz == nil {
+		return 0, false
+	}
+	k.cdc.MustUnmarshalBinaryBare(bz, &orderID)
+	return orderID, true
+}
+
+// Indexes an orderID by its owner and client order ID.
+// The index is kept after the order leaves the orderwall so that the client order ID can't be reused
+func (k Keeper) SetClientOrderID(ctx sdk.Context, owner sdk.AccAddress, clientOrderID string, orderID int64) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(ClientOrderIDKey(owner, clientOrderID), k.cdc.MustMarshalBinaryBare(orderID))
+}
+
+// get key in store to get the number of orders an owner has resting in the orderwalls
+func OpenOrderCountKey(owner sdk.AccAddress) []byte {
+	return AppendKeyComponents(openOrderCountsPrefix, owner)
+}
+
+// Gets the number of orders an owner has resting in the orderwalls
+func (k Keeper) GetOpenOrderCount(ctx sdk.Context, owner sdk.AccAddress) (count int64) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(OpenOrderCountKey(owner))
+	if bz == nil {
+		return 0
+	}
+	k.cdc.MustUnmarshalBinaryBare(bz, &count)
+	return count
+}
+
+// Sets the number of orders an owner has resting in the orderwalls, removing it once there are none left
+func (k Keeper) setOpenOrderCount(ctx sdk.Context, owner sdk.AccAddress, count int64) {
+	store := ctx.KVStore(k.storeKey)
+	if count <= 0 {
+		store.Delete(OpenOrderCountKey(owner))
+		return
+	}
+	store.Set(OpenOrderCountKey(owner), k.cdc.MustMarshalBinaryBare(count))
+}
+
+// get key in store of an order resting in the orderwalls, indexed by its owner
+func OwnerOrderKey(owner sdk.AccAddress, orderID int64) []byte {
+	return AppendKeyComponents(OwnerOrdersPrefix(owner), Int64ToSortableBytes(orderID))
+}
+
+// get prefix of the orders an owner has resting in the orderwalls
+func OwnerOrdersPrefix(owner sdk.AccAddress) []byte {
+	return AppendKeyComponents(ownerOrdersPrefix, owner)
+}
+
+// Gets the orders an owner has resting in the orderwalls, sorted by orderID
+func (k Keeper) GetOwnerOrders(ctx sdk.Context, owner sdk.AccAddress) (orders []Order) {
+	store := ctx.KVStore(k.storeKey)
+	prefix := OwnerOrdersPrefix(owner)
+	iterator := store.Iterator(prefix, sdk.PrefixEndBytes(prefix))
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var orderID int64
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &orderID)
+		order, found := k.GetOrder(ctx, orderID)
+		if found {
+			orders = append(orders, order)
+		}
+	}
+	return orders
+}
+
+// Indexes an order resting in the orderwalls by its owner
+func (k Keeper) setOwnerOrder(ctx sdk.Context, owner sdk.AccAddress, orderID int64) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(OwnerOrderKey(owner, orderID), k.cdc.MustMarshalBinaryBare(orderID))
+}
+
+// Removes an order that left the orderwalls from its owner's index
+func (k Keeper) deleteOwnerOrder(ctx sdk.Context, owner sdk.AccAddress, orderID int64) {
+	store := ctx.KVStore(k.storeKey)
+	store.Delete(OwnerOrderKey(owner, orderID))
 }
 
 // Gets the last orderID that was assigned
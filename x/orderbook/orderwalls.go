@@ -4,17 +4,8 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
-var orderwallPrefix = []byte("orderwalls")
-
-// returns a prefix for storing all orders in the orderwall of a specific DenomPair
-func OrderwallPrefix(pair DenomPair) []byte {
-	return AppendWithSeperator(orderwallPrefix, []byte(pair.String()))
-}
-
-// Returns the key for getting an orderID in an orderWall
-func OrderwallOrderKey(pair DenomPair, price Price, orderID int64) []byte {
-	return AppendWithSeperator(AppendWithSeperator(OrderwallPrefix(pair), SortableSDKDecBytes(price.Ratio)), Int64ToSortableBytes(orderID))
-}
+var orderwallPrefix = KeyFromComponents([]byte("orderwalls"))
+var lastWallSequenceKey = KeyFromComponents([]byte("lastWallSequence"))
 
 // Returns an iterator for all the orders in an orderwall by price
 func (k Keeper) OrderWallIterator(ctx sdk.Context, pair DenomPair) sdk.Iterator {
@@ -24,17 +15,53 @@ func (k Keeper) OrderWallIterator(ctx sdk.Context, pair DenomPair) sdk.Iterator
 
 // peeks at the next lowest orderwall order
 func (k Keeper) PeekOrderwallOrder(ctx sdk.Context, pair DenomPair) (order Order, found bool) {
-	orderWall := k.OrderWallIterator(ctx, pair)
-	if !orderWall.Valid() {
+	orders := k.PeekOrderwallOrders(ctx, pair, 1)
+	if len(orders) == 0 {
 		return order, false
 	}
+	return orders[0], true
+}
 
-	var orderID int64
-	k.cdc.MustUnmarshalBinaryBare(orderWall.Value(), &orderID)
+// peeks at up to limit of the lowest orderwall orders, in order, using a single walk of the orderwall.
+// The iterator is closed before returning so the orders can be modified in the store afterwards
+func (k Keeper) PeekOrderwallOrders(ctx sdk.Context, pair DenomPair, limit int64) (orders []Order) {
+	return k.PeekOrderwallOrdersWhile(ctx, pair, limit, func(order Order) bool { return true })
+}
 
-	orderWall.Close()
+// peeks at up to limit of the lowest orderwall orders, in order, for as long as while returns true for them.
+// The walk of the orderwall stops at the first order while returns false for, without reading any order past it.
+// The orderwall is read without gas metering, and GasPerPeekedOrder is consumed for every order read instead,
+// so peeking costs the same gas whether the orders are in the orderbook cache or not
+func (k Keeper) PeekOrderwallOrdersWhile(ctx sdk.Context, pair DenomPair, limit int64, while func(order Order) bool) (orders []Order) {
+	if k.cache != nil {
+		if orders, ok := k.peekCachedOrderwall(ctx, pair, limit, while); ok {
+			return orders
+		}
+	}
 
-	return k.GetOrder(ctx, orderID)
+	store := k.unmeteredStore(ctx)
+	orderWall := store.Iterator(OrderwallPrefix(pair), sdk.PrefixEndBytes(OrderwallPrefix(pair)))
+	defer orderWall.Close()
+
+	for ; orderWall.Valid() && int64(len(orders)) < limit; orderWall.Next() {
+		var orderID int64
+		k.cdc.MustUnmarshalBinaryBare(orderWall.Value(), &orderID)
+
+		bz := store.Get(OrderKey(orderID))
+		if bz == nil {
+			continue
+		}
+		var order Order
+		k.cdc.MustUnmarshalBinaryBare(bz, &order)
+
+		ctx.GasMeter().ConsumeGas(GasPerPeekedOrder, "orderbook peek")
+		if !while(order) {
+			break
+		}
+		orders = append(orders, order)
+	}
+
+	return orders
 }
 
 // pops the next lowest orderwall order
@@ -46,23 +73,56 @@ func (k Keeper) PopOrderwallOrder(ctx sdk.Context, pair DenomPair) (order Order,
 
 	var orderID int64
 	k.cdc.MustUnmarshalBinaryBare(orderWall.Value(), &orderID)
+	key := orderWall.Key()
 
 	orderWall.Close()
 
 	store := ctx.KVStore(k.storeKey)
-	store.Delete(orderWall.Key())
+	store.Delete(key)
+	k.updateCachedOrderwall(ctx, pair, func(wall *cachedOrderwall) { wall.remove(key) })
 
 	return k.GetOrder(ctx, orderID)
 }
 
 // Insert an orderID into the appropriate timeslice in the expiration queue
 func (k Keeper) InsertOrderwallOrder(ctx sdk.Context, order Order) {
+	key := NewOrderwallKey(order).Bytes()
 	store := ctx.KVStore(k.storeKey)
-	store.Set(OrderwallOrderKey(order.Pair(), order.Price, order.OrderID), k.cdc.MustMarshalBinaryBare(order.OrderID))
+	store.Set(key, k.cdc.MustMarshalBinaryBare(order.OrderID))
+	k.updateCachedOrderwall(ctx, order.Pair(), func(wall *cachedOrderwall) {
+		wall.insert(key, order, k.cache.depth)
+	})
 }
 
 // Insert an orderID into the appropriate timeslice in the expiration queue
 func (k Keeper) DeleteOrderwallOrder(ctx sdk.Context, order Order) {
+	key := NewOrderwallKey(order).Bytes()
+	store := ctx.KVStore(k.storeKey)
+	store.Delete(key)
+	k.updateCachedOrderwall(ctx, order.Pair(), func(wall *cachedOrderwall) { wall.remove(key) })
+}
+
+// Gets the last wallSequence that was assigned
+func (k Keeper) GetLastWallSequence(ctx sdk.Context) (lastWallSequence int64) {
 	store := ctx.KVStore(k.storeKey)
-	store.Set(OrderwallOrderKey(order.Pair(), order.Price, order.OrderID), nil)
+	bz := store.Get(lastWallSequenceKey)
+	if bz == nil {
+		return 0
+	}
+	k.cdc.MustUnmarshalBinaryBare(bz, &lastWallSequence)
+	return lastWallSequence
+}
+
+// Sets the last wallSequence that was assigned
+func (k Keeper) SetLastWallSequence(ctx sdk.Context, wallSequence int64) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(lastWallSequenceKey, k.cdc.MustMarshalBinaryBare(wallSequence))
+}
+
+// Gets the next unassigned wallSequence (and increments lastWallSequence).
+// An order inserted with it goes to the back of its price level
+func (k Keeper) GetNextWallSequence(ctx sdk.Context) (nextWallSequence int64) {
+	nextWallSequence = k.GetLastWallSequence(ctx) + 1
+	k.SetLastWallSequence(ctx, nextWallSequence)
+	return nextWallSequence
 }
@@ -0,0 +1,163 @@
+package orderbook
+
+import (
+	"fmt"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/params"
+)
+
+// DefaultParamspace is the name of the orderbook module's params subspace
+const DefaultParamspace = "orderbook"
+
+// DefaultMaxOrdersPerAccount is the number of orders an account can have resting in the orderwalls at once
+// if no other limit has been set
+const DefaultMaxOrdersPerAccount int64 = 1000
+
+// Keys for the orderbook params in the params store
+var (
+	KeyMaxPrice            = []byte("MaxPrice")
+	KeyMaxOrdersPerAccount = []byte("MaxOrdersPerAccount")
+	KeyMaxFills            = []byte("MaxFills")
+	KeyMakerFeeRate        = []byte("MakerFeeRate")
+	KeyTakerFeeRate        = []byte("TakerFeeRate")
+	KeyDefaultExpiry       = []byte("DefaultExpiry")
+	KeyAdmin               = []byte("Admin")
+)
+
+var _ params.ParamSet = (*Params)(nil)
+
+// Params are the orderbook parameters that can be changed without a new release of the chain
+type Params struct {
+	// highest price ratio an order can be made with, at most 10^10 so prices stay sortable
+	MaxPrice sdk.Dec `json:"max_price"`
+	// number of orders an account can have resting in the orderwalls at once
+	MaxOrdersPerAccount int64 `json:"max_orders_per_account"`
+	// number of opposing orders an incoming order can be matched against
+	MaxFills int64 `json:"max_fills"`
+	// share of the coins received in a fill charged to the resting and the incoming order
+	MakerFeeRate sdk.Dec `json:"maker_fee_rate"`
+	TakerFeeRate sdk.Dec `json:"taker_fee_rate"`
+	// how long an order made without an expiration time rests in the orderwall, 0 for no expiry
+	DefaultExpiry time.Duration `json:"default_expiry"`
+	// account allowed to update the params with MsgUpdateParams, empty if only governance can
+	Admin sdk.AccAddress `json:"admin"`
+}
+
+// Implements params.ParamSet
+func (p *Params) KeyValuePairs() params.KeyValuePairs {
+	return params.KeyValuePairs{
+		{KeyMaxPrice, &p.MaxPrice},
+		{KeyMaxOrdersPerAccount, &p.MaxOrdersPerAccount},
+		{KeyMaxFills, &p.MaxFills},
+		{KeyMakerFeeRate, &p.MakerFeeRate},
+		{KeyTakerFeeRate, &p.TakerFeeRate},
+		{KeyDefaultExpiry, &p.DefaultExpiry},
+		{KeyAdmin, &p.Admin},
+	}
+}
+
+// Returns the type table of the orderbook params subspace
+func ParamTypeTable() params.TypeTable {
+	return params.NewTypeTable().RegisterParamSet(&Params{})
+}
+
+// Returns the params a new chain starts with
+func DefaultParams() Params {
+	return Params{
+		MaxPrice:            maxDec,
+		MaxOrdersPerAccount: DefaultMaxOrdersPerAccount,
+		MaxFills:            DefaultMaxFills,
+		MakerFeeRate:        sdk.ZeroDec(),
+		TakerFeeRate:        sdk.ZeroDec(),
+		DefaultExpiry:       0,
+	}
+}
+
+// Checks that the params are within the bounds the orderbook can work with
+func (p Params) Validate() error {
+	if p.MaxPrice == (sdk.Dec{}) || !p.MaxPrice.IsPositive() || !ValidSortableDec(p.MaxPrice) {
+		return fmt.Errorf("max price must be positive and at most %s", maxDec)
+	}
+	if p.MaxOrdersPerAccount <= 0 {
+		return fmt.Errorf("max orders per account must be positive")
+	}
+	if p.MaxFills <= 0 {
+		return fmt.Errorf("max fills must be positive")
+	}
+	for _, rate := range []sdk.Dec{p.MakerFeeRate, p.TakerFeeRate} {
+		if rate == (sdk.Dec{}) || rate.IsNegative() || rate.GTE(sdk.OneDec()) {
+			return fmt.Errorf("fee rates must be at least 0 and less than 1")
+		}
+	}
+	if p.DefaultExpiry < 0 {
+		return fmt.Errorf("default expiry can't be negative")
+	}
+	return nil
+}
+
+func (p Params) String() string {
+	return fmt.Sprintf(`Orderbook Params:
+  Max Price:              %s
+  Max Orders Per Account: %d
+  Max Fills:              %d
+  Maker Fee Rate:         %s
+  Taker Fee Rate:         %s
+  Default Expiry:         %s
+  Admin:                  %s`,
+		p.MaxPrice, p.MaxOrdersPerAccount, p.MaxFills, p.MakerFeeRate, p.TakerFeeRate, p.DefaultExpiry, p.Admin)
+}
+
+// Gets all the orderbook params
+func (k Keeper) GetParams(ctx sdk.Context) (params Params) {
+	k.paramSpace.GetParamSet(ctx, &params)
+	return params
+}
+
+// Sets all the orderbook params
+func (k Keeper) SetParams(ctx sdk.Context, params Params) {
+	k.paramSpace.SetParamSet(ctx, &params)
+}
+
+// Gets the highest price ratio an order can be made with
+func (k Keeper) MaxPrice(ctx sdk.Context) (maxPrice sdk.Dec) {
+	k.paramSpace.Get(ctx, KeyMaxPrice, &maxPrice)
+	return maxPrice
+}
+
+// Gets the number of orders an account can have resting in the orderwalls at once
+func (k Keeper) MaxOrdersPerAccount(ctx sdk.Context) (maxOrders int64) {
+	k.paramSpace.Get(ctx, KeyMaxOrdersPerAccount, &maxOrders)
+	return maxOrders
+}
+
+// Gets the maximum number of opposing orders an incoming order can be matched against
+func (k Keeper) MaxFills(ctx sdk.Context) (maxFills int64) {
+	k.paramSpace.Get(ctx, KeyMaxFills, &maxFills)
+	return maxFills
+}
+
+// Gets the share of the coins received in a fill charged to the resting order
+func (k Keeper) MakerFeeRate(ctx sdk.Context) (rate sdk.Dec) {
+	k.paramSpace.Get(ctx, KeyMakerFeeRate, &rate)
+	return rate
+}
+
+// Gets the share of the coins received in a fill charged to the incoming order
+func (k Keeper) TakerFeeRate(ctx sdk.Context) (rate sdk.Dec) {
+	k.paramSpace.Get(ctx, KeyTakerFeeRate, &rate)
+	return rate
+}
+
+// Gets how long an order made without an expiration time rests in the orderwall
+func (k Keeper) DefaultExpiry(ctx sdk.Context) (expiry time.Duration) {
+	k.paramSpace.Get(ctx, KeyDefaultExpiry, &expiry)
+	return expiry
+}
+
+// Gets the account allowed to update the params with MsgUpdateParams
+func (k Keeper) Admin(ctx sdk.Context) (admin sdk.AccAddress) {
+	k.paramSpace.Get(ctx, KeyAdmin, &admin)
+	return admin
+}
@@ -0,0 +1,200 @@
+package orderbook
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/gov"
+)
+
+var proposalActionsPrefix = KeyFromComponents([]byte("proposalActions"))
+
+// Kinds of orderbook proposals
+const (
+	ProposalListMarket   = "list-market"
+	ProposalHaltMarket   = "halt-market"
+	ProposalDelistMarket = "delist-market"
+	ProposalChangeParams = "change-params"
+)
+
+// GovKeeper is the part of the governance keeper that orderbook proposals are submitted to and followed with
+type GovKeeper interface {
+	NewTextProposal(ctx sdk.Context, title string, description string, proposalType gov.ProposalKind) gov.Proposal
+	AddDeposit(ctx sdk.Context, proposalID uint64, depositorAddr sdk.AccAddress, depositAmount sdk.Coins) (sdk.Error, bool)
+	GetProposal(ctx sdk.Context, proposalID uint64) gov.Proposal
+}
+
+// ProposalAction is what the orderbook does once the governance proposal it was submitted with passes.
+// Market is used by the market proposals and Params by ProposalChangeParams
+type ProposalAction struct {
+	Kind   string `json:"kind"`
+	Market Market `json:"market"`
+	Params Params `json:"params"`
+}
+
+func NewListMarketAction(baseDenom, quoteDenom string) ProposalAction {
+	return ProposalAction{Kind: ProposalListMarket, Market: NewMarket(baseDenom, quoteDenom)}
+}
+
+func NewHaltMarketAction(baseDenom, quoteDenom string) ProposalAction {
+	return ProposalAction{Kind: ProposalHaltMarket, Market: NewMarket(baseDenom, quoteDenom)}
+}
+
+func NewDelistMarketAction(baseDenom, quoteDenom string) ProposalAction {
+	return ProposalAction{Kind: ProposalDelistMarket, Market: NewMarket(baseDenom, quoteDenom)}
+}
+
+func NewChangeParamsAction(params Params) ProposalAction {
+	return ProposalAction{Kind: ProposalChangeParams, Params: params}
+}
+
+// Checks that the action is well formed, without looking at the state it would be applied to
+func (a ProposalAction) Validate() error {
+	switch a.Kind {
+	case ProposalListMarket, ProposalHaltMarket, ProposalDelistMarket:
+		return a.Market.Validate()
+	case ProposalChangeParams:
+		return a.Params.Validate()
+	default:
+		return fmt.Errorf("unknown orderbook proposal kind %s", a.Kind)
+	}
+}
+
+// Returns the kind of governance proposal the action is submitted as
+func (a ProposalAction) ProposalType() gov.ProposalKind {
+	if a.Kind == ProposalChangeParams {
+		return gov.ProposalTypeParameterChange
+	}
+	return gov.ProposalTypeText
+}
+
+// get key in store to get the action of a governance proposal
+func ProposalActionKey(proposalID uint64) []byte {
+	return AppendKeyComponents(proposalActionsPrefix, Int64ToSortableBytes(int64(proposalID)))
+}
+
+// Gets the action waiting for a governance proposal to pass
+func (k Keeper) GetProposalAction(ctx sdk.Context, proposalID uint64) (action ProposalAction, found bool) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(ProposalActionKey(proposalID))
+	if bz == nil {
+		return action, false
+	}
+	k.cdc.MustUnmarshalBinaryBare(bz, &action)
+	return action, true
+}
+
+// Sets the action to apply once a governance proposal passes
+func (k Keeper) SetProposalAction(ctx sdk.Context, proposalID uint64, action ProposalAction) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(ProposalActionKey(proposalID), k.cdc.MustMarshalBinaryBare(action))
+}
+
+// Checks that an action can be applied to the current state
+func (k Keeper) validateProposalAction(ctx sdk.Context, action ProposalAction) sdk.Error {
+	if err := action.Validate(); err != nil {
+		return ErrInvalidProposal(k.codespace, err.Error())
+	}
+
+	market, found := k.GetMarket(ctx, action.Market.Pair())
+	switch action.Kind {
+	case ProposalListMarket:
+		if found && market.Status == MarketStatusActive {
+			return ErrInvalidProposal(k.codespace, fmt.Sprintf("market %s is already listed", market))
+		}
+	case ProposalHaltMarket:
+		if !found || market.Status != MarketStatusActive {
+			return ErrMarketNotActive(k.codespace, action.Market.Pair())
+		}
+	case ProposalDelistMarket:
+		if !found {
+			return ErrMarketNotFound(k.codespace, action.Market.Pair())
+		}
+	}
+	return nil
+}
+
+// Submits a governance proposal with the proposer's initial deposit, and keeps the action to apply if it passes
+func (k Keeper) SubmitProposal(ctx sdk.Context, proposer sdk.AccAddress, title string, description string,
+	initialDeposit sdk.Coins, action ProposalAction) (proposalID uint64, err sdk.Error) {
+
+	err = k.validateProposalAction(ctx, action)
+	if err != nil {
+		return 0, err
+	}
+
+	proposal := k.govKeeper.NewTextProposal(ctx, title, description, action.ProposalType())
+	proposalID = proposal.GetProposalID()
+
+	err, _ = k.govKeeper.AddDeposit(ctx, proposalID, proposer, initialDeposit)
+	if err != nil {
+		return 0, err
+	}
+
+	k.SetProposalAction(ctx, proposalID, action)
+	return proposalID, nil
+}
+
+// Applies a passed proposal's action to the orderbook
+func (k Keeper) applyProposalAction(ctx sdk.Context, action ProposalAction) sdk.Error {
+	err := k.validateProposalAction(ctx, action)
+	if err != nil {
+		return err
+	}
+
+	switch action.Kind {
+	case ProposalListMarket:
+		k.ListMarket(ctx, action.Market)
+	case ProposalHaltMarket:
+		return k.HaltMarket(ctx, action.Market.Pair())
+	case ProposalDelistMarket:
+		return k.DelistMarket(ctx, action.Market.Pair())
+	case ProposalChangeParams:
+		k.SetParams(ctx, action.Params)
+	}
+	return nil
+}
+
+// Applies the actions of proposals that passed and forgets the ones of proposals that were rejected or removed.
+// Meant to run after the governance EndBlocker has tallied the proposals whose voting period ended
+func (k Keeper) ApplyPassedProposals(ctx sdk.Context) (resTags sdk.Tags) {
+	store := ctx.KVStore(k.storeKey)
+	resTags = sdk.NewTags()
+
+	var keys [][]byte
+	var actions []ProposalAction
+	iterator := store.Iterator(proposalActionsPrefix, sdk.PrefixEndBytes(proposalActionsPrefix))
+	for ; iterator.Valid(); iterator.Next() {
+		var action ProposalAction
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &action)
+		keys = append(keys, iterator.Key())
+		actions = append(actions, action)
+	}
+	iterator.Close()
+
+	for i, action := range actions {
+		proposalID := uint64(SortableBytesToInt64(keys[i][len(keys[i])-8:]))
+		proposal := k.govKeeper.GetProposal(ctx, proposalID)
+
+		switch {
+		case proposal == nil || proposal.GetStatus() == gov.StatusRejected:
+			store.Delete(keys[i])
+
+		case proposal.GetStatus() == gov.StatusPassed:
+			store.Delete(keys[i])
+
+			// an action that no longer fits the state, like halting a market that was delisted since, is dropped
+			err := k.applyProposalAction(ctx, action)
+			if err != nil {
+				ctx.Logger().Info(fmt.Sprintf("orderbook proposal %d passed but could not be applied: %s", proposalID, err.Error()))
+				continue
+			}
+			resTags = resTags.AppendTag(TagAppliedProposalID, []byte(fmt.Sprintf("%d", proposalID)))
+			if action.Kind != ProposalChangeParams {
+				resTags = resTags.AppendTags(MarketTag(action.Market.Pair()))
+			}
+		}
+	}
+
+	return resTags
+}
@@ -0,0 +1,179 @@
+package orderbook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/gov"
+)
+
+// mockGovKeeper keeps proposals in memory, so tests can decide which ones pass without voting
+type mockGovKeeper struct {
+	proposals      map[uint64]*gov.TextProposal
+	lastProposalID uint64
+}
+
+func newMockGovKeeper() *mockGovKeeper {
+	return &mockGovKeeper{proposals: make(map[uint64]*gov.TextProposal)}
+}
+
+func (gk *mockGovKeeper) NewTextProposal(ctx sdk.Context, title string, description string, proposalType gov.ProposalKind) gov.Proposal {
+	gk.lastProposalID++
+	proposal := &gov.TextProposal{
+		ProposalID:   gk.lastProposalID,
+		Title:        title,
+		Description:  description,
+		ProposalType: proposalType,
+		Status:       gov.StatusDepositPeriod,
+	}
+	gk.proposals[proposal.ProposalID] = proposal
+	return proposal
+}
+
+func (gk *mockGovKeeper) AddDeposit(ctx sdk.Context, proposalID uint64, depositorAddr sdk.AccAddress, depositAmount sdk.Coins) (sdk.Error, bool) {
+	proposal, found := gk.proposals[proposalID]
+	if !found {
+		return gov.ErrUnknownProposal(gov.DefaultCodespace, proposalID), false
+	}
+	proposal.TotalDeposit = proposal.TotalDeposit.Plus(depositAmount)
+	return nil, false
+}
+
+func (gk *mockGovKeeper) GetProposal(ctx sdk.Context, proposalID uint64) gov.Proposal {
+	proposal, found := gk.proposals[proposalID]
+	if !found {
+		return nil
+	}
+	return proposal
+}
+
+// submits a proposal through the handler and returns its ID
+func submitProposal(t *testing.T, ctx sdk.Context, keeper Keeper, action ProposalAction) uint64 {
+	msg := NewMsgSubmitOrderbookProposal(alice, "title", "description", sdk.Coins{sdk.NewInt64Coin("BTC", 10)}, action)
+	require.Nil(t, msg.ValidateBasic())
+
+	res := NewHandler(keeper)(ctx, msg)
+	require.True(t, res.IsOK(), res.Log)
+
+	var proposalID uint64
+	keeper.cdc.MustUnmarshalBinaryBare(res.Data, &proposalID)
+	return proposalID
+}
+
+// sets the status governance decided on for a proposal and runs the orderbook EndBlocker
+func decideProposal(ctx sdk.Context, keeper Keeper, proposalID uint64, status gov.ProposalStatus) sdk.Tags {
+	keeper.govKeeper.GetProposal(ctx, proposalID).SetStatus(status)
+	return EndBlocker(ctx, keeper)
+}
+
+func TestListAndHaltMarketProposals(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	handler := NewHandler(keeper)
+
+	// ETH/ATOM isn't listed
+	res := handler(ctx, makeOrderMsg(alice, 10, "ETH", "2", "ATOM", STPNone))
+	require.Equal(t, CodeMarketNotActive, res.Code)
+
+	// a listing proposal does nothing while it's being voted on
+	proposalID := submitProposal(t, ctx, keeper, NewListMarketAction("ETH", "ATOM"))
+	_, found := keeper.GetProposalAction(ctx, proposalID)
+	require.True(t, found)
+	decideProposal(ctx, keeper, proposalID, gov.StatusVotingPeriod)
+	require.False(t, keeper.IsMarketActive(ctx, NewDenomPair("ATOM", "ETH")))
+
+	tags := decideProposal(ctx, keeper, proposalID, gov.StatusPassed)
+	require.Equal(t, "applied-proposal-id", string(tags[0].Key))
+	_, found = keeper.GetProposalAction(ctx, proposalID)
+	require.False(t, found)
+	require.True(t, keeper.IsMarketActive(ctx, NewDenomPair("ATOM", "ETH")))
+
+	res = handler(ctx, makeOrderMsg(alice, 10, "ETH", "2", "ATOM", STPNone))
+	require.True(t, res.IsOK(), res.Log)
+
+	// a listed market can't be listed again
+	msg := NewMsgSubmitOrderbookProposal(alice, "title", "description", nil, NewListMarketAction("ATOM", "ETH"))
+	require.Equal(t, CodeInvalidProposal, handler(ctx, msg).Code)
+
+	// halting keeps the resting orders, which can still be removed, but no new orders can be made
+	res = handler(ctx, makeOrderMsg(bob, 10, "BTC", "2", "ETH", STPNone))
+	require.True(t, res.IsOK(), res.Log)
+	var result MakeOrderResult
+	keeper.cdc.MustUnmarshalJSON(res.Data, &result)
+
+	proposalID = submitProposal(t, ctx, keeper, NewHaltMarketAction("BTC", "ETH"))
+	decideProposal(ctx, keeper, proposalID, gov.StatusPassed)
+
+	market, found := keeper.GetMarket(ctx, NewDenomPair("ETH", "BTC"))
+	require.True(t, found)
+	require.Equal(t, MarketStatusHalted, market.Status)
+
+	res = handler(ctx, makeOrderMsg(alice, 10, "ETH", "0.5", "BTC", STPNone))
+	require.Equal(t, CodeMarketNotActive, res.Code)
+	res = handler(ctx, NewMsgRemoveOrder(bob, result.OrderID))
+	require.True(t, res.IsOK(), res.Log)
+
+	// listing a halted market resumes trading
+	proposalID = submitProposal(t, ctx, keeper, NewListMarketAction("BTC", "ETH"))
+	decideProposal(ctx, keeper, proposalID, gov.StatusPassed)
+	require.True(t, keeper.IsMarketActive(ctx, NewDenomPair("BTC", "ETH")))
+}
+
+func TestDelistMarketProposal(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	handler := NewHandler(keeper)
+
+	res := handler(ctx, makeOrderMsg(bob, 10, "BTC", "2", "ETH", STPNone))
+	require.True(t, res.IsOK(), res.Log)
+	res = handler(ctx, makeOrderMsg(alice, 10, "ETH", "0.8", "BTC", STPNone))
+	require.True(t, res.IsOK(), res.Log)
+	twap, err := keeper.AddNewTWAPOrder(ctx, aliceMarketTWAP(50, 10))
+	require.Nil(t, err)
+
+	// a rejected proposal is forgotten
+	proposalID := submitProposal(t, ctx, keeper, NewDelistMarketAction("ETH", "BTC"))
+	decideProposal(ctx, keeper, proposalID, gov.StatusRejected)
+	_, found := keeper.GetProposalAction(ctx, proposalID)
+	require.False(t, found)
+	require.True(t, keeper.IsMarketActive(ctx, NewDenomPair("BTC", "ETH")))
+
+	// delisting cancels and refunds the orders in both orderwalls, and the TWAPOrders making slices in the market
+	proposalID = submitProposal(t, ctx, keeper, NewDelistMarketAction("ETH", "BTC"))
+	decideProposal(ctx, keeper, proposalID, gov.StatusPassed)
+
+	_, found = keeper.GetMarket(ctx, NewDenomPair("BTC", "ETH"))
+	require.False(t, found)
+	require.Empty(t, keeper.PeekOrderwallOrders(ctx, NewDenomPair("BTC", "ETH"), 10))
+	require.Empty(t, keeper.PeekOrderwallOrders(ctx, NewDenomPair("ETH", "BTC"), 10))
+
+	require.Equal(t, int64(1000), keeper.coinKeeper.GetCoins(ctx, bob).AmountOf("BTC").Int64())
+	require.Equal(t, int64(1000), keeper.coinKeeper.GetCoins(ctx, alice).AmountOf("ETH").Int64())
+
+	order, found := keeper.GetClosedOrder(ctx, 1)
+	require.True(t, found)
+	require.Equal(t, OrderStatusCancelled, order.Status)
+
+	twap, found = keeper.GetTWAPOrder(ctx, twap.TWAPID)
+	require.True(t, found)
+	require.Equal(t, TWAPStatusCancelled, twap.Status)
+	iterator := keeper.TWAPQueueIterator(ctx, ctx.BlockHeight()+1000)
+	require.False(t, iterator.Valid())
+	iterator.Close()
+}
+
+func TestChangeParamsProposal(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+
+	params := keeper.GetParams(ctx)
+	params.MaxFills = 0
+	msg := NewMsgSubmitOrderbookProposal(alice, "title", "description", nil, NewChangeParamsAction(params))
+	require.NotNil(t, msg.ValidateBasic())
+
+	params.MaxFills = 5
+	proposalID := submitProposal(t, ctx, keeper, NewChangeParamsAction(params))
+	require.Equal(t, gov.ProposalTypeParameterChange, keeper.govKeeper.GetProposal(ctx, proposalID).GetProposalType())
+
+	decideProposal(ctx, keeper, proposalID, gov.StatusPassed)
+	require.Equal(t, int64(5), keeper.MaxFills(ctx))
+}
@@ -1,6 +1,7 @@
 package orderbook
 
 import (
+	"fmt"
 	"strconv"
 
 	"github.com/cosmos/cosmos-sdk/codec"
@@ -11,18 +12,52 @@ import (
 
 // query endpoints supported by the governance Querier
 const (
-	QueryOrder     = "order"
-	QueryOrderwall = "orderwall"
+	QueryOrder       = "order"
+	QueryOrderwall   = "orderwall"
+	QueryTWAPOrder   = "twap"
+	QueryClientOrder = "client-order"
+	QueryParams      = "params"
+	QueryMarkets     = "markets"
+	QueryDepth       = "depth"
+	QueryTrades      = "trades"
+	QueryTrade       = "trade"
+	QueryOwnerOrders = "owner-orders"
 )
 
+// Number of trades returned by a trades query when no limit is given
+const DefaultTradesLimit int64 = 100
+
 // NewQuerier is the module level router for state queries
 func NewQuerier(keeper Keeper) sdk.Querier {
 	return func(ctx sdk.Context, path []string, req abci.RequestQuery) (res []byte, err sdk.Error) {
+		// custom queries are run against the latest state whatever height they ask for.  Past heights are read
+		// from the raw store instead, see client.ProvenState
+		if req.Height != 0 && req.Height != ctx.BlockHeight() {
+			return nil, sdk.ErrUnknownRequest(fmt.Sprintf("orderbook queries are only served at the latest height %d, not at height %d",
+				ctx.BlockHeight(), req.Height))
+		}
+
 		switch path[0] {
 		case QueryOrder:
 			return queryOrder(ctx, path[1:], req, keeper)
 		case QueryOrderwall:
 			return queryOrderwall(ctx, path[1:], req, keeper)
+		case QueryTWAPOrder:
+			return queryTWAPOrder(ctx, path[1:], req, keeper)
+		case QueryClientOrder:
+			return queryClientOrder(ctx, path[1:], req, keeper)
+		case QueryParams:
+			return queryParams(ctx, path[1:], req, keeper)
+		case QueryMarkets:
+			return queryMarkets(ctx, path[1:], req, keeper)
+		case QueryDepth:
+			return queryDepth(ctx, path[1:], req, keeper)
+		case QueryTrades:
+			return queryTrades(ctx, path[1:], req, keeper)
+		case QueryTrade:
+			return queryTrade(ctx, path[1:], req, keeper)
+		case QueryOwnerOrders:
+			return queryOwnerOrders(ctx, path[1:], req, keeper)
 		default:
 			return nil, sdk.ErrUnknownRequest("unknown orderbook query endpoint")
 		}
@@ -38,13 +73,13 @@ func queryOrder(ctx sdk.Context, path []string, req abci.RequestQuery, keeper Ke
 		return res, ErrInvalidOrderID(keeper.codespace)
 	}
 
-	order, found := keeper.GetOrder(ctx, orderID)
+	order, found := keeper.GetOrderOrClosedOrder(ctx, orderID)
 
 	if !found {
 		return res, ErrOrderNotFound(keeper.codespace, orderID)
 	}
 
-	res, err2 = codec.MarshalJSONIndent(keeper.cdc, order)
+	res, err2 = codec.MarshalJSONIndent(keeper.cdc, order.Visible())
 	if err2 != nil {
 		panic("could not marshal result to JSON")
 	}
@@ -78,7 +113,7 @@ func queryOrderwall(ctx sdk.Context, path []string, req abci.RequestQuery, keepe
 
 		order, found := keeper.GetOrder(ctx, orderID)
 		if found {
-			orderwall = append(orderwall, order)
+			orderwall = append(orderwall, order.Visible())
 		}
 	}
 
@@ -91,3 +126,172 @@ func queryOrderwall(ctx sdk.Context, path []string, req abci.RequestQuery, keepe
 
 	return res, nil
 }
+
+// nolint: unparam
+func queryTWAPOrder(ctx sdk.Context, path []string, req abci.RequestQuery, keeper Keeper) (res []byte, err sdk.Error) {
+	twapID, err2 := strconv.ParseInt(path[0], 10, 64)
+	if err2 != nil {
+		return res, ErrInvalidOrderID(keeper.codespace)
+	}
+
+	twap, found := keeper.GetTWAPOrder(ctx, twapID)
+	if !found {
+		return res, ErrTWAPOrderNotFound(keeper.codespace, twapID)
+	}
+
+	progress := TWAPProgress{
+		TWAPOrder:    twap,
+		AveragePrice: twap.AveragePrice(),
+	}
+
+	res, err2 = codec.MarshalJSONIndent(keeper.cdc, progress)
+	if err2 != nil {
+		panic("could not marshal result to JSON")
+	}
+
+	return res, nil
+}
+
+// nolint: unparam
+func queryClientOrder(ctx sdk.Context, path []string, req abci.RequestQuery, keeper Keeper) (res []byte, err sdk.Error) {
+	if len(path) != 2 {
+		return res, sdk.ErrUnknownRequest("client-order query needs an owner and a client order ID")
+	}
+
+	owner, err2 := sdk.AccAddressFromBech32(path[0])
+	if err2 != nil {
+		return res, sdk.ErrInvalidAddress(path[0])
+	}
+
+	orderID, found := keeper.GetOrderIDByClientOrderID(ctx, owner, path[1])
+	if !found {
+		return res, ErrOrderNotFound(keeper.codespace, orderID)
+	}
+
+	return queryOrder(ctx, []string{strconv.FormatInt(orderID, 10)}, req, keeper)
+}
+
+// nolint: unparam
+func queryParams(ctx sdk.Context, path []string, req abci.RequestQuery, keeper Keeper) (res []byte, err sdk.Error) {
+	res, err2 := codec.MarshalJSONIndent(keeper.cdc, keeper.GetParams(ctx))
+	if err2 != nil {
+		panic("could not marshal result to JSON")
+	}
+
+	return res, nil
+}
+
+// nolint: unparam
+func queryMarkets(ctx sdk.Context, path []string, req abci.RequestQuery, keeper Keeper) (res []byte, err sdk.Error) {
+	res, err2 := codec.MarshalJSONIndent(keeper.cdc, keeper.GetMarkets(ctx))
+	if err2 != nil {
+		panic("could not marshal result to JSON")
+	}
+
+	return res, nil
+}
+
+// nolint: unparam
+func queryDepth(ctx sdk.Context, path []string, req abci.RequestQuery, keeper Keeper) (res []byte, err sdk.Error) {
+	denomPair, levels, err := parsePairAndLimit(path, DefaultDepthLevels, keeper.codespace)
+	if err != nil {
+		return res, err
+	}
+
+	res, err2 := codec.MarshalJSONIndent(keeper.cdc, keeper.GetDepth(ctx, denomPair, levels))
+	if err2 != nil {
+		panic("could not marshal result to JSON")
+	}
+
+	return res, nil
+}
+
+// nolint: unparam
+func queryTrades(ctx sdk.Context, path []string, req abci.RequestQuery, keeper Keeper) (res []byte, err sdk.Error) {
+	denomPair, limit, err := parsePairAndLimit(path, DefaultTradesLimit, keeper.codespace)
+	if err != nil {
+		return res, err
+	}
+
+	res, err2 := codec.MarshalJSONIndent(keeper.cdc, keeper.GetRecentTrades(ctx, denomPair, limit))
+	if err2 != nil {
+		panic("could not marshal result to JSON")
+	}
+
+	return res, nil
+}
+
+// nolint: unparam
+func queryTrade(ctx sdk.Context, path []string, req abci.RequestQuery, keeper Keeper) (res []byte, err sdk.Error) {
+	if len(path) != 2 {
+		return res, sdk.ErrUnknownRequest("trade query needs a denom pair and a trade ID")
+	}
+
+	denomPair, err2 := DenomPairFromStr(path[0])
+	if err2 != nil {
+		return res, ErrInvalidDenomPair(keeper.codespace)
+	}
+
+	tradeID, err2 := strconv.ParseInt(path[1], 10, 64)
+	if err2 != nil {
+		return res, sdk.ErrUnknownRequest("trade ID must be an integer")
+	}
+
+	trade, found := keeper.GetTrade(ctx, denomPair, tradeID)
+	if !found {
+		return res, sdk.ErrUnknownRequest(fmt.Sprintf("trade %d of %s not found", tradeID, denomPair))
+	}
+
+	res, err2 = codec.MarshalJSONIndent(keeper.cdc, trade)
+	if err2 != nil {
+		panic("could not marshal result to JSON")
+	}
+
+	return res, nil
+}
+
+// nolint: unparam
+func queryOwnerOrders(ctx sdk.Context, path []string, req abci.RequestQuery, keeper Keeper) (res []byte, err sdk.Error) {
+	if len(path) != 1 {
+		return res, sdk.ErrUnknownRequest("owner-orders query needs an owner")
+	}
+
+	owner, err2 := sdk.AccAddressFromBech32(path[0])
+	if err2 != nil {
+		return res, sdk.ErrInvalidAddress(path[0])
+	}
+
+	orders := []Order{}
+	for _, order := range keeper.GetOwnerOrders(ctx, owner) {
+		orders = append(orders, order.Visible())
+	}
+
+	res, err2 = codec.MarshalJSONIndent(keeper.cdc, orders)
+	if err2 != nil {
+		panic("could not marshal result to JSON")
+	}
+
+	return res, nil
+}
+
+// Parses the path of a query taking a DenomPair and an optional positive limit
+func parsePairAndLimit(path []string, defaultLimit int64, codespace sdk.CodespaceType) (denomPair DenomPair, limit int64, err sdk.Error) {
+	if len(path) < 1 || len(path) > 2 {
+		return denomPair, limit, sdk.ErrUnknownRequest("query needs a denom pair and an optional limit")
+	}
+
+	denomPair, err2 := DenomPairFromStr(path[0])
+	if err2 != nil {
+		return denomPair, limit, ErrInvalidDenomPair(codespace)
+	}
+
+	limit = defaultLimit
+	if len(path) == 2 {
+		limit, err2 = strconv.ParseInt(path[1], 10, 64)
+		if err2 != nil || limit <= 0 {
+			return denomPair, limit, sdk.ErrUnknownRequest("limit must be a positive integer")
+		}
+	}
+
+	return denomPair, limit, nil
+}
@@ -0,0 +1,312 @@
+package orderbook
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/mock"
+)
+
+var (
+	simulationSeed      int64
+	simulationNumBlocks int
+)
+
+func init() {
+	flag.Int64Var(&simulationSeed, "SimulationSeed", 42, "Seed of the random orderbook simulation")
+	flag.IntVar(&simulationNumBlocks, "SimulationNumBlocks", 2000, "Number of blocks of the random orderbook simulation")
+}
+
+const (
+	simulationAccounts      = 50
+	simulationMaxTxs        = 20
+	simulationMaxAmount     = 1000
+	simulationBlockTime     = 5 * time.Second
+	simulationUnlistedDenom = "DOGE"
+)
+
+// markets of the simulation, with the price they are quoted around, in QuoteDenom per BaseDenom
+var simulationMarkets = []struct {
+	market Market
+	mid    sdk.Dec
+}{
+	{NewMarket("BTC", "ETH"), sdk.NewDec(20)},
+	{NewMarket("ETH", "XRP"), sdk.NewDec(500)},
+	{NewMarket("BTC", "XRP"), sdk.NewDec(10000)},
+}
+
+// simulation sends random valid and invalid orderbook transactions to a mock app, block after block
+type simulation struct {
+	t        *testing.T
+	r        *rand.Rand
+	mapp     *mock.App
+	keeper   Keeper
+	addrs    []sdk.AccAddress
+	privKeys []crypto.PrivKey
+	supply   sdk.Coins
+	header   abci.Header
+
+	// number of transactions by kind and outcome
+	stats map[string]int
+}
+
+func newSimulation(t *testing.T, seed int64) *simulation {
+	params := DefaultParams()
+	params.MaxFills = 5
+	params.MakerFeeRate = sdk.NewDecWithPrec(1, 3)
+	params.TakerFeeRate = sdk.NewDecWithPrec(2, 3)
+	var markets []Market
+	for _, m := range simulationMarkets {
+		markets = append(markets, m.market)
+	}
+
+	genCoins := sdk.Coins{
+		sdk.NewInt64Coin("BTC", 10000000),
+		sdk.NewInt64Coin(simulationUnlistedDenom, 10000000),
+		sdk.NewInt64Coin("ETH", 10000000),
+		sdk.NewInt64Coin("XRP", 10000000),
+	}
+	mapp, keeper, addrs, privKeys := getMockApp(t, simulationAccounts, genCoins, NewGenesisState(params, markets))
+
+	var supply sdk.Coins
+	for range addrs {
+		supply = supply.Plus(genCoins)
+	}
+
+	return &simulation{
+		t:        t,
+		r:        rand.New(rand.NewSource(seed)),
+		mapp:     mapp,
+		keeper:   keeper,
+		addrs:    addrs,
+		privKeys: privKeys,
+		supply:   supply,
+		header:   abci.Header{Height: mapp.LastBlockHeight(), Time: time.Unix(0, 0).UTC()},
+		stats:    make(map[string]int),
+	}
+}
+
+// returns a context on the state committed by the last block
+func (s *simulation) committedContext() sdk.Context {
+	return s.mapp.BaseApp.NewContext(true, abci.Header{})
+}
+
+// runs a block of transactions from distinct random accounts, so that their sequences can be read from the last block
+func (s *simulation) runBlock() {
+	ctx := s.committedContext()
+	s.header.Height++
+	s.header.Time = s.header.Time.Add(simulationBlockTime)
+	s.mapp.BeginBlock(abci.RequestBeginBlock{Header: s.header})
+
+	for _, i := range s.r.Perm(len(s.addrs))[:s.r.Intn(simulationMaxTxs)+1] {
+		var msgs []sdk.Msg
+		var kinds []string
+		mustFail := false
+		for n := 1 + s.r.Intn(10)/9; n > 0; n-- {
+			msg, kind, invalid := s.randomMsg(ctx, i)
+			msgs = append(msgs, msg)
+			kinds = append(kinds, kind)
+			mustFail = mustFail || invalid
+		}
+
+		acc := s.mapp.AccountKeeper.GetAccount(ctx, s.addrs[i])
+		res := s.mapp.Deliver(genTx(msgs, acc.GetAccountNumber(), acc.GetSequence(), s.privKeys[i]))
+		if mustFail {
+			require.False(s.t, res.IsOK(), "invalid %v succeeded at height %d with seed %d", kinds, s.header.Height, simulationSeed)
+		}
+
+		outcome := "ok"
+		if !res.IsOK() {
+			outcome = "failed"
+		}
+		for _, kind := range kinds {
+			s.stats[kind+" "+outcome]++
+		}
+	}
+
+	s.mapp.EndBlock(abci.RequestEndBlock{})
+	s.mapp.Commit()
+	s.keeper.ResetCheckTxOrderbookCache()
+}
+
+// returns a random msg of the account at index i, its kind, and whether it is invalid and must fail
+func (s *simulation) randomMsg(ctx sdk.Context, i int) (msg sdk.Msg, kind string, invalid bool) {
+	switch s.r.Intn(10) {
+	case 0:
+		return s.invalidMakeOrder(ctx, i), "invalid make", true
+	case 1:
+		return s.invalidRemoveOrder(ctx, i), "invalid remove", true
+	case 2, 3, 4:
+		if msg, ok := s.removeOrder(ctx, i); ok {
+			return msg, "remove", false
+		}
+	}
+	return s.makeOrder(ctx, i), "make", false
+}
+
+// returns a random order around the mid price of a random market, in a random direction
+func (s *simulation) makeOrder(ctx sdk.Context, i int) MsgMakeOrder {
+	m := simulationMarkets[s.r.Intn(len(simulationMarkets))]
+	pair := m.market.Pair()
+	// between 10% below and 10% above the mid price
+	price := m.mid.Mul(sdk.NewDecWithPrec(int64(900+s.r.Intn(201)), 3))
+	if s.r.Intn(2) == 0 {
+		pair = pair.ReversePair()
+		price = SDKDecReciprocal(price)
+	}
+
+	amount := sdk.NewInt(s.r.Int63n(simulationMaxAmount) + 1)
+	balance := s.mapp.AccountKeeper.GetAccount(ctx, s.addrs[i]).GetCoins().AmountOf(pair.SellDenom)
+	if amount.GT(balance) && balance.IsPositive() {
+		amount = balance
+	}
+
+	clientOrderID := ""
+	if s.r.Intn(5) == 0 {
+		// drawn from few enough IDs that some are reused while their order rests
+		clientOrderID = fmt.Sprintf("order-%d", s.r.Intn(20))
+	}
+	displayAmount := sdk.ZeroInt()
+	if s.r.Intn(10) == 0 {
+		displayAmount = amount.Div(sdk.NewInt(4))
+	}
+	var expirationTime time.Time
+	if s.r.Intn(10) == 0 {
+		expirationTime = s.header.Time.Add(time.Duration(s.r.Intn(20)+1) * simulationBlockTime)
+	}
+	stp := STPNone
+	if s.r.Intn(5) == 0 {
+		stp = SelfTradePrevention(s.r.Intn(len(selfTradePreventionStrings)))
+	}
+
+	return NewMsgMakeOrder(s.addrs[i], clientOrderID, sdk.NewCoin(pair.SellDenom, amount),
+		NewPrice(price, pair.BuyDenom, pair.SellDenom), expirationTime, displayAmount, stp)
+}
+
+// returns an order that can't be made
+func (s *simulation) invalidMakeOrder(ctx sdk.Context, i int) MsgMakeOrder {
+	msg := s.makeOrder(ctx, i)
+	switch s.r.Intn(7) {
+	case 0:
+		msg.SellCoins.Amount = sdk.ZeroInt()
+	case 1:
+		// more than all accounts hold together
+		msg.SellCoins.Amount = s.supply.AmountOf(msg.SellCoins.Denom).Add(sdk.OneInt())
+	case 2:
+		msg.SellCoins.Denom = simulationUnlistedDenom
+		msg.Price.DenomenatorDenom = simulationUnlistedDenom
+	case 3:
+		msg.Price.DenomenatorDenom = msg.Price.NumeratorDenom
+	case 4:
+		msg.Price.Ratio = maxDec.Mul(sdk.NewDec(10))
+	case 5:
+		msg.SelfTradePrevention = SelfTradePrevention(len(selfTradePreventionStrings) + s.r.Intn(10))
+	case 6:
+		msg.DisplayAmount = msg.SellCoins.Amount.Add(sdk.OneInt())
+	}
+	return msg
+}
+
+// removes a random resting order of the account at index i, by order ID or by client order ID
+func (s *simulation) removeOrder(ctx sdk.Context, i int) (msg MsgRemoveOrder, ok bool) {
+	orders := s.keeper.GetOwnerOrders(ctx, s.addrs[i])
+	if len(orders) == 0 {
+		return msg, false
+	}
+	order := orders[s.r.Intn(len(orders))]
+	if order.ClientOrderID != "" && s.r.Intn(2) == 0 {
+		return NewMsgRemoveOrderByClientOrderID(s.addrs[i], order.ClientOrderID), true
+	}
+	return NewMsgRemoveOrder(s.addrs[i], order.OrderID), true
+}
+
+// returns a removal of an order that doesn't exist or belongs to another account
+func (s *simulation) invalidRemoveOrder(ctx sdk.Context, i int) MsgRemoveOrder {
+	switch s.r.Intn(3) {
+	case 0:
+		other := (i + 1 + s.r.Intn(len(s.addrs)-1)) % len(s.addrs)
+		if orders := s.keeper.GetOwnerOrders(ctx, s.addrs[other]); len(orders) > 0 {
+			return NewMsgRemoveOrder(s.addrs[i], orders[0].OrderID)
+		}
+		fallthrough
+	case 1:
+		return NewMsgRemoveOrder(s.addrs[i], s.keeper.GetLastOrderID(ctx)+1000)
+	default:
+		return NewMsgRemoveOrderByClientOrderID(s.addrs[i], "unknown")
+	}
+}
+
+// checks the registered invariants of the orderbook and coin conservation on the committed state
+func (s *simulation) checkInvariants() error {
+	ctx := s.committedContext()
+	err := checkCoinConservation(ctx, s.mapp, s.keeper, s.supply)
+	if err != nil {
+		return err
+	}
+	return AssertInvariants(ctx, s.keeper)
+}
+
+// checks that the coins of all accounts, the coins escrowed in resting orders and the collected fees
+// add up to the supply at genesis
+func checkCoinConservation(ctx sdk.Context, mapp *mock.App, keeper Keeper, supply sdk.Coins) error {
+	var total sdk.Coins
+	mapp.AccountKeeper.IterateAccounts(ctx, func(acc auth.Account) bool {
+		total = total.Plus(acc.GetCoins())
+		return false
+	})
+	keeper.IterateOrders(ctx, func(order Order) bool {
+		if order.TotalSellCoins().IsPositive() {
+			total = total.Plus(sdk.Coins{order.TotalSellCoins()})
+		}
+		return false
+	})
+	total = total.Plus(mapp.FeeCollectionKeeper.GetCollectedFees(ctx))
+
+	if !total.IsEqual(supply) {
+		return fmt.Errorf("coins aren't conserved: %s in total instead of %s", total, supply)
+	}
+	return nil
+}
+
+func TestSimulation(t *testing.T) {
+	numBlocks := simulationNumBlocks
+	if testing.Short() {
+		numBlocks = 100
+	}
+
+	s := newSimulation(t, simulationSeed)
+	for i := 0; i < numBlocks; i++ {
+		s.runBlock()
+		err := s.checkInvariants()
+		if err != nil {
+			t.Fatalf("invariant broken at height %d, rerun with -SimulationSeed=%d: %v", s.header.Height, simulationSeed, err)
+		}
+	}
+
+	t.Logf("simulated %d blocks with seed %d: %v", numBlocks, simulationSeed, s.stats)
+	require.True(t, s.stats["make ok"] > 0)
+	require.True(t, s.stats["remove ok"] > 0)
+	require.True(t, s.keeper.GetLastTradeID(s.committedContext()) > 0)
+}
+
+func TestSimulationReproducible(t *testing.T) {
+	var hashes [][]byte
+	for i := 0; i < 2; i++ {
+		s := newSimulation(t, simulationSeed)
+		for block := 0; block < 50; block++ {
+			s.runBlock()
+		}
+		hashes = append(hashes, s.mapp.LastCommitID().Hash)
+	}
+	require.Equal(t, hashes[0], hashes[1])
+}
@@ -0,0 +1,45 @@
+package orderbook
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Tags of the orderbook, so that clients can follow the changes of the orderwalls from the results of blocks
+const (
+	// market whose orderwalls a tx or block changed, as its pair with the denoms in sorted order
+	TagMarket = "market"
+	// order made by a MsgMakeOrder
+	TagOrderID = "order-id"
+	// order removed by a MsgRemoveOrder
+	TagCancelledOrderID = "cancelled-order-id"
+	// order closed because it expired
+	TagExpiredOrderID = "expired-order-id"
+	// fill between a maker and a taker order
+	TagTradeID = "trade-id"
+	// fill made in the EndBlocker, like by a TWAP slice, as its JSON.  EndBlock results have no data to hold it
+	TagTrade = "trade"
+	// TWAPOrder that executed a slice
+	TagTWAPID = "twap-id"
+	// TWAPOrder whose slice couldn't be made, like when its market is halted
+	TagFailedTWAPID = "failed-twap-id"
+	// TWAPOrder cancelled and refunded because its slices can't be made anymore
+	TagCancelledTWAPID = "cancelled-twap-id"
+	// passed orderbook proposal that was applied
+	TagAppliedProposalID = "applied-proposal-id"
+	// proposal submitted by a MsgSubmitOrderbookProposal
+	TagProposalID = "proposal-id"
+	// schema version the orderbook store was migrated to
+	TagSchemaVersion = "schema-version"
+)
+
+// Returns the tag of the market of a pair
+func MarketTag(pair DenomPair) sdk.Tags {
+	return sdk.NewTags(TagMarket, []byte(marketPair(pair).String()))
+}
+
+// Returns an int64 ID as a tag value
+func idTagValue(id int64) []byte {
+	return []byte(fmt.Sprintf("%d", id))
+}
@@ -1,105 +1,101 @@
 package orderbook
 
-// import (
-// 	"bytes"
-// 	"log"
-// 	"sort"
-// 	"testing"
-
-// 	"github.com/stretchr/testify/require"
-
-// 	abci "github.com/tendermint/tendermint/abci/types"
-// 	"github.com/tendermint/tendermint/crypto"
-
-// 	sdk "github.com/cosmos/cosmos-sdk/types"
-// 	"github.com/cosmos/cosmos-sdk/x/bank"
-// 	"github.com/cosmos/cosmos-sdk/x/mock"
-// )
-
-// // initialize the mock application for this module
-// func getMockApp(t *testing.T, numGenAccs int) (*mock.App, Keeper, bank.Keeper, []sdk.AccAddress, []crypto.PubKey, []crypto.PrivKey) {
-// 	mapp := mock.NewApp()
-
-// 	RegisterCodec(mapp.Cdc)
-
-// 	keyOrderbook := sdk.NewKVStoreKey("orderbook")
-
-// 	ck := bank.NewBaseKeeper(mapp.AccountKeeper)
-// 	keeper := NewKeeper(ck, keyOrderbook, mapp.Cdc, DefaultCodespace)
-
-// 	mapp.Router().AddRoute("orderbook", NewHandler(keeper))
-
-// 	mapp.SetInitChainer(getInitChainer(mapp))
-
-// 	require.NoError(t, mapp.CompleteSetup(keyOrderbook))
-
-// 	genAccs, addrs, pubKeys, privKeys := mock.CreateGenAccounts(numGenAccs, sdk.Coins{sdk.NewInt64Coin("steak", 42)})
-
-// 	mock.SetGenesis(mapp, genAccs)
-
-// 	return mapp, keeper, ck, addrs, pubKeys, privKeys
-// }
-
-// // gov and stake initchainer
-// func getInitChainer(mapp *mock.App) sdk.InitChainer {
-// 	return func(ctx sdk.Context, req abci.RequestInitChain) abci.ResponseInitChain {
-// 		mapp.InitChainer(ctx, req)
-// 	}
-// }
-
-// // TODO: Remove once address interface has been implemented (ref: #2186)
-// func SortValAddresses(addrs []sdk.ValAddress) {
-// 	var byteAddrs [][]byte
-// 	for _, addr := range addrs {
-// 		byteAddrs = append(byteAddrs, addr.Bytes())
-// 	}
-
-// 	SortByteArrays(byteAddrs)
-
-// 	for i, byteAddr := range byteAddrs {
-// 		addrs[i] = byteAddr
-// 	}
-// }
-
-// // Sorts Addresses
-// func SortAddresses(addrs []sdk.AccAddress) {
-// 	var byteAddrs [][]byte
-// 	for _, addr := range addrs {
-// 		byteAddrs = append(byteAddrs, addr.Bytes())
-// 	}
-// 	SortByteArrays(byteAddrs)
-// 	for i, byteAddr := range byteAddrs {
-// 		addrs[i] = byteAddr
-// 	}
-// }
-
-// // implement `Interface` in sort package.
-// type sortByteArrays [][]byte
-
-// func (b sortByteArrays) Len() int {
-// 	return len(b)
-// }
-
-// func (b sortByteArrays) Less(i, j int) bool {
-// 	// bytes package already implements Comparable for []byte.
-// 	switch bytes.Compare(b[i], b[j]) {
-// 	case -1:
-// 		return true
-// 	case 0, 1:
-// 		return false
-// 	default:
-// 		log.Panic("not fail-able with `bytes.Comparable` bounded [-1, 1].")
-// 		return false
-// 	}
-// }
-
-// func (b sortByteArrays) Swap(i, j int) {
-// 	b[j], b[i] = b[i], b[j]
-// }
-
-// // Public
-// func SortByteArrays(src [][]byte) [][]byte {
-// 	sorted := sortByteArrays(src)
-// 	sort.Sort(sorted)
-// 	return sorted
-// }
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+	"github.com/cosmos/cosmos-sdk/x/mock"
+	"github.com/cosmos/cosmos-sdk/x/params"
+)
+
+// gas limit of the transactions built by genTx, enough for any orderbook msg
+const mockTxGas = 10000000
+
+// initialize the mock application for this module, with numGenAccs accounts funded with genCoins
+// and the orderbook started from genesis
+func getMockApp(t testing.TB, numGenAccs int, genCoins sdk.Coins, genesis GenesisState) (*mock.App, Keeper, []sdk.AccAddress, []crypto.PrivKey) {
+	mapp := mock.NewApp()
+
+	RegisterCodec(mapp.Cdc)
+
+	keyOrderbook := sdk.NewKVStoreKey("orderbook")
+	keyGlobalParams := sdk.NewKVStoreKey("params")
+	tkeyGlobalParams := sdk.NewTransientStoreKey("transient_params")
+
+	pk := params.NewKeeper(mapp.Cdc, keyGlobalParams, tkeyGlobalParams)
+	ck := bank.NewBaseKeeper(mapp.AccountKeeper)
+	// the keeper caches the orderwalls like the one of DexterApp does
+	keeper := NewKeeper(ck, mapp.FeeCollectionKeeper, nil, keyOrderbook, mapp.Cdc,
+		pk.Subspace(DefaultParamspace), DefaultCodespace).WithOrderbookCache(DefaultCacheDepth)
+
+	mapp.Router().AddRoute("orderbook", NewHandler(keeper))
+	mapp.SetEndBlocker(getEndBlocker(keeper))
+	mapp.SetInitChainer(getInitChainer(mapp, keeper, genesis))
+
+	require.NoError(t, mapp.CompleteSetup(keyOrderbook, keyGlobalParams, tkeyGlobalParams))
+
+	genAccs, addrs, privKeys := createGenAccounts(numGenAccs, genCoins)
+
+	mock.SetGenesis(mapp, genAccs)
+
+	return mapp, keeper, addrs, privKeys
+}
+
+// creates numAccs accounts funded with genCoins.  Unlike mock.CreateGenAccounts, the keys are derived from the
+// index of the account, so that chains started with them are reproducible
+func createGenAccounts(numAccs int, genCoins sdk.Coins) (genAccs []auth.Account, addrs []sdk.AccAddress, privKeys []crypto.PrivKey) {
+	for i := 0; i < numAccs; i++ {
+		privKey := secp256k1.GenPrivKeySecp256k1([]byte(fmt.Sprintf("orderbook-account-%d", i)))
+		addr := sdk.AccAddress(privKey.PubKey().Address())
+		genAccs = append(genAccs, &auth.BaseAccount{
+			Address: addr,
+			Coins:   genCoins,
+		})
+		addrs = append(addrs, addr)
+		privKeys = append(privKeys, privKey)
+	}
+	return genAccs, addrs, privKeys
+}
+
+// orderbook initchainer
+func getInitChainer(mapp *mock.App, keeper Keeper, genesis GenesisState) sdk.InitChainer {
+	return func(ctx sdk.Context, req abci.RequestInitChain) abci.ResponseInitChain {
+		mapp.InitChainer(ctx, req)
+		InitGenesis(ctx, keeper, genesis)
+		return abci.ResponseInitChain{}
+	}
+}
+
+// orderbook endblocker
+func getEndBlocker(keeper Keeper) sdk.EndBlocker {
+	return func(ctx sdk.Context, req abci.RequestEndBlock) abci.ResponseEndBlock {
+		tags := EndBlocker(ctx, keeper)
+		return abci.ResponseEndBlock{
+			Tags: tags,
+		}
+	}
+}
+
+// builds a transaction of msgs signed by priv, for a chain started with mock.SetGenesis
+func genTx(msgs []sdk.Msg, accnum int64, seq int64, priv crypto.PrivKey) auth.StdTx {
+	fee := auth.NewStdFee(mockTxGas)
+	sig, err := priv.Sign(auth.StdSignBytes("", accnum, seq, fee, msgs, ""))
+	if err != nil {
+		panic(err)
+	}
+	return auth.NewStdTx(msgs, fee, []auth.StdSignature{{
+		PubKey:        priv.PubKey(),
+		Signature:     sig,
+		AccountNumber: accnum,
+		Sequence:      seq,
+	}}, "")
+}
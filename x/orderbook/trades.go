@@ -0,0 +1,139 @@
+package orderbook
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+var lastTradeIDKey = KeyFromComponents([]byte("lastTradeID"))
+var tradesPrefix = KeyFromComponents([]byte("trades"))
+var tradeQueuePrefix = KeyFromComponents([]byte("tradeQueue"))
+
+// Trade is a fill between a resting maker order and an incoming taker order
+type Trade struct {
+	TradeID      int64          `json:"trade_id"`
+	MakerOrderID int64          `json:"maker_order_id"`
+	TakerOrderID int64          `json:"taker_order_id"`
+	Maker        sdk.AccAddress `json:"maker"`
+	Taker        sdk.AccAddress `json:"taker"`
+	MakerSold    sdk.Coin       `json:"maker_sold"`
+	TakerSold    sdk.Coin       `json:"taker_sold"`
+	// trading fees taken from what the maker and the taker received
+	MakerFee sdk.Coin `json:"maker_fee"`
+	TakerFee sdk.Coin `json:"taker_fee"`
+	// Price of the maker order the trade executed at, in units of TakerSold/MakerSold
+	Price  Price     `json:"price"`
+	Height int64     `json:"height"`
+	Time   time.Time `json:"time"`
+}
+
+// Returns the DenomPair of the maker's side of the trade
+func (t Trade) Pair() DenomPair {
+	return NewDenomPair(t.MakerSold.Denom, t.TakerSold.Denom)
+}
+
+// get key in store to get a Trade.  Trades of both directions of a pair are kept together, sorted by tradeID
+func TradeKey(pair DenomPair, tradeID int64) []byte {
+	return AppendKeyComponents(TradesPrefix(pair), Int64ToSortableBytes(tradeID))
+}
+
+// get prefix of the trades of a pair, shared by both of its directions
+func TradesPrefix(pair DenomPair) []byte {
+	return AppendKeyComponents(tradesPrefix, pairComponents(marketPair(pair))...)
+}
+
+// get key in the queue of trades, sorted by the height they were made at
+func TradeQueueKey(height int64, tradeID int64) []byte {
+	return AppendKeyComponents(tradeQueuePrefix, Int64ToSortableBytes(height), Int64ToSortableBytes(tradeID))
+}
+
+// Records a fill between a maker and a taker order in the trade history.
+// The fees are what's left of the coins sold by the other side after what the maker and the taker received
+func (k Keeper) recordTrade(ctx sdk.Context, maker Order, taker Order, makerSold sdk.Coin, takerSold sdk.Coin,
+	makerReceived sdk.Coin, takerReceived sdk.Coin) {
+	trade := Trade{
+		TradeID:      k.getNextTradeID(ctx),
+		MakerOrderID: maker.OrderID,
+		TakerOrderID: taker.OrderID,
+		Maker:        maker.Owner,
+		Taker:        taker.Owner,
+		MakerSold:    makerSold,
+		TakerSold:    takerSold,
+		MakerFee:     takerSold.Minus(makerReceived),
+		TakerFee:     makerSold.Minus(takerReceived),
+		Price:        maker.Price,
+		Height:       ctx.BlockHeight(),
+		Time:         ctx.BlockHeader().Time,
+	}
+
+	store := ctx.KVStore(k.storeKey)
+	store.Set(TradeKey(trade.Pair(), trade.TradeID), k.cdc.MustMarshalBinaryBare(trade))
+	store.Set(TradeQueueKey(trade.Height, trade.TradeID), TradeKey(trade.Pair(), trade.TradeID))
+}
+
+// Gets a Trade of a pair from the trade history
+func (k Keeper) GetTrade(ctx sdk.Context, pair DenomPair, tradeID int64) (trade Trade, found bool) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(TradeKey(pair, tradeID))
+	if bz == nil {
+		return trade, false
+	}
+	k.cdc.MustUnmarshalBinaryBare(bz, &trade)
+	return trade, true
+}
+
+// Gets the most recent trades of a pair, in both directions, newest first
+func (k Keeper) GetRecentTrades(ctx sdk.Context, pair DenomPair, limit int64) (trades []Trade) {
+	store := ctx.KVStore(k.storeKey)
+	prefix := TradesPrefix(pair)
+	iterator := store.ReverseIterator(prefix, sdk.PrefixEndBytes(prefix))
+	defer iterator.Close()
+
+	for ; iterator.Valid() && int64(len(trades)) < limit; iterator.Next() {
+		var trade Trade
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &trade)
+		trades = append(trades, trade)
+	}
+	return trades
+}
+
+// Removes the trades made more than OrderHistoryRetention blocks ago from the trade history
+func (k Keeper) PruneTrades(ctx sdk.Context) {
+	pruneHeight := ctx.BlockHeight() - OrderHistoryRetention
+	if pruneHeight < 0 {
+		return
+	}
+
+	store := ctx.KVStore(k.storeKey)
+	iterator := store.Iterator(tradeQueuePrefix, AppendKeyComponents(tradeQueuePrefix, Int64ToSortableBytes(pruneHeight+1)))
+
+	var prunedKeys [][]byte
+	for ; iterator.Valid(); iterator.Next() {
+		prunedKeys = append(prunedKeys, iterator.Key(), iterator.Value())
+	}
+	iterator.Close()
+
+	for _, key := range prunedKeys {
+		store.Delete(key)
+	}
+}
+
+// Gets the last tradeID that was assigned
+func (k Keeper) GetLastTradeID(ctx sdk.Context) (lastTradeID int64) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(lastTradeIDKey)
+	if bz == nil {
+		return 0
+	}
+	k.cdc.MustUnmarshalBinaryBare(bz, &lastTradeID)
+	return lastTradeID
+}
+
+// Gets the next unassigned tradeID (and increments lastTradeID)
+func (k Keeper) getNextTradeID(ctx sdk.Context) (nextTradeID int64) {
+	nextTradeID = k.GetLastTradeID(ctx) + 1
+	store := ctx.KVStore(k.storeKey)
+	store.Set(lastTradeIDKey, k.cdc.MustMarshalBinaryBare(nextTradeID))
+	return nextTradeID
+}
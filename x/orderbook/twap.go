@@ -0,0 +1,282 @@
+package orderbook
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+var lastTWAPIDKey = KeyFromComponents([]byte("lastTWAPID"))
+var twapOrdersPrefix = KeyFromComponents([]byte("twapOrders"))
+var twapQueuePrefix = KeyFromComponents([]byte("twapQueue"))
+
+// Statuses of a TWAPOrder
+const (
+	TWAPStatusActive    = "active"
+	TWAPStatusDone      = "done"
+	TWAPStatusCancelled = "cancelled"
+)
+
+// TWAPOrder sells SellCoins gradually by submitting a slice of SliceAmount every Interval blocks.
+// The full amount is escrowed when the TWAPOrder is made.  Each slice is executed against the opposing orderwall
+// at Price (or at any price for a market TWAPOrder) and whatever part of it doesn't fill is returned to the escrow
+type TWAPOrder struct {
+	TWAPID      int64
+	Owner       sdk.AccAddress
+	SellCoins   sdk.Coin
+	Price       Price
+	Market      bool
+	SliceAmount sdk.Int
+	Interval    int64
+
+	// coins still escrowed, coins sold so far and coins received for them
+	RemainingCoins sdk.Coin
+	FilledCoins    sdk.Coin
+	ReceivedCoins  sdk.Coin
+
+	NextHeight int64
+	Status     string
+}
+
+// Returns the average price the TWAPOrder has been filled at so far, in units of BuyDenom/SellDenom
+func (t TWAPOrder) AveragePrice() sdk.Dec {
+	if !t.FilledCoins.IsPositive() {
+		return sdk.ZeroDec()
+	}
+	return sdk.NewDecFromInt(t.ReceivedCoins.Amount).Quo(sdk.NewDecFromInt(t.FilledCoins.Amount))
+}
+
+// Returns the DenomPair the slices of the TWAPOrder are made in
+func (t TWAPOrder) Pair() DenomPair {
+	return NewDenomPair(t.SellCoins.Denom, t.Price.NumeratorDenom)
+}
+
+// TWAPProgress is the result of querying a TWAPOrder
+type TWAPProgress struct {
+	TWAPOrder    TWAPOrder `json:"twap_order"`
+	AveragePrice sdk.Dec   `json:"average_price"`
+}
+
+// get key in store to get a TWAPOrder
+func TWAPOrderKey(twapID int64) []byte {
+	return AppendKeyComponents(twapOrdersPrefix, Int64ToSortableBytes(twapID))
+}
+
+// get key in the queue of TWAPOrders waiting for their next slice
+func TWAPQueueKey(height int64, twapID int64) []byte {
+	return AppendKeyComponents(twapQueuePrefix, Int64ToSortableBytes(height), Int64ToSortableBytes(twapID))
+}
+
+// Gets a TWAPOrder from the Store
+func (k Keeper) GetTWAPOrder(ctx sdk.Context, twapID int64) (twap TWAPOrder, found bool) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(TWAPOrderKey(twapID))
+	if bz == nil {
+		return twap, false
+	}
+	err := k.cdc.UnmarshalBinaryBare(bz, &twap)
+	if err != nil {
+		return twap, false
+	}
+	return twap, true
+}
+
+// Sets a TWAPOrder in the Store
+func (k Keeper) SetTWAPOrder(ctx sdk.Context, twap TWAPOrder) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(TWAPOrderKey(twap.TWAPID), k.cdc.MustMarshalBinaryBare(twap))
+}
+
+// Gets the next unassigned twapID (and increments lastTWAPID)
+func (k Keeper) GetNextTWAPID(ctx sdk.Context) (nextTWAPID int64) {
+	store := ctx.KVStore(k.storeKey)
+	var lastTWAPID int64
+	bz := store.Get(lastTWAPIDKey)
+	if bz != nil {
+		k.cdc.MustUnmarshalBinaryBare(bz, &lastTWAPID)
+	}
+	nextTWAPID = lastTWAPID + 1
+	store.Set(lastTWAPIDKey, k.cdc.MustMarshalBinaryBare(nextTWAPID))
+	return nextTWAPID
+}
+
+// Gets the active TWAPOrders making slices in either direction of a pair
+func (k Keeper) GetActiveTWAPOrders(ctx sdk.Context, pair DenomPair) (twaps []TWAPOrder) {
+	store := ctx.KVStore(k.storeKey)
+	iterator := store.Iterator(twapOrdersPrefix, sdk.PrefixEndBytes(twapOrdersPrefix))
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var twap TWAPOrder
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &twap)
+		if twap.Status != TWAPStatusActive {
+			continue
+		}
+		if twap.Pair() == pair || twap.Pair() == pair.ReversePair() {
+			twaps = append(twaps, twap)
+		}
+	}
+	return twaps
+}
+
+// Returns an iterator over all TWAPOrders due for a slice at or before height
+func (k Keeper) TWAPQueueIterator(ctx sdk.Context, height int64) sdk.Iterator {
+	store := ctx.KVStore(k.storeKey)
+	return store.Iterator(twapQueuePrefix, AppendKeyComponents(twapQueuePrefix, Int64ToSortableBytes(height+1)))
+}
+
+// Escrows the coins of a new TWAPOrder and schedules its first slice for the end of the current block
+func (k Keeper) AddNewTWAPOrder(ctx sdk.Context, twap TWAPOrder) (TWAPOrder, sdk.Error) {
+	pair := twap.Pair()
+	if !k.IsMarketActive(ctx, pair) {
+		return twap, ErrMarketNotActive(k.codespace, pair)
+	}
+
+	_, _, err := k.coinKeeper.SubtractCoins(ctx, twap.Owner, sdk.Coins{twap.SellCoins})
+	if err != nil {
+		return twap, err
+	}
+
+	twap.TWAPID = k.GetNextTWAPID(ctx)
+	twap.RemainingCoins = twap.SellCoins
+	twap.FilledCoins = sdk.NewCoin(twap.SellCoins.Denom, sdk.ZeroInt())
+	twap.ReceivedCoins = sdk.NewCoin(twap.Price.NumeratorDenom, sdk.ZeroInt())
+	twap.NextHeight = ctx.BlockHeight()
+	twap.Status = TWAPStatusActive
+
+	k.SetTWAPOrder(ctx, twap)
+	ctx.KVStore(k.storeKey).Set(TWAPQueueKey(twap.NextHeight, twap.TWAPID), k.cdc.MustMarshalBinaryBare(twap.TWAPID))
+	return twap, nil
+}
+
+// Cancels an active TWAPOrder and refunds whatever is still escrowed
+func (k Keeper) CancelTWAPOrder(ctx sdk.Context, twapID int64) (TWAPOrder, sdk.Error) {
+	twap, found := k.GetTWAPOrder(ctx, twapID)
+	if !found {
+		return twap, ErrTWAPOrderNotFound(k.codespace, twapID)
+	}
+	if twap.Status != TWAPStatusActive {
+		return twap, ErrTWAPOrderNotActive(k.codespace, twapID)
+	}
+
+	ctx.KVStore(k.storeKey).Delete(TWAPQueueKey(twap.NextHeight, twap.TWAPID))
+	k.coinKeeper.AddCoins(ctx, twap.Owner, sdk.Coins{twap.RemainingCoins})
+
+	twap.RemainingCoins = twap.RemainingCoins.Minus(twap.RemainingCoins)
+	twap.Status = TWAPStatusCancelled
+	k.SetTWAPOrder(ctx, twap)
+	return twap, nil
+}
+
+// Submits the next slice of a TWAPOrder as a child order through AddNewOrder.
+// The child order is immediate-or-cancel: the unfilled part is pulled back out of the orderwall into the escrow.
+// Returns the error of AddNewOrder if the slice couldn't be made, with the TWAPOrder unchanged
+func (k Keeper) executeTWAPSlice(ctx sdk.Context, twap TWAPOrder) (TWAPOrder, sdk.Error) {
+	sliceAmount := twap.SliceAmount
+	if twap.RemainingCoins.Amount.LT(sliceAmount) {
+		sliceAmount = twap.RemainingCoins.Amount
+	}
+	slice := sdk.NewCoin(twap.SellCoins.Denom, sliceAmount)
+
+	// market slices ask for the least they can, so they take whatever the opposing orderwall gives
+	price := twap.Price
+	if twap.Market {
+		price.Ratio = sdk.NewDecWithPrec(1, sdk.Precision)
+	}
+
+	child := Order{
+		OrderID:       k.GetNextOrderID(ctx),
+		Owner:         twap.Owner,
+		SellCoins:     slice,
+		BuyDenom:      price.NumeratorDenom,
+		Price:         price,
+		DisplayAmount: sdk.ZeroInt(),
+		HiddenCoins:   sdk.NewCoin(slice.Denom, sdk.ZeroInt()),
+	}
+
+	child, consumed, err := k.AddNewOrder(ctx, child)
+	if err != nil {
+		return twap, err
+	}
+	if !consumed {
+		k.RemoveOrder(ctx, child.OrderID)
+	}
+	// a child cancelled for still crossing after MaxFills had its unfilled coins refunded to the owner,
+	// but they're still part of RemainingCoins, so they go back to the escrow
+	if child.Status == OrderStatusCancelled {
+		unfilled := slice.Minus(child.FilledCoins)
+		if unfilled.IsPositive() {
+			// the owner was just refunded these coins, so it can't be short of them
+			_, _, err := k.coinKeeper.SubtractCoins(ctx, twap.Owner, sdk.Coins{unfilled})
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	twap.RemainingCoins = twap.RemainingCoins.Minus(child.FilledCoins)
+	twap.FilledCoins = twap.FilledCoins.Plus(child.FilledCoins)
+	twap.ReceivedCoins = twap.ReceivedCoins.Plus(child.ReceivedCoins)
+	return twap, nil
+}
+
+// Executes the slices of all TWAPOrders that are due at the current height and reschedules them
+func (k Keeper) ProcessTWAPQueue(ctx sdk.Context) (resTags sdk.Tags) {
+	store := ctx.KVStore(k.storeKey)
+	resTags = sdk.NewTags()
+
+	var dueKeys [][]byte
+	var dueIDs []int64
+	iterator := k.TWAPQueueIterator(ctx, ctx.BlockHeight())
+	for ; iterator.Valid(); iterator.Next() {
+		var twapID int64
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &twapID)
+		dueKeys = append(dueKeys, iterator.Key())
+		dueIDs = append(dueIDs, twapID)
+	}
+	iterator.Close()
+
+	for i, twapID := range dueIDs {
+		store.Delete(dueKeys[i])
+
+		twap, found := k.GetTWAPOrder(ctx, twapID)
+		if !found || twap.Status != TWAPStatusActive {
+			continue
+		}
+
+		lastTradeID := k.GetLastTradeID(ctx)
+		twap, err := k.executeTWAPSlice(ctx, twap)
+		for tradeID := lastTradeID + 1; tradeID <= k.GetLastTradeID(ctx); tradeID++ {
+			trade, _ := k.GetTrade(ctx, twap.Pair(), tradeID)
+			resTags = resTags.AppendTag(TagTradeID, idTagValue(tradeID))
+			resTags = resTags.AppendTag(TagTrade, k.cdc.MustMarshalJSON(trade))
+		}
+
+		// a slice that couldn't be made is tried again at the next interval, unless its price is out of range,
+		// which it stays until the params change, so the TWAPOrder is cancelled and refunded instead
+		if err != nil {
+			resTags = resTags.AppendTag(TagFailedTWAPID, idTagValue(twap.TWAPID))
+			if err.Codespace() == k.codespace && err.Code() == CodeInvalidPriceRange {
+				twap, err = k.CancelTWAPOrder(ctx, twap.TWAPID)
+				if err != nil {
+					panic(err)
+				}
+				resTags = resTags.AppendTag(TagCancelledTWAPID, idTagValue(twap.TWAPID))
+				resTags = resTags.AppendTags(MarketTag(twap.Pair()))
+				continue
+			}
+		}
+
+		// the TWAPOrder is done once everything escrowed has been sold
+		if !twap.RemainingCoins.IsPositive() {
+			twap.Status = TWAPStatusDone
+		} else {
+			twap.NextHeight = ctx.BlockHeight() + twap.Interval
+			store.Set(TWAPQueueKey(twap.NextHeight, twap.TWAPID), k.cdc.MustMarshalBinaryBare(twap.TWAPID))
+		}
+		k.SetTWAPOrder(ctx, twap)
+
+		resTags = resTags.AppendTag(TagTWAPID, idTagValue(twap.TWAPID))
+		resTags = resTags.AppendTags(MarketTag(twap.Pair()))
+	}
+
+	return resTags
+}
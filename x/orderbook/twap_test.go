@@ -0,0 +1,128 @@
+package orderbook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+)
+
+// returns the coins of alice and bob, the coins escrowed in resting orders and active TWAPOrders, and the collected fees
+func totalSupply(ctx sdk.Context, keeper Keeper) (total sdk.Coins) {
+	for _, addr := range []sdk.AccAddress{alice, bob} {
+		total = total.Plus(keeper.coinKeeper.GetCoins(ctx, addr))
+	}
+	keeper.IterateOrders(ctx, func(order Order) bool {
+		total = total.Plus(sdk.Coins{order.TotalSellCoins()})
+		return false
+	})
+	for twapID := int64(1); ; twapID++ {
+		twap, found := keeper.GetTWAPOrder(ctx, twapID)
+		if !found {
+			break
+		}
+		if twap.RemainingCoins.IsPositive() {
+			total = total.Plus(sdk.Coins{twap.RemainingCoins})
+		}
+	}
+	return total.Plus(keeper.feeCollectionKeeper.(auth.FeeCollectionKeeper).GetCollectedFees(ctx))
+}
+
+// builds a market TWAPOrder of alice selling sellAmount ETH for BTC in slices of sliceAmount every block
+func aliceMarketTWAP(sellAmount int64, sliceAmount int64) TWAPOrder {
+	return TWAPOrder{
+		Owner:       alice,
+		SellCoins:   sdk.NewInt64Coin("ETH", sellAmount),
+		Price:       NewPrice(sdk.OneDec(), "BTC", "ETH"),
+		Market:      true,
+		SliceAmount: sdk.NewInt(sliceAmount),
+		Interval:    1,
+	}
+}
+
+func TestTWAPSliceCancelledAfterMaxFills(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	handler := NewHandler(keeper)
+	setMaxFills(ctx, keeper, 1)
+
+	for i := 0; i < 2; i++ {
+		res := handler(ctx, makeOrderMsg(bob, 10, "BTC", "2", "ETH", STPNone))
+		require.True(t, res.IsOK(), res.Log)
+	}
+	twap, err := keeper.AddNewTWAPOrder(ctx, aliceMarketTWAP(100, 100))
+	require.Nil(t, err)
+	supply := totalSupply(ctx, keeper)
+
+	// the slice takes bob's first order, then is cancelled as it still crosses the second one
+	tags := keeper.ProcessTWAPQueue(ctx)
+	require.True(t, supply.IsEqual(totalSupply(ctx, keeper)), "%s != %s", supply, totalSupply(ctx, keeper))
+
+	// the trade is in the tags, as the EndBlock results have no data
+	var trades []Trade
+	for _, tag := range tags {
+		if string(tag.Key) == TagTrade {
+			var trade Trade
+			keeper.cdc.MustUnmarshalJSON(tag.Value, &trade)
+			trades = append(trades, trade)
+		}
+	}
+	require.Len(t, trades, 1)
+	require.Equal(t, twap.Owner, trades[0].Taker)
+	require.Equal(t, int64(10), trades[0].MakerSold.Amount.Int64())
+
+	twap, found := keeper.GetTWAPOrder(ctx, twap.TWAPID)
+	require.True(t, found)
+	require.Equal(t, TWAPStatusActive, twap.Status)
+	require.Equal(t, int64(20), twap.FilledCoins.Amount.Int64())
+	require.Equal(t, int64(80), twap.RemainingCoins.Amount.Int64())
+	require.Equal(t, int64(900), keeper.coinKeeper.GetCoins(ctx, alice).AmountOf("ETH").Int64())
+}
+
+// returns whether tags have a tag with a key and an ID as its value
+func hasIDTag(tags sdk.Tags, key string, id int64) bool {
+	for _, tag := range tags {
+		if string(tag.Key) == key && string(tag.Value) == string(idTagValue(id)) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestTWAPSliceErrors(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	pair := NewDenomPair("ETH", "BTC")
+
+	twapOrder := aliceMarketTWAP(100, 10)
+	twapOrder.Market = false
+	twapOrder.Price = NewPrice(sdk.NewDec(1000), "BTC", "ETH")
+	twap, err := keeper.AddNewTWAPOrder(ctx, twapOrder)
+	require.Nil(t, err)
+
+	// the slice of a halted market fails, and is tried again at the next interval
+	require.Nil(t, keeper.HaltMarket(ctx, pair))
+	tags := keeper.ProcessTWAPQueue(ctx)
+	require.True(t, hasIDTag(tags, TagFailedTWAPID, twap.TWAPID))
+	twap, _ = keeper.GetTWAPOrder(ctx, twap.TWAPID)
+	require.Equal(t, TWAPStatusActive, twap.Status)
+	require.Equal(t, int64(100), twap.RemainingCoins.Amount.Int64())
+	require.Equal(t, ctx.BlockHeight()+1, twap.NextHeight)
+
+	// a price that's out of range doesn't come back in range, so the TWAPOrder is cancelled and refunded
+	keeper.ListMarket(ctx, NewMarket("BTC", "ETH"))
+	params := keeper.GetParams(ctx)
+	params.MaxPrice = sdk.NewDec(100)
+	keeper.SetParams(ctx, params)
+	ctx = ctx.WithBlockHeight(twap.NextHeight)
+	tags = keeper.ProcessTWAPQueue(ctx)
+	require.True(t, hasIDTag(tags, TagFailedTWAPID, twap.TWAPID))
+	require.True(t, hasIDTag(tags, TagCancelledTWAPID, twap.TWAPID))
+	twap, _ = keeper.GetTWAPOrder(ctx, twap.TWAPID)
+	require.Equal(t, TWAPStatusCancelled, twap.Status)
+	require.Equal(t, int64(1000), keeper.coinKeeper.GetCoins(ctx, alice).AmountOf("ETH").Int64())
+
+	iterator := keeper.TWAPQueueIterator(ctx, ctx.BlockHeight()+1000)
+	require.False(t, iterator.Valid())
+	iterator.Close()
+}
@@ -91,14 +91,47 @@ func MulCoinsPrice(coins sdk.Coin, price Price) (sdk.Coin, error) {
 
 // ------------------------------------------------------------
 
+// Statuses of an Order
+const (
+	OrderStatusOpen            = "open"
+	OrderStatusPartiallyFilled = "partially_filled"
+	OrderStatusFilled          = "filled"
+	OrderStatusCancelled       = "cancelled"
+	OrderStatusExpired         = "expired"
+)
+
 // Order
 type Order struct {
 	OrderID        int64
+	ClientOrderID  string
 	Owner          sdk.AccAddress
 	SellCoins      sdk.Coin
 	BuyDenom       string
 	Price          Price
 	ExpirationTime time.Time
+
+	// Iceberg orders only show DisplayAmount of their size in the orderwall at a time.
+	// The rest of the order is kept in HiddenCoins until the visible SellCoins are consumed
+	DisplayAmount sdk.Int
+	HiddenCoins   sdk.Coin
+
+	// Position of the order within its price level in the orderwall
+	WallSequence int64
+
+	// What to do when the order would match against a resting order of the same owner
+	SelfTradePrevention SelfTradePrevention
+
+	// Coins the order was made with, coins sold by the order so far and coins received for them.
+	// AveragePrice is the ratio of ReceivedCoins to FilledCoins, in units of BuyDenom/SellDenom
+	OriginalCoins sdk.Coin
+	FilledCoins   sdk.Coin
+	ReceivedCoins sdk.Coin
+	AveragePrice  sdk.Dec
+
+	Status        string
+	CreatedHeight int64
+	CreatedTime   time.Time
+	ClosedHeight  int64
 }
 
 // Returns the DenomPair of (BuyDenom, SellDenom).  Used for assigning order to the proper orderbook
@@ -109,6 +142,131 @@ func (o Order) Pair() DenomPair {
 	}
 }
 
+// Returns whether the order only displays part of its size in the orderwall
+func (o Order) IsIceberg() bool {
+	return o.DisplayAmount != (sdk.Int{}) && o.DisplayAmount.Sign() == 1
+}
+
+// Returns all the coins left in the order, including the hidden part of an iceberg order
+func (o Order) TotalSellCoins() sdk.Coin {
+	if !o.IsIceberg() {
+		return o.SellCoins
+	}
+	return o.SellCoins.Plus(o.HiddenCoins)
+}
+
+// Splits an iceberg order so that only DisplayAmount is left in SellCoins and the rest is hidden
+func (o Order) HideIcebergReserve() Order {
+	if !o.IsIceberg() {
+		return o
+	}
+	total := o.TotalSellCoins()
+	visible := total.Amount
+	if o.DisplayAmount.LT(visible) {
+		visible = o.DisplayAmount
+	}
+	o.SellCoins = sdk.NewCoin(total.Denom, visible)
+	o.HiddenCoins = sdk.NewCoin(total.Denom, total.Amount.Sub(visible))
+	return o
+}
+
+// Moves the next slice of an iceberg order's hidden coins into its SellCoins.
+// Returns false if there is nothing left to refill with
+func (o Order) RefillIceberg() (refilled Order, ok bool) {
+	if !o.IsIceberg() || !o.HiddenCoins.IsPositive() {
+		return o, false
+	}
+	return o.HideIcebergReserve(), true
+}
+
+// Adds a fill of the order, selling sold in exchange for received
+func (o Order) recordFill(sold sdk.Coin, received sdk.Coin) Order {
+	o.FilledCoins = o.FilledCoins.Plus(sold)
+	o.ReceivedCoins = o.ReceivedCoins.Plus(received)
+	if o.FilledCoins.IsPositive() {
+		o.AveragePrice = sdk.NewDecFromInt(o.ReceivedCoins.Amount).Quo(sdk.NewDecFromInt(o.FilledCoins.Amount))
+	}
+	o.Status = OrderStatusPartiallyFilled
+	return o
+}
+
+// Returns whether the order is no longer in the orderwall
+func (o Order) IsClosed() bool {
+	return o.Status == OrderStatusFilled || o.Status == OrderStatusCancelled || o.Status == OrderStatusExpired
+}
+
+// Returns the order as it should be shown to others, without the hidden part of an iceberg order
+func (o Order) Visible() Order {
+	if o.IsIceberg() {
+		o.HiddenCoins = sdk.NewCoin(o.HiddenCoins.Denom, sdk.ZeroInt())
+		o.OriginalCoins = o.FilledCoins.Plus(o.SellCoins)
+	}
+	return o
+}
+
+// MakeOrderResult is returned as the Data of a MsgMakeOrder's Result
+type MakeOrderResult struct {
+	OrderID       int64    `json:"order_id"`
+	ClientOrderID string   `json:"client_order_id"`
+	Consumed      bool     `json:"consumed"`
+	Status        string   `json:"status"`
+	FilledCoins   sdk.Coin `json:"filled_coins"`
+	ReceivedCoins sdk.Coin `json:"received_coins"`
+	// coins of the order left resting in the orderwall, including the hidden part of an iceberg order
+	RemainingCoins sdk.Coin `json:"remaining_coins"`
+	// fills of the order against the opposing orderwall
+	Trades []Trade `json:"trades"`
+}
+
+// ------------------------------------------------------------
+
+// SelfTradePrevention decides what happens when an incoming order would match a resting order of the same owner
+type SelfTradePrevention byte
+
+// Self-trade prevention modes
+const (
+	// let the orders match as if they had different owners
+	STPNone SelfTradePrevention = iota
+	// cancel what's left of the incoming order
+	STPCancelNewest
+	// cancel the resting order and keep matching the incoming order
+	STPCancelOldest
+	// cancel both the incoming and the resting order
+	STPCancelBoth
+	// decrease both orders by the size of the smaller one, which gets cancelled
+	STPDecrementAndCancel
+)
+
+var selfTradePreventionStrings = map[SelfTradePrevention]string{
+	STPNone:               "none",
+	STPCancelNewest:       "cancel-newest",
+	STPCancelOldest:       "cancel-oldest",
+	STPCancelBoth:         "cancel-both",
+	STPDecrementAndCancel: "decrement-and-cancel",
+}
+
+// Returns a SelfTradePrevention mode from its string representation
+func SelfTradePreventionFromStr(str string) (SelfTradePrevention, error) {
+	for stp, stpStr := range selfTradePreventionStrings {
+		if stpStr == str {
+			return stp, nil
+		}
+	}
+	return STPNone, fmt.Errorf("Unknown self-trade prevention mode %s", str)
+}
+
+// Returns whether stp is one of the known self-trade prevention modes
+func (stp SelfTradePrevention) IsValid() bool {
+	_, ok := selfTradePreventionStrings[stp]
+	return ok
+}
+
+func (stp SelfTradePrevention) String() string {
+	return selfTradePreventionStrings[stp]
+}
+
+// ------------------------------------------------------------
+
 // DenomPair is a tuple of two denoms
 type DenomPair struct {
 	SellDenom string
@@ -76,3 +76,37 @@ func TestMulCoinsPrice(t *testing.T) {
 		})
 	}
 }
+
+func TestOrder_HideIcebergReserve(t *testing.T) {
+	tests := []struct {
+		name          string
+		sellAmount    int64
+		hiddenAmount  int64
+		displayAmount int64
+		wantSell      int64
+		wantHidden    int64
+	}{
+		{"not iceberg", 100, 0, 0, 100, 0},
+		{"split", 100, 0, 30, 30, 70},
+		{"refill", 0, 70, 30, 30, 40},
+		{"last slice", 0, 10, 30, 10, 0},
+		{"display larger than order", 20, 0, 30, 20, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := Order{
+				SellCoins:     sdk.NewInt64Coin("BTC", tt.sellAmount),
+				HiddenCoins:   sdk.NewInt64Coin("BTC", tt.hiddenAmount),
+				DisplayAmount: sdk.NewInt(tt.displayAmount),
+			}
+
+			got := o.HideIcebergReserve()
+			if !got.SellCoins.IsEqual(sdk.NewInt64Coin("BTC", tt.wantSell)) {
+				t.Errorf("Order.HideIcebergReserve() SellCoins = %v, want %v", got.SellCoins, tt.wantSell)
+			}
+			if !got.HiddenCoins.IsEqual(sdk.NewInt64Coin("BTC", tt.wantHidden)) {
+				t.Errorf("Order.HideIcebergReserve() HiddenCoins = %v, want %v", got.HiddenCoins, tt.wantHidden)
+			}
+		})
+	}
+}
@@ -4,22 +4,12 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"math/big"
+	"strings"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
-var keySeperator = []byte("/")
-
-// joins two byte slices using the keySeperator as a delimter
-func AppendWithSeperator(byteslice1, byteslice2 []byte) []byte {
-	return append(append(byteslice1, keySeperator...), byteslice2...)
-}
-
-// Splits a key path using the delimter keySeperator
-func SplitKeyAlongSeperator(fullKey []byte) [][]byte {
-	return bytes.Split(fullKey, keySeperator)
-}
-
 // marshals int64 to a bigendian byte slice so it can be sorted
 func Int64ToSortableBytes(i int64) []byte {
 	b := make([]byte, 8)
@@ -27,6 +17,11 @@ func Int64ToSortableBytes(i int64) []byte {
 	return b
 }
 
+// unmarshals an int64 from the bigendian byte slice made by Int64ToSortableBytes
+func SortableBytesToInt64(b []byte) int64 {
+	return int64(binary.BigEndian.Uint64(b))
+}
+
 // returns the reciprocal of an sdk.Dec
 func SDKDecReciprocal(dec sdk.Dec) sdk.Dec {
 	return sdk.OneDec().Quo(dec)
@@ -40,8 +35,12 @@ func ValidSortableDec(dec sdk.Dec) bool {
 	return dec.LTE(maxDec)
 }
 
+// Shortest length of the byte slices made by SortableSDKDecBytes
+const sortableDecMinLength = 20
+
 // Returns a byte slice representation of an sdk.Dec that can be sorted.
-// Left and right pads with 0s so there are 10 digits to left and right of decimal point
+// Left pads with 0s to at least 20 characters, so decimals of the same length sort by their value.
+// The largest decimals are longer than that, so the slices sort by their length before their bytes, as key components do.
 // For this reason, there is a maximum and minimum value for this
 // Prices need to be marshalled using this, and so prices must be within the bounds
 // enforced by ValidSortableDec
@@ -51,3 +50,51 @@ func SortableSDKDecBytes(dec sdk.Dec) []byte {
 	}
 	return []byte(fmt.Sprintf("%020s", dec))
 }
+
+// Parses the byte slice made by SortableSDKDecBytes back into an sdk.Dec
+func ParseSortableSDKDecBytes(bz []byte) (sdk.Dec, error) {
+	if len(bz) < sortableDecMinLength {
+		return sdk.Dec{}, fmt.Errorf("sortable dec has %d bytes instead of at least %d", len(bz), sortableDecMinLength)
+	}
+	digits := strings.Replace(string(bz), ".", "", 1)
+	if strings.Trim(digits, "0123456789") != "" {
+		return sdk.Dec{}, fmt.Errorf("sortable dec %q isn't a decimal", bz)
+	}
+	// there are always sdk.Precision digits after the decimal point
+	i, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return sdk.Dec{}, fmt.Errorf("sortable dec %q isn't a decimal", bz)
+	}
+	dec := sdk.NewDecFromBigIntWithPrec(i, sdk.Precision)
+	// every sortable dec has a single byte slice, so that its key does too
+	if !ValidSortableDec(dec) || !bytes.Equal(SortableSDKDecBytes(dec), bz) {
+		return sdk.Dec{}, fmt.Errorf("sortable dec %q isn't written the way SortableSDKDecBytes writes it", bz)
+	}
+	return dec, nil
+}
+
+// MaxClientOrderIDLength is the longest client order ID an order can be given
+const MaxClientOrderIDLength = 64
+
+// Ensures that a client order ID is short and only uses characters that are safe in query paths
+func ValidClientOrderID(clientOrderID string) bool {
+	if len(clientOrderID) == 0 || len(clientOrderID) > MaxClientOrderIDLength {
+		return false
+	}
+	for _, c := range clientOrderID {
+		isAlphaNumeric := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+		if !isAlphaNumeric && c != '-' && c != '_' && c != '.' && c != ':' {
+			return false
+		}
+	}
+	return true
+}
+
+// Ensures that a denom is one coins can be made of, and short enough to be a key component
+func ValidDenom(denom string) bool {
+	if len(denom) == 0 || len(denom) > MaxKeyComponentLength {
+		return false
+	}
+	_, err := sdk.ParseCoin("1" + denom)
+	return err == nil
+}
@@ -2,6 +2,7 @@ package orderbook
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -35,83 +36,97 @@ func TestValidSortableDec(t *testing.T) {
 	}
 }
 
-func TestAppendWithSeperator(t *testing.T) {
-	type args struct {
-		byteslice1 []byte
-		byteslice2 []byte
-	}
+func TestSDKDecReciprocal(t *testing.T) {
 	tests := []struct {
-		name string
-		args args
-		want []byte
+		name       string
+		decString  string
+		wantString string
 	}{
-		{"nonempty w/ nonempty", args{[]byte("asdf"), []byte("jkl;")}, []byte("asdf/jkl;")},
-		{"nonempty w/ empty", args{[]byte("asdf"), []byte("")}, []byte("asdf/")},
-		{"empty w/ nonempty", args{[]byte(""), []byte("jkl;")}, []byte("/jkl;")},
-		{"empty w/ empty", args{[]byte(""), []byte("")}, []byte("/")},
+		{"power ten", "0.00001", "100000"},
+		{"common fractions", "0.25", "4"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := AppendWithSeperator(tt.args.byteslice1, tt.args.byteslice2); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("AppendWithSeperator() = %v, want %v", got, tt.want)
+			dec, _ := sdk.NewDecFromStr(tt.decString)
+			want, _ := sdk.NewDecFromStr(tt.wantString)
+
+			if got := SDKDecReciprocal(dec); !reflect.DeepEqual(got, want) {
+				t.Errorf("SDKDecReciprocal() = %v, want %v", got, want)
 			}
 		})
 	}
 }
 
-func TestSplitKeyAlongSeperator(t *testing.T) {
+func TestSortableSDKDecBytes(t *testing.T) {
 	tests := []struct {
-		name    string
-		fullKey []byte
-		want    [][]byte
+		name string
+		dec  sdk.Dec
+		want []byte
 	}{
-		{"2 layer", []byte("asdf/jkl;"), [][]byte{[]byte("asdf"), []byte("jkl;")}},
-		{"3 layer", []byte("asdf/jkl;/qwerty"), [][]byte{[]byte("asdf"), []byte("jkl;"), []byte("qwerty")}},
-		{"empty sections", []byte("asdf//qwerty"), [][]byte{[]byte("asdf"), []byte(""), []byte("qwerty")}},
+		{"one", sdk.OneDec(), []byte("00000000010000000000")},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := SplitKeyAlongSeperator(tt.fullKey); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("SplitKeyAlongSeperator() = %v, want %v", got, tt.want)
+			if got := SortableSDKDecBytes(tt.dec); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SortableSDKDecBytes() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
-func TestSDKDecReciprocal(t *testing.T) {
+func TestParseSortableSDKDecBytes(t *testing.T) {
 	tests := []struct {
-		name       string
-		decString  string
-		wantString string
+		name      string
+		decString string
 	}{
-		{"power ten", "0.00001", "100000"},
-		{"common fractions", "0.25", "4"},
+		{"one", "1"},
+		{"smallest", "0.0000000001"},
+		{"largest", "10000000000"},
+		{"longer than the padding", "1234567890.5"},
+		{"fraction", "123.456"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			dec, _ := sdk.NewDecFromStr(tt.decString)
-			want, _ := sdk.NewDecFromStr(tt.wantString)
-
-			if got := SDKDecReciprocal(dec); !reflect.DeepEqual(got, want) {
-				t.Errorf("SDKDecReciprocal() = %v, want %v", got, want)
+			got, err := ParseSortableSDKDecBytes(SortableSDKDecBytes(dec))
+			if err != nil || !got.Equal(dec) {
+				t.Errorf("ParseSortableSDKDecBytes() = %v, %v, want %v", got, err, dec)
 			}
 		})
 	}
+
+	// a decimal can't be written with more padding than SortableSDKDecBytes writes it with
+	padded := append([]byte("0"), SortableSDKDecBytes(sdk.OneDec())...)
+	for _, bz := range [][]byte{[]byte("1"), []byte("0000000001000000000a"), []byte("-0000000001000000000"), padded} {
+		if _, err := ParseSortableSDKDecBytes(bz); err == nil {
+			t.Errorf("ParseSortableSDKDecBytes(%q) didn't fail", bz)
+		}
+	}
 }
 
-func TestSortableSDKDecBytes(t *testing.T) {
+func TestValidDenom(t *testing.T) {
 	tests := []struct {
-		name string
-		dec  sdk.Dec
-		want []byte
+		name  string
+		denom string
+		want  bool
 	}{
-		{"one", sdk.OneDec(), []byte("00000000010000000000")},
+		{"coin denom", "BTC", true},
+		{"empty", "", false},
+		{"separator", "BTC/ETH", false},
+		{"pair separator", "BTC|ETH", false},
+		{"longer than a key component", strings.Repeat("a", MaxKeyComponentLength+1), false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := SortableSDKDecBytes(tt.dec); !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("SortableSDKDecBytes() = %v, want %v", got, tt.want)
+			if got := ValidDenom(tt.denom); got != tt.want {
+				t.Errorf("ValidDenom(%q) = %v, want %v", tt.denom, got, tt.want)
 			}
 		})
 	}
+
+	// an order can't be made for a denom that doesn't fit in the keys of its orderwall
+	msg := makeOrderMsg(alice, 10, "ETH", "1", strings.Repeat("a", MaxKeyComponentLength+1), STPNone)
+	if msg.ValidateBasic() == nil {
+		t.Errorf("MsgMakeOrder.ValidateBasic() accepted a denom of %d bytes", len(msg.Price.NumeratorDenom))
+	}
 }
@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/sunnya97/sdk-dex-mvp/x/token"
+)
+
+// QueryDenomMetadata gets the metadata of a denom by its symbol or one of its display names.
+// Denoms without a token only have base units
+func QueryDenomMetadata(cliCtx context.CLIContext, queryRoute string, name string) (metadata token.DenomMetadata, err error) {
+	res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/metadata/%s", queryRoute, name), nil)
+	if err != nil {
+		return metadata, err
+	}
+	err = cliCtx.Codec.UnmarshalJSON(res, &metadata)
+	return metadata, err
+}
+
+// ParseDisplayCoin parses coins written either in display units, like 1.5BTC, or in base units of their denom
+func ParseDisplayCoin(cliCtx context.CLIContext, queryRoute string, coinStr string) (sdk.Coin, token.DenomMetadata, error) {
+	amountStr, name, err := token.SplitAmount(coinStr)
+	if err != nil {
+		return sdk.Coin{}, token.DenomMetadata{}, err
+	}
+	metadata, err := QueryDenomMetadata(cliCtx, queryRoute, name)
+	if err != nil {
+		return sdk.Coin{}, metadata, err
+	}
+	amount, err := metadata.ParseAmount(amountStr, name)
+	if err != nil {
+		return sdk.Coin{}, metadata, err
+	}
+	return sdk.NewCoin(metadata.Denom, amount), metadata, nil
+}
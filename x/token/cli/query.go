@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/sunnya97/sdk-dex-mvp/x/token"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/spf13/cobra"
+)
+
+// GetCmdGetToken queries a token by its symbol
+func GetCmdGetToken(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "token [symbol]",
+		Short: "get a token by its symbol",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/token/%s", queryRoute, args[0]), nil)
+			if err != nil {
+				return err
+			}
+
+			var t token.Token
+			cdc.MustUnmarshalJSON(res, &t)
+			fmt.Println(t)
+
+			return nil
+		},
+	}
+}
+
+// GetCmdGetTokens queries all tokens
+func GetCmdGetTokens(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "tokens",
+		Short: "get all tokens",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			res, err := cliCtx.QueryWithData(fmt.Sprintf("custom/%s/tokens", queryRoute), nil)
+			if err != nil {
+				return err
+			}
+
+			var tokens []token.Token
+			cdc.MustUnmarshalJSON(res, &tokens)
+			for _, t := range tokens {
+				fmt.Println(t)
+			}
+
+			return nil
+		},
+	}
+}
+
+// GetCmdGetDenomMetadata queries the metadata of a denom by its symbol or one of its display names
+func GetCmdGetDenomMetadata(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "denom [name]",
+		Short: "get the display unit and decimals of a denom, by its symbol or display name",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cliCtx := context.NewCLIContext().WithCodec(cdc)
+
+			metadata, err := QueryDenomMetadata(cliCtx, queryRoute, args[0])
+			if err != nil {
+				return err
+			}
+
+			out, err := codec.MarshalJSONIndent(cdc, metadata)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(out))
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/client/utils"
+	"github.com/cosmos/cosmos-sdk/codec"
+
+	"github.com/sunnya97/sdk-dex-mvp/x/token"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authcmd "github.com/cosmos/cosmos-sdk/x/auth/client/cli"
+	authtxb "github.com/cosmos/cosmos-sdk/x/auth/client/txbuilder"
+)
+
+const (
+	flagDecimals    = "decimals"
+	flagMintable    = "mintable"
+	flagQuoteDenoms = "quote-denoms"
+	flagDisplayName = "display-name"
+	flagAliases     = "aliases"
+)
+
+// splits a comma separated flag into its values
+func splitFlag(flag string) (values []string) {
+	if str := viper.GetString(flag); str != "" {
+		values = strings.Split(str, ",")
+	}
+	return values
+}
+
+// signs and broadcasts a token msg from the --from account
+func broadcastMsg(cdc *codec.Codec, buildMsg func(from sdk.AccAddress) (sdk.Msg, error)) error {
+	cliCtx := context.NewCLIContext().
+		WithCodec(cdc).
+		WithAccountDecoder(authcmd.GetAccountDecoder(cdc))
+
+	txBldr := authtxb.NewTxBuilderFromCLI().WithCodec(cdc)
+
+	if err := cliCtx.EnsureAccountExists(); err != nil {
+		return err
+	}
+
+	account, err := cliCtx.GetFromAddress()
+	if err != nil {
+		return err
+	}
+
+	msg, err := buildMsg(account)
+	if err != nil {
+		return err
+	}
+	err = msg.ValidateBasic()
+	if err != nil {
+		return err
+	}
+
+	cliCtx.PrintResponse = true
+
+	return utils.CompleteAndBroadcastTxCli(txBldr, cliCtx, []sdk.Msg{msg})
+}
+
+// GetCmdIssueToken is the CLI command for sending an IssueToken transaction
+func GetCmdIssueToken(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "issue-token [symbol] [total-supply]",
+		Short: "issue a new token, whose total supply goes to you, in base units",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return broadcastMsg(cdc, func(from sdk.AccAddress) (sdk.Msg, error) {
+				totalSupply, ok := sdk.NewIntFromString(args[1])
+				if !ok {
+					return nil, token.ErrInvalidToken(token.DefaultCodespace, "invalid total supply "+args[1])
+				}
+
+				msg := token.NewMsgIssueToken(from, args[0], totalSupply, viper.GetInt64(flagDecimals), viper.GetBool(flagMintable), splitFlag(flagQuoteDenoms))
+				msg.DisplayName = viper.GetString(flagDisplayName)
+				msg.Aliases = splitFlag(flagAliases)
+				return msg, nil
+			})
+		},
+	}
+
+	cmd.Flags().Int64(flagDecimals, 0, "Number of decimals the token's display unit has")
+	cmd.Flags().Bool(flagMintable, false, "Whether you can mint more of the token later")
+	cmd.Flags().String(flagQuoteDenoms, "", "Comma separated denoms to list markets for the token against")
+	cmd.Flags().String(flagDisplayName, "", "Name of the display unit, 10^decimals base units")
+	cmd.Flags().String(flagAliases, "", "Comma separated other names of the display unit")
+
+	return cmd
+}
+
+// GetCmdMint is the CLI command for sending a Mint transaction
+func GetCmdMint(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "mint [coins]",
+		Short: "mint more coins of a token you own",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return broadcastMsg(cdc, func(from sdk.AccAddress) (sdk.Msg, error) {
+				coins, err := sdk.ParseCoin(args[0])
+				if err != nil {
+					return nil, err
+				}
+				return token.NewMsgMint(from, coins), nil
+			})
+		},
+	}
+}
+
+// GetCmdBurn is the CLI command for sending a Burn transaction
+func GetCmdBurn(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "burn [coins]",
+		Short: "destroy coins of a token you hold",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return broadcastMsg(cdc, func(from sdk.AccAddress) (sdk.Msg, error) {
+				coins, err := sdk.ParseCoin(args[0])
+				if err != nil {
+					return nil, err
+				}
+				return token.NewMsgBurn(from, coins), nil
+			})
+		},
+	}
+}
+
+// GetCmdTransferOwnership is the CLI command for sending a TransferOwnership transaction
+func GetCmdTransferOwnership(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "transfer-token-ownership [symbol] [new-owner]",
+		Short: "make another account the owner of a token you own",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return broadcastMsg(cdc, func(from sdk.AccAddress) (sdk.Msg, error) {
+				newOwner, err := sdk.AccAddressFromBech32(args[1])
+				if err != nil {
+					return nil, err
+				}
+				return token.NewMsgTransferOwnership(from, args[0], newOwner), nil
+			})
+		},
+	}
+}
+
+// GetCmdSetDisplayNames is the CLI command for sending a SetDisplayNames transaction
+func GetCmdSetDisplayNames(cdc *codec.Codec) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set-display-names [symbol] [display-name]",
+		Short: "rename the display unit of a token you own",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return broadcastMsg(cdc, func(from sdk.AccAddress) (sdk.Msg, error) {
+				return token.NewMsgSetDisplayNames(from, args[0], args[1], splitFlag(flagAliases)), nil
+			})
+		},
+	}
+
+	cmd.Flags().String(flagAliases, "", "Comma separated other names of the display unit")
+
+	return cmd
+}
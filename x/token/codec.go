@@ -0,0 +1,14 @@
+package token
+
+import (
+	"github.com/cosmos/cosmos-sdk/codec"
+)
+
+// Register concrete types on wire codec
+func RegisterCodec(cdc *codec.Codec) {
+	cdc.RegisterConcrete(MsgIssueToken{}, "token/IssueToken", nil)
+	cdc.RegisterConcrete(MsgMint{}, "token/Mint", nil)
+	cdc.RegisterConcrete(MsgBurn{}, "token/Burn", nil)
+	cdc.RegisterConcrete(MsgTransferOwnership{}, "token/TransferOwnership", nil)
+	cdc.RegisterConcrete(MsgSetDisplayNames{}, "token/SetDisplayNames", nil)
+}
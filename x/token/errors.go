@@ -0,0 +1,46 @@
+// nolint
+package token
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	DefaultCodespace sdk.CodespaceType = 432
+
+	CodeInvalidToken  sdk.CodeType = 1
+	CodeTokenExists   sdk.CodeType = 2
+	CodeTokenNotFound sdk.CodeType = 3
+	CodeNotOwner      sdk.CodeType = 4
+	CodeNotMintable   sdk.CodeType = 5
+)
+
+//----------------------------------------
+// Error constructors
+
+// Error for when a token's symbol, supply or decimals are malformed
+func ErrInvalidToken(codespace sdk.CodespaceType, reason string) sdk.Error {
+	return sdk.NewError(codespace, CodeInvalidToken, fmt.Sprintf("Invalid token: %s", reason))
+}
+
+// Error for when a token is issued with the symbol of an existing denom
+func ErrTokenExists(codespace sdk.CodespaceType, symbol string) sdk.Error {
+	return sdk.NewError(codespace, CodeTokenExists, fmt.Sprintf("A token with symbol %s already exists", symbol))
+}
+
+// Error for when there is no token with a symbol
+func ErrTokenNotFound(codespace sdk.CodespaceType, symbol string) sdk.Error {
+	return sdk.NewError(codespace, CodeTokenNotFound, fmt.Sprintf("Could not find a token with symbol %s", symbol))
+}
+
+// Error for when someone other than a token's owner tries to mint it or transfer its ownership
+func ErrNotOwner(codespace sdk.CodespaceType, symbol string) sdk.Error {
+	return sdk.NewError(codespace, CodeNotOwner, fmt.Sprintf("Only the owner of token %s can do this", symbol))
+}
+
+// Error for when a token that was issued with a fixed supply is minted
+func ErrNotMintable(codespace sdk.CodespaceType, symbol string) sdk.Error {
+	return sdk.NewError(codespace, CodeNotMintable, fmt.Sprintf("Token %s has a fixed supply", symbol))
+}
@@ -0,0 +1,66 @@
+package token
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// GenesisState is the token state a chain starts with
+type GenesisState struct {
+	Tokens []Token `json:"tokens"`
+}
+
+func NewGenesisState(tokens []Token) GenesisState {
+	return GenesisState{
+		Tokens: tokens,
+	}
+}
+
+// Returns the token state of a new chain, where every denom is one held by a genesis account
+func DefaultGenesisState() GenesisState {
+	return NewGenesisState(nil)
+}
+
+// Checks that the token genesis state can be used to start a chain
+func ValidateGenesis(data GenesisState) error {
+	// symbols and display names all name a single denom
+	names := make(map[string]string)
+	for _, token := range data.Tokens {
+		err := token.Validate()
+		if err != nil {
+			return err
+		}
+		if _, found := names[token.Symbol]; found {
+			return fmt.Errorf("symbol of token %s is already used", token.Symbol)
+		}
+		names[token.Symbol] = token.Symbol
+	}
+	for _, token := range data.Tokens {
+		for _, name := range token.DisplayNames() {
+			if symbol, found := names[name]; found && symbol != token.Symbol {
+				return fmt.Errorf("display name %s of token %s already names %s", name, token.Symbol, symbol)
+			}
+			names[name] = token.Symbol
+		}
+	}
+	return nil
+}
+
+// Sets the token state from genesis. Every denom held by the genesis accounts that isn't one of the
+// genesis tokens becomes a token without an owner, so it can't be issued again
+func InitGenesis(ctx sdk.Context, keeper Keeper, data GenesisState, genesisCoins sdk.Coins) {
+	for _, token := range data.Tokens {
+		keeper.setTokenWithDisplayNames(ctx, token)
+	}
+	for _, coin := range genesisCoins {
+		if _, found := keeper.GetDenomMetadata(ctx, coin.Denom); !found {
+			keeper.SetToken(ctx, NewToken(coin.Denom, nil, coin.Amount, 0, false))
+		}
+	}
+}
+
+// Returns the token state to export as genesis
+func ExportGenesis(ctx sdk.Context, keeper Keeper) GenesisState {
+	return NewGenesisState(keeper.GetTokens(ctx))
+}
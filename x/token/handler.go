@@ -0,0 +1,84 @@
+package token
+
+import (
+	"fmt"
+	"reflect"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// NewHandler returns a handler for "token" type messages.
+func NewHandler(keeper Keeper) sdk.Handler {
+	return func(ctx sdk.Context, msg sdk.Msg) sdk.Result {
+		switch msg := msg.(type) {
+		case MsgIssueToken:
+			return handleMsgIssueToken(ctx, keeper, msg)
+		case MsgMint:
+			return handleMsgMint(ctx, keeper, msg)
+		case MsgBurn:
+			return handleMsgBurn(ctx, keeper, msg)
+		case MsgTransferOwnership:
+			return handleMsgTransferOwnership(ctx, keeper, msg)
+		case MsgSetDisplayNames:
+			return handleMsgSetDisplayNames(ctx, keeper, msg)
+		default:
+			errMsg := fmt.Sprintf("Unrecognized token Msg type: %v", reflect.TypeOf(msg).Name())
+			return sdk.ErrUnknownRequest(errMsg).Result()
+		}
+	}
+}
+
+// Handle MsgIssueToken
+func handleMsgIssueToken(ctx sdk.Context, keeper Keeper, msg MsgIssueToken) sdk.Result {
+	err := keeper.IssueToken(ctx, msg.Token(), msg.QuoteDenoms)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{
+		Tags: sdk.NewTags("token", []byte(msg.Symbol)),
+	}
+}
+
+// Handle MsgMint
+func handleMsgMint(ctx sdk.Context, keeper Keeper, msg MsgMint) sdk.Result {
+	err := keeper.Mint(ctx, msg.Owner, msg.Coins)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{
+		Tags: sdk.NewTags("token", []byte(msg.Coins.Denom)),
+	}
+}
+
+// Handle MsgBurn
+func handleMsgBurn(ctx sdk.Context, keeper Keeper, msg MsgBurn) sdk.Result {
+	err := keeper.Burn(ctx, msg.Holder, msg.Coins)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{
+		Tags: sdk.NewTags("token", []byte(msg.Coins.Denom)),
+	}
+}
+
+// Handle MsgTransferOwnership
+func handleMsgTransferOwnership(ctx sdk.Context, keeper Keeper, msg MsgTransferOwnership) sdk.Result {
+	err := keeper.TransferOwnership(ctx, msg.Owner, msg.Symbol, msg.NewOwner)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{
+		Tags: sdk.NewTags("token", []byte(msg.Symbol)),
+	}
+}
+
+// Handle MsgSetDisplayNames
+func handleMsgSetDisplayNames(ctx sdk.Context, keeper Keeper, msg MsgSetDisplayNames) sdk.Result {
+	err := keeper.SetDisplayNames(ctx, msg.Owner, msg.Symbol, msg.DisplayName, msg.Aliases)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{
+		Tags: sdk.NewTags("token", []byte(msg.Symbol)),
+	}
+}
@@ -0,0 +1,168 @@
+package token
+
+import (
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+)
+
+var tokensPrefix = []byte("tokens")
+
+// OrderbookKeeper is the part of the orderbook keeper issued tokens are listed for trading with
+type OrderbookKeeper interface {
+	GetMarket(ctx sdk.Context, pair orderbook.DenomPair) (market orderbook.Market, found bool)
+	ListMarket(ctx sdk.Context, market orderbook.Market)
+}
+
+// Keeper maintains the link to data storage and exposes getter/setter methods for the various parts of the state machine
+type Keeper struct {
+	coinKeeper      bank.Keeper
+	orderbookKeeper OrderbookKeeper
+
+	storeKey sdk.StoreKey // Unexposed key to access store from sdk.Context
+
+	cdc *codec.Codec // The wire codec for binary encoding/decoding.
+
+	codespace sdk.CodespaceType
+}
+
+func NewKeeper(coinKeeper bank.Keeper, orderbookKeeper OrderbookKeeper, storeKey sdk.StoreKey, cdc *codec.Codec, codespace sdk.CodespaceType) Keeper {
+	return Keeper{
+		coinKeeper:      coinKeeper,
+		orderbookKeeper: orderbookKeeper,
+		storeKey:        storeKey,
+		cdc:             cdc,
+		codespace:       codespace,
+	}
+}
+
+// get key in store to get the Token of a symbol
+func TokenKey(symbol string) []byte {
+	return []byte(fmt.Sprintf("%s/%s", tokensPrefix, symbol))
+}
+
+// Gets the Token of a symbol
+func (k Keeper) GetToken(ctx sdk.Context, symbol string) (token Token, found bool) {
+	store := ctx.KVStore(k.storeKey)
+	bz := store.Get(TokenKey(symbol))
+	if bz == nil {
+		return token, false
+	}
+	k.cdc.MustUnmarshalBinaryBare(bz, &token)
+	return token, true
+}
+
+// Sets a Token in the Store
+func (k Keeper) SetToken(ctx sdk.Context, token Token) {
+	store := ctx.KVStore(k.storeKey)
+	store.Set(TokenKey(token.Symbol), k.cdc.MustMarshalBinaryBare(token))
+}
+
+// Gets all Tokens, ordered by symbol
+func (k Keeper) GetTokens(ctx sdk.Context) (tokens []Token) {
+	store := ctx.KVStore(k.storeKey)
+	prefix := TokenKey("")
+	iterator := store.Iterator(prefix, sdk.PrefixEndBytes(prefix))
+	defer iterator.Close()
+
+	for ; iterator.Valid(); iterator.Next() {
+		var token Token
+		k.cdc.MustUnmarshalBinaryBare(iterator.Value(), &token)
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// Creates a new token, crediting its whole supply to its owner.
+// The token is listed for trading against every quote denom, which must be existing tokens
+func (k Keeper) IssueToken(ctx sdk.Context, token Token, quoteDenoms []string) sdk.Error {
+	if err := token.Validate(); err != nil {
+		return ErrInvalidToken(k.codespace, err.Error())
+	}
+	if _, found := k.GetDenomMetadata(ctx, token.Symbol); found {
+		return ErrTokenExists(k.codespace, token.Symbol)
+	}
+	if err := k.validateDisplayNames(ctx, token); err != nil {
+		return err
+	}
+
+	var markets []orderbook.Market
+	for _, quoteDenom := range quoteDenoms {
+		if _, found := k.GetToken(ctx, quoteDenom); !found || quoteDenom == token.Symbol {
+			return ErrTokenNotFound(k.codespace, quoteDenom)
+		}
+		markets = append(markets, orderbook.NewMarket(token.Symbol, quoteDenom))
+	}
+
+	k.setTokenWithDisplayNames(ctx, token)
+	if token.TotalSupply.IsPositive() {
+		_, _, err := k.coinKeeper.AddCoins(ctx, token.Owner, sdk.Coins{sdk.NewCoin(token.Symbol, token.TotalSupply)})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, market := range markets {
+		if _, found := k.orderbookKeeper.GetMarket(ctx, market.Pair()); !found {
+			k.orderbookKeeper.ListMarket(ctx, market)
+		}
+	}
+	return nil
+}
+
+// Creates new coins of a mintable token for its owner
+func (k Keeper) Mint(ctx sdk.Context, owner sdk.AccAddress, coins sdk.Coin) sdk.Error {
+	token, found := k.GetToken(ctx, coins.Denom)
+	if !found {
+		return ErrTokenNotFound(k.codespace, coins.Denom)
+	}
+	if !token.Owner.Equals(owner) {
+		return ErrNotOwner(k.codespace, token.Symbol)
+	}
+	if !token.Mintable {
+		return ErrNotMintable(k.codespace, token.Symbol)
+	}
+
+	_, _, err := k.coinKeeper.AddCoins(ctx, owner, sdk.Coins{coins})
+	if err != nil {
+		return err
+	}
+	token.TotalSupply = token.TotalSupply.Add(coins.Amount)
+	k.SetToken(ctx, token)
+	return nil
+}
+
+// Destroys coins of a token held by an account, reducing the token's supply
+func (k Keeper) Burn(ctx sdk.Context, holder sdk.AccAddress, coins sdk.Coin) sdk.Error {
+	token, found := k.GetToken(ctx, coins.Denom)
+	if !found {
+		return ErrTokenNotFound(k.codespace, coins.Denom)
+	}
+
+	_, _, err := k.coinKeeper.SubtractCoins(ctx, holder, sdk.Coins{coins})
+	if err != nil {
+		return err
+	}
+	token.TotalSupply = token.TotalSupply.Sub(coins.Amount)
+	k.SetToken(ctx, token)
+	return nil
+}
+
+// Makes another account the owner of a token
+func (k Keeper) TransferOwnership(ctx sdk.Context, owner sdk.AccAddress, symbol string, newOwner sdk.AccAddress) sdk.Error {
+	token, found := k.GetToken(ctx, symbol)
+	if !found {
+		return ErrTokenNotFound(k.codespace, symbol)
+	}
+	if !token.Owner.Equals(owner) {
+		return ErrNotOwner(k.codespace, token.Symbol)
+	}
+
+	token.Owner = newOwner
+	k.SetToken(ctx, token)
+	return nil
+}
@@ -0,0 +1,124 @@
+package token
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	dbm "github.com/tendermint/tendermint/libs/db"
+	"github.com/tendermint/tendermint/libs/log"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	"github.com/cosmos/cosmos-sdk/store"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/bank"
+
+	"github.com/sunnya97/sdk-dex-mvp/x/orderbook"
+)
+
+var (
+	alice = sdk.AccAddress([]byte("alice_______________"))
+	bob   = sdk.AccAddress([]byte("bob_________________"))
+)
+
+// mockOrderbookKeeper keeps the listed markets in memory
+type mockOrderbookKeeper map[string]orderbook.Market
+
+func (ok mockOrderbookKeeper) GetMarket(ctx sdk.Context, pair orderbook.DenomPair) (market orderbook.Market, found bool) {
+	market, found = ok[string(orderbook.MarketKey(pair))]
+	return market, found
+}
+
+func (ok mockOrderbookKeeper) ListMarket(ctx sdk.Context, market orderbook.Market) {
+	ok[string(orderbook.MarketKey(market.Pair()))] = market
+}
+
+// creates a context and a token Keeper backed by in-memory stores, with alice holding BTC from genesis
+func createTestInput(t *testing.T) (sdk.Context, Keeper) {
+	keyAcc := sdk.NewKVStoreKey("acc")
+	keyToken := sdk.NewKVStoreKey("token")
+
+	db := dbm.NewMemDB()
+	ms := store.NewCommitMultiStore(db)
+	ms.MountStoreWithDB(keyAcc, sdk.StoreTypeIAVL, db)
+	ms.MountStoreWithDB(keyToken, sdk.StoreTypeIAVL, db)
+	require.NoError(t, ms.LoadLatestVersion())
+
+	cdc := codec.New()
+	RegisterCodec(cdc)
+	auth.RegisterBaseAccount(cdc)
+	codec.RegisterCrypto(cdc)
+
+	ctx := sdk.NewContext(ms, abci.Header{ChainID: "test-chain"}, false, log.NewNopLogger())
+
+	accountKeeper := auth.NewAccountKeeper(cdc, keyAcc, auth.ProtoBaseAccount)
+	bankKeeper := bank.NewBaseKeeper(accountKeeper)
+	keeper := NewKeeper(bankKeeper, mockOrderbookKeeper{}, keyToken, cdc, DefaultCodespace)
+
+	genesisCoins := sdk.Coins{sdk.NewInt64Coin("BTC", 1000)}
+	_, _, err := bankKeeper.AddCoins(ctx, alice, genesisCoins)
+	require.Nil(t, err)
+	InitGenesis(ctx, keeper, DefaultGenesisState(), genesisCoins)
+
+	return ctx, keeper
+}
+
+func TestIssueToken(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	handler := NewHandler(keeper)
+
+	// genesis denoms can't be issued again, and markets can only be listed against existing tokens
+	res := handler(ctx, NewMsgIssueToken(bob, "BTC", sdk.NewInt(100), 8, true, nil))
+	require.Equal(t, CodeTokenExists, res.Code)
+	res = handler(ctx, NewMsgIssueToken(bob, "DEX", sdk.NewInt(100), 6, true, []string{"ETH"}))
+	require.Equal(t, CodeTokenNotFound, res.Code)
+
+	res = handler(ctx, NewMsgIssueToken(bob, "DEX", sdk.NewInt(100), 6, true, []string{"BTC"}))
+	require.True(t, res.IsOK(), res.Log)
+	require.Equal(t, int64(100), keeper.coinKeeper.GetCoins(ctx, bob).AmountOf("DEX").Int64())
+	_, found := keeper.orderbookKeeper.GetMarket(ctx, orderbook.NewDenomPair("BTC", "DEX"))
+	require.True(t, found)
+
+	token, found := keeper.GetToken(ctx, "DEX")
+	require.True(t, found)
+	require.Equal(t, NewToken("DEX", bob, sdk.NewInt(100), 6, true), token)
+	require.Len(t, keeper.GetTokens(ctx), 2)
+}
+
+func TestMintBurnAndTransferOwnership(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	handler := NewHandler(keeper)
+
+	res := handler(ctx, NewMsgIssueToken(bob, "DEX", sdk.NewInt(100), 6, true, nil))
+	require.True(t, res.IsOK(), res.Log)
+
+	// only the owner mints, and tokens without an owner have a fixed supply
+	res = handler(ctx, NewMsgMint(alice, sdk.NewInt64Coin("DEX", 50)))
+	require.Equal(t, CodeNotOwner, res.Code)
+	res = handler(ctx, NewMsgMint(alice, sdk.NewInt64Coin("BTC", 50)))
+	require.Equal(t, CodeNotOwner, res.Code)
+
+	res = handler(ctx, NewMsgMint(bob, sdk.NewInt64Coin("DEX", 50)))
+	require.True(t, res.IsOK(), res.Log)
+
+	// any holder can burn what they hold
+	res = handler(ctx, NewMsgBurn(alice, sdk.NewInt64Coin("BTC", 1001)))
+	require.Equal(t, sdk.CodeInsufficientCoins, res.Code)
+	res = handler(ctx, NewMsgBurn(alice, sdk.NewInt64Coin("BTC", 400)))
+	require.True(t, res.IsOK(), res.Log)
+	token, _ := keeper.GetToken(ctx, "BTC")
+	require.Equal(t, int64(600), token.TotalSupply.Int64())
+
+	res = handler(ctx, NewMsgTransferOwnership(bob, "DEX", alice))
+	require.True(t, res.IsOK(), res.Log)
+	res = handler(ctx, NewMsgMint(bob, sdk.NewInt64Coin("DEX", 50)))
+	require.Equal(t, CodeNotOwner, res.Code)
+	res = handler(ctx, NewMsgMint(alice, sdk.NewInt64Coin("DEX", 50)))
+	require.True(t, res.IsOK(), res.Log)
+
+	token, _ = keeper.GetToken(ctx, "DEX")
+	require.Equal(t, int64(200), token.TotalSupply.Int64())
+	require.Equal(t, alice, token.Owner)
+}
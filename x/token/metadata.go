@@ -0,0 +1,208 @@
+package token
+
+import (
+	"fmt"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+var denomNamesPrefix = []byte("denomNames")
+
+// DenomMetadata is what a denom's amounts are read and written with.
+// An amount written with DisplayName or one of the Aliases is in display units, each 10^Decimals base units,
+// while an amount written with Denom is in base units
+type DenomMetadata struct {
+	Denom       string   `json:"denom"`
+	DisplayName string   `json:"display_name"`
+	Decimals    int64    `json:"decimals"`
+	Aliases     []string `json:"aliases"`
+}
+
+// Returns the metadata of a denom that has none stored, whose amounts are only written in base units
+func BaseDenomMetadata(denom string) DenomMetadata {
+	return DenomMetadata{Denom: denom}
+}
+
+// Returns the name amounts are rendered with, the display name if there is one
+func (m DenomMetadata) Unit() string {
+	if m.DisplayName == "" {
+		return m.Denom
+	}
+	return m.DisplayName
+}
+
+// Returns whether a name is one of the names of the display unit
+func (m DenomMetadata) IsDisplayName(name string) bool {
+	if name == m.DisplayName && name != "" {
+		return true
+	}
+	for _, alias := range m.Aliases {
+		if name == alias {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the power of ten an amount is multiplied by to convert it from the unit it's named with to base units
+func (m DenomMetadata) DecimalsOf(name string) int64 {
+	if !m.IsDisplayName(name) {
+		return 0
+	}
+	return m.Decimals
+}
+
+// Returns the decimals of the unit amounts are rendered in
+func (m DenomMetadata) DisplayDecimals() int64 {
+	return m.DecimalsOf(m.Unit())
+}
+
+// Renders an amount of base units in the display unit, without losing precision
+func (m DenomMetadata) FormatAmount(amount sdk.Int) string {
+	decimals := m.DisplayDecimals()
+	str := amount.String()
+	if decimals == 0 {
+		return str + m.Unit()
+	}
+
+	sign := ""
+	if strings.HasPrefix(str, "-") {
+		sign, str = "-", str[1:]
+	}
+	if int64(len(str)) <= decimals {
+		str = strings.Repeat("0", int(decimals)-len(str)+1) + str
+	}
+	whole, frac := str[:int64(len(str))-decimals], strings.TrimRight(str[int64(len(str))-decimals:], "0")
+	if frac == "" {
+		return sign + whole + m.Unit()
+	}
+	return sign + whole + "." + frac + m.Unit()
+}
+
+// Parses an amount written in the unit name is one of the names of, and returns it in base units.
+// Amounts that are finer than a base unit are rejected
+func (m DenomMetadata) ParseAmount(amountStr string, name string) (sdk.Int, error) {
+	decimals := m.DecimalsOf(name)
+	whole, frac := amountStr, ""
+	if i := strings.Index(amountStr, "."); i >= 0 {
+		whole, frac = amountStr[:i], amountStr[i+1:]
+	}
+	if int64(len(frac)) > decimals {
+		return sdk.Int{}, fmt.Errorf("%s%s is finer than the %d decimals of %s", amountStr, name, decimals, m.Denom)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+
+	amount, ok := sdk.NewIntFromString(whole + frac + strings.Repeat("0", int(decimals)-len(frac)))
+	if !ok || strings.ContainsAny(whole+frac, "+-") {
+		return sdk.Int{}, fmt.Errorf("invalid amount %s%s", amountStr, name)
+	}
+	return amount, nil
+}
+
+// Splits an amount written with a denom or display name, like 1.5BTC, into the amount and the name
+func SplitAmount(coinStr string) (amountStr string, name string, err error) {
+	coinStr = strings.TrimSpace(coinStr)
+	i := strings.IndexFunc(coinStr, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i <= 0 {
+		return "", "", fmt.Errorf("invalid amount %s, must be a number followed by a denom", coinStr)
+	}
+	return coinStr[:i], strings.TrimSpace(coinStr[i:]), nil
+}
+
+// Converts a price from units of numer/denom, whose amounts are 10^numerDecimals and 10^denomDecimals
+// base units, to base units
+func PriceToBase(ratio sdk.Dec, numerDecimals int64, denomDecimals int64) sdk.Dec {
+	return shiftDecimals(ratio, numerDecimals-denomDecimals)
+}
+
+// Converts a price in base units to units of numer/denom, whose amounts are 10^numerDecimals and
+// 10^denomDecimals base units
+func PriceFromBase(ratio sdk.Dec, numerDecimals int64, denomDecimals int64) sdk.Dec {
+	return shiftDecimals(ratio, denomDecimals-numerDecimals)
+}
+
+// multiplies a Dec by 10^exp
+func shiftDecimals(d sdk.Dec, exp int64) sdk.Dec {
+	for ; exp > 0; exp-- {
+		d = d.MulInt(sdk.NewInt(10))
+	}
+	for ; exp < 0; exp++ {
+		d = d.QuoInt(sdk.NewInt(10))
+	}
+	return d
+}
+
+// get key in store to get the symbol of the token a display name or alias names
+func DenomNameKey(name string) []byte {
+	return []byte(fmt.Sprintf("%s/%s", denomNamesPrefix, name))
+}
+
+// Gets the metadata of a denom, by its symbol or one of its display names.
+// Denoms without a token only have base units
+func (k Keeper) GetDenomMetadata(ctx sdk.Context, name string) (metadata DenomMetadata, found bool) {
+	symbol := name
+	if bz := ctx.KVStore(k.storeKey).Get(DenomNameKey(name)); bz != nil {
+		symbol = string(bz)
+	}
+	token, found := k.GetToken(ctx, symbol)
+	if !found {
+		return BaseDenomMetadata(name), false
+	}
+	return token.Metadata(), true
+}
+
+// Checks that a token's display names aren't the symbol or a display name of another token
+func (k Keeper) validateDisplayNames(ctx sdk.Context, token Token) sdk.Error {
+	store := ctx.KVStore(k.storeKey)
+	for _, name := range token.DisplayNames() {
+		if symbol := store.Get(DenomNameKey(name)); symbol != nil && string(symbol) != token.Symbol {
+			return ErrInvalidToken(k.codespace, fmt.Sprintf("%s is a display name of %s", name, symbol))
+		}
+		if _, found := k.GetToken(ctx, name); found && name != token.Symbol {
+			return ErrInvalidToken(k.codespace, fmt.Sprintf("%s is the symbol of another token", name))
+		}
+	}
+	return nil
+}
+
+// Sets a token along with the index of its display names, replacing the names it had before
+func (k Keeper) setTokenWithDisplayNames(ctx sdk.Context, token Token) {
+	store := ctx.KVStore(k.storeKey)
+	if old, found := k.GetToken(ctx, token.Symbol); found {
+		for _, name := range old.DisplayNames() {
+			store.Delete(DenomNameKey(name))
+		}
+	}
+	for _, name := range token.DisplayNames() {
+		store.Set(DenomNameKey(name), []byte(token.Symbol))
+	}
+	k.SetToken(ctx, token)
+}
+
+// Changes the display name and aliases of a token, as its owner
+func (k Keeper) SetDisplayNames(ctx sdk.Context, owner sdk.AccAddress, symbol string, displayName string, aliases []string) sdk.Error {
+	token, found := k.GetToken(ctx, symbol)
+	if !found {
+		return ErrTokenNotFound(k.codespace, symbol)
+	}
+	if !token.Owner.Equals(owner) {
+		return ErrNotOwner(k.codespace, token.Symbol)
+	}
+
+	token.DisplayName = displayName
+	token.Aliases = aliases
+	if err := token.Validate(); err != nil {
+		return ErrInvalidToken(k.codespace, err.Error())
+	}
+	if err := k.validateDisplayNames(ctx, token); err != nil {
+		return err
+	}
+
+	k.setTokenWithDisplayNames(ctx, token)
+	return nil
+}
@@ -0,0 +1,91 @@
+package token
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestFormatAndParseAmount(t *testing.T) {
+	btc := DenomMetadata{Denom: "sat", DisplayName: "BTC", Decimals: 8, Aliases: []string{"bitcoin"}}
+
+	tests := []struct {
+		metadata DenomMetadata
+		amount   int64
+		display  string
+	}{
+		{btc, 150000000, "1.5BTC"},
+		{btc, 1, "0.00000001BTC"},
+		{btc, 200000000, "2BTC"},
+		{btc, 0, "0BTC"},
+		{BaseDenomMetadata("ETH"), 42, "42ETH"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.display, tt.metadata.FormatAmount(sdk.NewInt(tt.amount)))
+
+		amountStr, name, err := SplitAmount(tt.display)
+		require.NoError(t, err)
+		amount, err := tt.metadata.ParseAmount(amountStr, name)
+		require.NoError(t, err)
+		require.Equal(t, tt.amount, amount.Int64())
+	}
+
+	// aliases are display units, and the base denom is in base units
+	amount, err := btc.ParseAmount("0.25", "bitcoin")
+	require.NoError(t, err)
+	require.Equal(t, int64(25000000), amount.Int64())
+	amount, err = btc.ParseAmount("25", "sat")
+	require.NoError(t, err)
+	require.Equal(t, int64(25), amount.Int64())
+
+	// amounts finer than a base unit are rejected
+	_, err = btc.ParseAmount("0.000000001", "BTC")
+	require.Error(t, err)
+	_, err = btc.ParseAmount("1.5", "sat")
+	require.Error(t, err)
+	_, err = btc.ParseAmount("-1", "BTC")
+	require.Error(t, err)
+}
+
+func TestPriceConversion(t *testing.T) {
+	// 30 ETH (18 decimals) per BTC (8 decimals) is 3 * 10^11 wei per sat
+	display := sdk.NewDec(30)
+	base := PriceToBase(display, 18, 8)
+	require.True(t, sdk.NewDec(300000000000).Equal(base), base.String())
+	require.True(t, display.Equal(PriceFromBase(base, 18, 8)))
+}
+
+func TestSetDisplayNames(t *testing.T) {
+	ctx, keeper := createTestInput(t)
+	handler := NewHandler(keeper)
+
+	msg := NewMsgIssueToken(bob, "usat", sdk.NewInt(100000000), 8, false, nil)
+	msg.DisplayName = "SAT"
+	msg.Aliases = []string{"satoshi"}
+	res := handler(ctx, msg)
+	require.True(t, res.IsOK(), res.Log)
+
+	metadata, found := keeper.GetDenomMetadata(ctx, "satoshi")
+	require.True(t, found)
+	require.Equal(t, "usat", metadata.Denom)
+	require.Equal(t, "1SAT", metadata.FormatAmount(sdk.NewInt(100000000)))
+
+	// display names can't name another denom, and a token's symbol can't be taken by a display name
+	res = handler(ctx, NewMsgSetDisplayNames(bob, "usat", "BTC", nil))
+	require.Equal(t, CodeInvalidToken, res.Code)
+	res = handler(ctx, NewMsgIssueToken(alice, "satoshi", sdk.NewInt(1), 0, false, nil))
+	require.Equal(t, CodeTokenExists, res.Code)
+	res = handler(ctx, NewMsgSetDisplayNames(alice, "usat", "XSAT", nil))
+	require.Equal(t, CodeNotOwner, res.Code)
+
+	// renaming frees the old names
+	res = handler(ctx, NewMsgSetDisplayNames(bob, "usat", "XSAT", nil))
+	require.True(t, res.IsOK(), res.Log)
+	_, found = keeper.GetDenomMetadata(ctx, "satoshi")
+	require.False(t, found)
+	metadata, found = keeper.GetDenomMetadata(ctx, "XSAT")
+	require.True(t, found)
+	require.Equal(t, "usat", metadata.Denom)
+}
@@ -0,0 +1,246 @@
+package token
+
+import (
+	"encoding/json"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Msg for issuing a new token, whose whole supply goes to its owner.
+// The token is listed on the orderbook against each of QuoteDenoms, so it can be traded right away.
+// DisplayName and Aliases are optional names of the display unit, 10^Decimals base units
+type MsgIssueToken struct {
+	Owner       sdk.AccAddress
+	Symbol      string
+	TotalSupply sdk.Int
+	Decimals    int64
+	Mintable    bool
+	QuoteDenoms []string
+	DisplayName string
+	Aliases     []string
+}
+
+func NewMsgIssueToken(owner sdk.AccAddress, symbol string, totalSupply sdk.Int, decimals int64, mintable bool, quoteDenoms []string) MsgIssueToken {
+	return MsgIssueToken{
+		Owner:       owner,
+		Symbol:      symbol,
+		TotalSupply: totalSupply,
+		Decimals:    decimals,
+		Mintable:    mintable,
+		QuoteDenoms: quoteDenoms,
+	}
+}
+
+// Returns the token the msg issues
+func (msg MsgIssueToken) Token() Token {
+	token := NewToken(msg.Symbol, msg.Owner, msg.TotalSupply, msg.Decimals, msg.Mintable)
+	token.DisplayName = msg.DisplayName
+	token.Aliases = msg.Aliases
+	return token
+}
+
+// Implements Msg.
+func (msg MsgIssueToken) Route() string { return "token" }
+func (msg MsgIssueToken) Type() string  { return "issue_token" }
+
+// Implements Msg.
+func (msg MsgIssueToken) ValidateBasic() sdk.Error {
+	if msg.Owner.Empty() {
+		return sdk.ErrInvalidAddress(msg.Owner.String())
+	}
+	if err := msg.Token().Validate(); err != nil {
+		return ErrInvalidToken(DefaultCodespace, err.Error())
+	}
+	return nil
+}
+
+// Implements Msg.
+func (msg MsgIssueToken) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return sdk.MustSortJSON(b)
+}
+
+// Implements Msg.
+func (msg MsgIssueToken) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Owner}
+}
+
+// Msg for a token's owner to create new coins of it
+type MsgMint struct {
+	Owner sdk.AccAddress
+	Coins sdk.Coin
+}
+
+func NewMsgMint(owner sdk.AccAddress, coins sdk.Coin) MsgMint {
+	return MsgMint{
+		Owner: owner,
+		Coins: coins,
+	}
+}
+
+// Implements Msg.
+func (msg MsgMint) Route() string { return "token" }
+func (msg MsgMint) Type() string  { return "mint" }
+
+// Implements Msg.
+func (msg MsgMint) ValidateBasic() sdk.Error {
+	if msg.Owner.Empty() {
+		return sdk.ErrInvalidAddress(msg.Owner.String())
+	}
+	if !msg.Coins.IsPositive() {
+		return sdk.ErrInvalidCoins(msg.Coins.String())
+	}
+	return nil
+}
+
+// Implements Msg.
+func (msg MsgMint) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return sdk.MustSortJSON(b)
+}
+
+// Implements Msg.
+func (msg MsgMint) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Owner}
+}
+
+// Msg for destroying coins of a token held by the sender
+type MsgBurn struct {
+	Holder sdk.AccAddress
+	Coins  sdk.Coin
+}
+
+func NewMsgBurn(holder sdk.AccAddress, coins sdk.Coin) MsgBurn {
+	return MsgBurn{
+		Holder: holder,
+		Coins:  coins,
+	}
+}
+
+// Implements Msg.
+func (msg MsgBurn) Route() string { return "token" }
+func (msg MsgBurn) Type() string  { return "burn" }
+
+// Implements Msg.
+func (msg MsgBurn) ValidateBasic() sdk.Error {
+	if msg.Holder.Empty() {
+		return sdk.ErrInvalidAddress(msg.Holder.String())
+	}
+	if !msg.Coins.IsPositive() {
+		return sdk.ErrInvalidCoins(msg.Coins.String())
+	}
+	return nil
+}
+
+// Implements Msg.
+func (msg MsgBurn) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return sdk.MustSortJSON(b)
+}
+
+// Implements Msg.
+func (msg MsgBurn) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Holder}
+}
+
+// Msg for a token's owner to hand the token over to a new owner
+type MsgTransferOwnership struct {
+	Owner    sdk.AccAddress
+	Symbol   string
+	NewOwner sdk.AccAddress
+}
+
+func NewMsgTransferOwnership(owner sdk.AccAddress, symbol string, newOwner sdk.AccAddress) MsgTransferOwnership {
+	return MsgTransferOwnership{
+		Owner:    owner,
+		Symbol:   symbol,
+		NewOwner: newOwner,
+	}
+}
+
+// Implements Msg.
+func (msg MsgTransferOwnership) Route() string { return "token" }
+func (msg MsgTransferOwnership) Type() string  { return "transfer_ownership" }
+
+// Implements Msg.
+func (msg MsgTransferOwnership) ValidateBasic() sdk.Error {
+	if msg.Owner.Empty() {
+		return sdk.ErrInvalidAddress(msg.Owner.String())
+	}
+	if msg.NewOwner.Empty() {
+		return sdk.ErrInvalidAddress(msg.NewOwner.String())
+	}
+	if !ValidSymbol(msg.Symbol) {
+		return ErrTokenNotFound(DefaultCodespace, msg.Symbol)
+	}
+	return nil
+}
+
+// Implements Msg.
+func (msg MsgTransferOwnership) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return sdk.MustSortJSON(b)
+}
+
+// Implements Msg.
+func (msg MsgTransferOwnership) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Owner}
+}
+
+// Msg for a token's owner to change the names of its display unit
+type MsgSetDisplayNames struct {
+	Owner       sdk.AccAddress
+	Symbol      string
+	DisplayName string
+	Aliases     []string
+}
+
+func NewMsgSetDisplayNames(owner sdk.AccAddress, symbol string, displayName string, aliases []string) MsgSetDisplayNames {
+	return MsgSetDisplayNames{
+		Owner:       owner,
+		Symbol:      symbol,
+		DisplayName: displayName,
+		Aliases:     aliases,
+	}
+}
+
+// Implements Msg.
+func (msg MsgSetDisplayNames) Route() string { return "token" }
+func (msg MsgSetDisplayNames) Type() string  { return "set_display_names" }
+
+// Implements Msg.
+func (msg MsgSetDisplayNames) ValidateBasic() sdk.Error {
+	if msg.Owner.Empty() {
+		return sdk.ErrInvalidAddress(msg.Owner.String())
+	}
+	if !ValidSymbol(msg.Symbol) {
+		return ErrTokenNotFound(DefaultCodespace, msg.Symbol)
+	}
+	return nil
+}
+
+// Implements Msg.
+func (msg MsgSetDisplayNames) GetSignBytes() []byte {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	return sdk.MustSortJSON(b)
+}
+
+// Implements Msg.
+func (msg MsgSetDisplayNames) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Owner}
+}
@@ -0,0 +1,71 @@
+package token
+
+import (
+	"github.com/cosmos/cosmos-sdk/codec"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+// query endpoints supported by the token Querier
+const (
+	QueryToken    = "token"
+	QueryTokens   = "tokens"
+	QueryMetadata = "metadata"
+)
+
+// NewQuerier is the module level router for state queries
+func NewQuerier(keeper Keeper) sdk.Querier {
+	return func(ctx sdk.Context, path []string, req abci.RequestQuery) (res []byte, err sdk.Error) {
+		switch path[0] {
+		case QueryToken:
+			return queryToken(ctx, path[1:], req, keeper)
+		case QueryTokens:
+			return queryTokens(ctx, path[1:], req, keeper)
+		case QueryMetadata:
+			return queryMetadata(ctx, path[1:], req, keeper)
+		default:
+			return nil, sdk.ErrUnknownRequest("unknown token query endpoint")
+		}
+	}
+}
+
+// nolint: unparam
+func queryToken(ctx sdk.Context, path []string, req abci.RequestQuery, keeper Keeper) (res []byte, err sdk.Error) {
+	if len(path) == 0 {
+		return nil, sdk.ErrUnknownRequest("missing token symbol")
+	}
+	token, found := keeper.GetToken(ctx, path[0])
+	if !found {
+		return nil, ErrTokenNotFound(keeper.codespace, path[0])
+	}
+
+	res, err2 := codec.MarshalJSONIndent(keeper.cdc, token)
+	if err2 != nil {
+		panic("could not marshal result to JSON")
+	}
+	return res, nil
+}
+
+// nolint: unparam
+func queryTokens(ctx sdk.Context, path []string, req abci.RequestQuery, keeper Keeper) (res []byte, err sdk.Error) {
+	res, err2 := codec.MarshalJSONIndent(keeper.cdc, keeper.GetTokens(ctx))
+	if err2 != nil {
+		panic("could not marshal result to JSON")
+	}
+	return res, nil
+}
+
+// nolint: unparam
+func queryMetadata(ctx sdk.Context, path []string, req abci.RequestQuery, keeper Keeper) (res []byte, err sdk.Error) {
+	if len(path) == 0 {
+		return nil, sdk.ErrUnknownRequest("missing denom")
+	}
+	metadata, _ := keeper.GetDenomMetadata(ctx, path[0])
+
+	res, err2 := codec.MarshalJSONIndent(keeper.cdc, metadata)
+	if err2 != nil {
+		panic("could not marshal result to JSON")
+	}
+	return res, nil
+}
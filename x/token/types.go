@@ -0,0 +1,107 @@
+package token
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// MaxDecimals is the largest number of decimals a token's base unit can be divided into
+const MaxDecimals = 18
+
+// symbols are short alphanumeric denoms, starting with a letter
+var symbolRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9]{2,15}$`)
+
+// Token is a denom the token module keeps the supply of.
+// Tokens issued with MsgIssueToken have an Owner, who can mint them if they're Mintable.
+// Denoms that exist in genesis are tokens without an owner, whose supply is fixed.
+// DisplayName and Aliases name the display unit, which is 10^Decimals of the base unit Symbol names
+type Token struct {
+	Symbol      string         `json:"symbol"`
+	Owner       sdk.AccAddress `json:"owner"`
+	TotalSupply sdk.Int        `json:"total_supply"`
+	Decimals    int64          `json:"decimals"`
+	Mintable    bool           `json:"mintable"`
+	DisplayName string         `json:"display_name"`
+	Aliases     []string       `json:"aliases"`
+}
+
+func NewToken(symbol string, owner sdk.AccAddress, totalSupply sdk.Int, decimals int64, mintable bool) Token {
+	return Token{
+		Symbol:      symbol,
+		Owner:       owner,
+		TotalSupply: totalSupply,
+		Decimals:    decimals,
+		Mintable:    mintable,
+	}
+}
+
+func (t Token) String() string {
+	return fmt.Sprintf(`Token %s:
+  Owner:        %s
+  Total Supply: %s
+  Decimals:     %d
+  Mintable:     %t
+  Display Name: %s
+  Aliases:      %s`, t.Symbol, t.Owner, t.TotalSupply, t.Decimals, t.Mintable, t.DisplayName, strings.Join(t.Aliases, ", "))
+}
+
+// Returns the names of the token's display unit
+func (t Token) DisplayNames() []string {
+	if t.DisplayName == "" {
+		return t.Aliases
+	}
+	return append([]string{t.DisplayName}, t.Aliases...)
+}
+
+// Returns the metadata amounts of the token are read and written with
+func (t Token) Metadata() DenomMetadata {
+	return DenomMetadata{
+		Denom:       t.Symbol,
+		DisplayName: t.DisplayName,
+		Decimals:    t.Decimals,
+		Aliases:     t.Aliases,
+	}
+}
+
+// Returns whether a symbol can be used as the denom of a token
+func ValidSymbol(symbol string) bool {
+	return symbolRegex.MatchString(symbol)
+}
+
+// Checks that a token's symbol, supply and decimals are well formed
+func (t Token) Validate() error {
+	if !ValidSymbol(t.Symbol) {
+		return fmt.Errorf("symbol %s must be 3 to 16 letters and digits, starting with a letter", t.Symbol)
+	}
+	if t.TotalSupply == (sdk.Int{}) || t.TotalSupply.Sign() == -1 {
+		return fmt.Errorf("total supply of %s can't be negative", t.Symbol)
+	}
+	if t.Decimals < 0 || t.Decimals > MaxDecimals {
+		return fmt.Errorf("decimals of %s must be between 0 and %d", t.Symbol, MaxDecimals)
+	}
+	if t.Mintable && t.Owner.Empty() {
+		return fmt.Errorf("token %s without an owner can't be mintable", t.Symbol)
+	}
+
+	if t.DisplayName == "" && len(t.Aliases) > 0 {
+		return fmt.Errorf("aliases of %s need a display name", t.Symbol)
+	}
+	seen := make(map[string]bool)
+	for _, name := range t.DisplayNames() {
+		if !ValidSymbol(name) {
+			return fmt.Errorf("display name %s must be 3 to 16 letters and digits, starting with a letter", name)
+		}
+		// a display unit named like the base unit would make amounts ambiguous
+		if name == t.Symbol && t.Decimals > 0 {
+			return fmt.Errorf("display name %s of a token with decimals must differ from its symbol", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("display name %s is given twice", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}